@@ -0,0 +1,293 @@
+package beef
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	util "github.com/bitcoin-sv/go-sdk/util"
+)
+
+// Bytes will serialize the BEEF (in the format expected by DecodeBEEF)
+//
+// The Version selects the format (BEEF V1 if not set), the AtomicTxID adds the Atomic BEEF prefix
+func (d *DecodedBEEF) Bytes() ([]byte, error) {
+	version := d.Version
+	if version == 0 {
+		version = BEEFVersion1
+	} else if version != BEEFVersion1 && version != BEEFVersion2 {
+		return nil, fmt.Errorf("cannot encode BEEF - invalid version %d", version)
+	}
+
+	buf := make([]byte, 0, 1024)
+	if d.IsAtomic() {
+		subjectTxID, err := chainhash.NewHashFromHex(d.AtomicTxID)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode BEEF - invalid atomic subject txid: %w", err)
+		}
+		buf = binary.LittleEndian.AppendUint32(buf, AtomicBEEFPrefix)
+		buf = append(buf, subjectTxID[:]...)
+	}
+	buf = binary.LittleEndian.AppendUint32(buf, version)
+
+	buf = append(buf, sdk.VarInt(len(d.BUMPs)).Bytes()...)
+	for i, bump := range d.BUMPs {
+		var err error
+		if buf, err = bump.appendBytes(buf); err != nil {
+			return nil, fmt.Errorf("cannot encode BUMP at index %d: %w", i, err)
+		}
+	}
+
+	buf = append(buf, sdk.VarInt(len(d.Transactions)).Bytes()...)
+	for i, td := range d.Transactions {
+		var err error
+		if version == BEEFVersion2 {
+			buf, err = d.appendTransactionV2(buf, td)
+		} else {
+			buf, err = d.appendTransactionV1(buf, td)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode transaction at index %d - %w", i, err)
+		}
+	}
+
+	return buf, nil
+}
+
+// appendTransactionV1 will append the transaction followed by the HasBump flag (BRC-62)
+func (d *DecodedBEEF) appendTransactionV1(buf []byte, td *TxData) ([]byte, error) {
+	if td == nil || td.IsTxIDOnly() {
+		return nil, errors.New("transaction is missing")
+	}
+	buf = append(buf, td.Transaction.Bytes()...)
+
+	if td.Unmined() {
+		return append(buf, HasNoBump), nil
+	}
+	if err := d.validateBumpIndex(td); err != nil {
+		return nil, err
+	}
+	buf = append(buf, HasBump)
+	return append(buf, td.BumpIndex.Bytes()...), nil
+}
+
+// appendTransactionV2 will append the format flag followed by the BUMP index and transaction or the txid (BRC-96)
+func (d *DecodedBEEF) appendTransactionV2(buf []byte, td *TxData) ([]byte, error) {
+	if td == nil {
+		return nil, errors.New("transaction is missing")
+	}
+
+	if td.IsTxIDOnly() {
+		txID, err := chainhash.NewHashFromHex(td.GetTxID())
+		if err != nil {
+			return nil, fmt.Errorf("invalid txid: %w", err)
+		}
+		buf = append(buf, TxIDOnly)
+		return append(buf, txID[:]...), nil
+	}
+
+	if td.Unmined() {
+		buf = append(buf, HasNoBump)
+	} else {
+		if err := d.validateBumpIndex(td); err != nil {
+			return nil, err
+		}
+		buf = append(buf, HasBump)
+		buf = append(buf, td.BumpIndex.Bytes()...)
+	}
+	return append(buf, td.Transaction.Bytes()...), nil
+}
+
+// validateBumpIndex will check that the BUMP index of the transaction is in range
+func (d *DecodedBEEF) validateBumpIndex(td *TxData) error {
+	if uint64(*td.BumpIndex) >= uint64(len(d.BUMPs)) {
+		return fmt.Errorf("BUMP index %d out of range", uint64(*td.BumpIndex))
+	}
+	return nil
+}
+
+// Hex will serialize the BEEF as a hex string (in the format expected by DecodeBEEF)
+func (d *DecodedBEEF) Hex() (string, error) {
+	beefBytes, err := d.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(beefBytes), nil
+}
+
+// appendBytes will append the serialized BUMP (in the format read by the Decoder)
+func (b *BUMP) appendBytes(buf []byte) ([]byte, error) {
+	if b == nil {
+		return nil, errors.New("BUMP is missing")
+	}
+	if len(b.Path) > maxTreeHeight {
+		return nil, fmt.Errorf("treeHeight cannot be grater than %d", maxTreeHeight)
+	}
+
+	buf = append(buf, sdk.VarInt(b.BlockHeight).Bytes()...)
+	buf = append(buf, byte(len(b.Path)))
+
+	for _, level := range b.Path {
+		buf = append(buf, sdk.VarInt(len(level)).Bytes()...)
+		for _, leaf := range level {
+			buf = append(buf, sdk.VarInt(leaf.Offset).Bytes()...)
+
+			flag := dataFlag
+			if leaf.Duplicate {
+				buf = append(buf, duplicateFlag)
+				continue
+			} else if leaf.TxId {
+				flag = txIDFlag
+			}
+
+			hash, err := hex.DecodeString(leaf.Hash)
+			if err != nil || len(hash) != hashBytesCount {
+				return nil, fmt.Errorf("invalid hash of leaf at offset %d", leaf.Offset)
+			}
+			buf = append(buf, flag)
+			buf = append(buf, util.ReverseBytes(hash)...)
+		}
+	}
+
+	return buf, nil
+}
+
+// Builder builds a BEEF from a subject transaction, its ancestors and the BUMPs of the mined ancestors
+type Builder struct {
+	ancestors []*builderTx
+	subject   *sdk.Transaction
+}
+
+// builderTx is an ancestor added to the Builder
+type builderTx struct {
+	bump *BUMP
+	tx   *sdk.Transaction
+	txID string
+}
+
+// NewBuilder will return a Builder for the subject transaction (the last transaction of the BEEF)
+func NewBuilder(subject *sdk.Transaction) *Builder {
+	return &Builder{subject: subject}
+}
+
+// AddAncestor will add an ancestor transaction with its BUMP (nil if the ancestor is not mined)
+//
+// Ancestors sharing a block can use the same BUMP, it is serialized only once
+func (b *Builder) AddAncestor(tx *sdk.Transaction, bump *BUMP) *Builder {
+	b.ancestors = append(b.ancestors, &builderTx{bump: bump, tx: tx})
+	return b
+}
+
+// Build will order the transactions topologically (parents first, subject last) and assign the BUMP indexes
+func (b *Builder) Build() (*DecodedBEEF, error) {
+	if b.subject == nil {
+		return nil, errors.New("cannot build BEEF - subject transaction is missing")
+	}
+	subjectTxID := b.subject.TxID().String()
+
+	byTxID := make(map[string]*builderTx, len(b.ancestors))
+	for _, ancestor := range b.ancestors {
+		if ancestor.tx == nil {
+			return nil, errors.New("cannot build BEEF - ancestor transaction is missing")
+		}
+		ancestor.txID = ancestor.tx.TxID().String()
+		if _, ok := byTxID[ancestor.txID]; ok || ancestor.txID == subjectTxID {
+			return nil, fmt.Errorf("cannot build BEEF - duplicate transaction %s", ancestor.txID)
+		}
+		byTxID[ancestor.txID] = ancestor
+	}
+
+	ordered, err := b.orderAncestors(byTxID, subjectTxID)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded := &DecodedBEEF{Transactions: make([]*TxData, 0, len(ordered)+1), Version: BEEFVersion1}
+	bumpIndexes := make(map[*BUMP]int)
+	for _, ancestor := range ordered {
+		td := &TxData{Transaction: ancestor.tx, txID: ancestor.txID}
+		if ancestor.bump != nil {
+			if !ancestor.bump.containsTxID(ancestor.txID) {
+				return nil, fmt.Errorf("cannot build BEEF - BUMP does not contain transaction %s", ancestor.txID)
+			}
+			index, ok := bumpIndexes[ancestor.bump]
+			if !ok {
+				index = len(decoded.BUMPs)
+				bumpIndexes[ancestor.bump] = index
+				decoded.BUMPs = append(decoded.BUMPs, ancestor.bump)
+			}
+			bumpIndex := sdk.VarInt(index)
+			td.BumpIndex = &bumpIndex
+		}
+		decoded.Transactions = append(decoded.Transactions, td)
+	}
+	decoded.Transactions = append(decoded.Transactions, &TxData{Transaction: b.subject, txID: subjectTxID})
+
+	if len(decoded.BUMPs) == 0 {
+		return nil, errors.New("cannot build BEEF - at least one mined ancestor (BUMP) is required")
+	}
+	return decoded, nil
+}
+
+// orderAncestors will sort the ancestors topologically (Kahn's algorithm, stable for independent transactions)
+func (b *Builder) orderAncestors(byTxID map[string]*builderTx, subjectTxID string) ([]*builderTx, error) {
+	inDegree := make(map[string]int, len(b.ancestors))
+	children := make(map[string][]*builderTx, len(b.ancestors))
+	for _, ancestor := range b.ancestors {
+		for _, input := range ancestor.tx.Inputs {
+			parentTxID := sourceTxID(input)
+			if parentTxID == subjectTxID {
+				return nil, fmt.Errorf("cannot build BEEF - subject transaction is a parent of %s", ancestor.txID)
+			}
+			if _, ok := byTxID[parentTxID]; ok {
+				inDegree[ancestor.txID]++
+				children[parentTxID] = append(children[parentTxID], ancestor)
+			}
+		}
+	}
+
+	ordered := make([]*builderTx, 0, len(b.ancestors))
+	for _, ancestor := range b.ancestors {
+		if inDegree[ancestor.txID] == 0 {
+			ordered = append(ordered, ancestor)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		for _, child := range children[ordered[i].txID] {
+			if inDegree[child.txID]--; inDegree[child.txID] == 0 {
+				ordered = append(ordered, child)
+			}
+		}
+	}
+
+	if len(ordered) != len(b.ancestors) {
+		return nil, errors.New("cannot build BEEF - ancestors contain a cycle")
+	}
+	return ordered, nil
+}
+
+// containsTxID will return true if the BUMP contains the txid (as a txid leaf at the base level)
+func (b *BUMP) containsTxID(txID string) bool {
+	if len(b.Path) == 0 {
+		return false
+	}
+	for _, leaf := range b.Path[0] {
+		if leaf.TxId && leaf.Hash == txID {
+			return true
+		}
+	}
+	return false
+}
+
+// sourceTxID will return the txid of the transaction spent by the input
+func sourceTxID(input *sdk.TransactionInput) string {
+	if input.SourceTXID != nil {
+		return input.SourceTXID.String()
+	} else if input.SourceTransaction != nil {
+		return input.SourceTransaction.TxID().String()
+	}
+	return ""
+}
@@ -0,0 +1,229 @@
+package beef
+
+import (
+	"math/rand"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	script "github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBEEFHex is a valid BEEF with 1 BUMP and 1 input transaction
+const testBEEFHex = "0100beef01fe636d0c0007021400fe507c0c7aa754cef1f7889d5fd395cf1f785dd7de98eed895dbedfe4e5bc70d1502ac4e164f5bc16746bb0868404292ac8318bbac3800e4aad13a014da427adce3e010b00bc4ff395efd11719b277694cface5aa50d085a0bb81f613f70313acd28cf4557010400574b2d9142b8d28b61d88e3b2c3f44d858411356b49a28a4643b6d1a6a092a5201030051a05fc84d531b5d250c23f4f886f6812f9fe3f402d61607f977b4ecd2701c19010000fd781529d58fc2523cf396a7f25440b409857e7e221766c57214b1d38c7b481f01010062f542f45ea3660f86c013ced80534cb5fd4c19d66c56e7e8c5d4bf2d40acc5e010100b121e91836fd7cd5102b654e9f72f3cf6fdbfd0b161c53a9c54b12c841126331020100000001cd4e4cac3c7b56920d1e7655e7e260d31f29d9a388d04910f1bbd72304a79029010000006b483045022100e75279a205a547c445719420aa3138bf14743e3f42618e5f86a19bde14bb95f7022064777d34776b05d816daf1699493fcdf2ef5a5ab1ad710d9c97bfb5b8f7cef3641210263e2dee22b1ddc5e11f6fab8bcd2378bdd19580d640501ea956ec0e786f93e76ffffffff013e660000000000001976a9146bfd5c7fbe21529d45803dbcf0c87dd3c71efbc288ac0000000001000100000001ac4e164f5bc16746bb0868404292ac8318bbac3800e4aad13a014da427adce3e000000006a47304402203a61a2e931612b4bda08d541cfb980885173b8dcf64a3471238ae7abcd368d6402204cbf24f04b9aa2256d8901f0ed97866603d2be8324c2bfb7a37bf8fc90edd5b441210263e2dee22b1ddc5e11f6fab8bcd2378bdd19580d640501ea956ec0e786f93e76ffffffff013c660000000000001976a9146bfd5c7fbe21529d45803dbcf0c87dd3c71efbc288ac0000000000"
+
+func TestDecodedBEEF_Hex_RoundTripsDecodedBEEF(t *testing.T) {
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+
+	beefHex, err := decoded.Hex()
+	require.NoError(t, err)
+	assert.Equal(t, testBEEFHex, beefHex)
+}
+
+func TestBuilder_Build_RoundTripProperty(t *testing.T) {
+	r := rand.New(rand.NewSource(62))
+
+	for i := 0; i < 200; i++ {
+		subject, ancestors := randomTxGraph(r)
+
+		builder := NewBuilder(subject)
+		for _, index := range r.Perm(len(ancestors)) { // insertion order must not matter
+			builder.AddAncestor(ancestors[index].tx, ancestors[index].bump)
+		}
+
+		built, err := builder.Build()
+		require.NoError(t, err)
+		assertTopologicalOrder(t, built)
+		assert.Equal(t, subject.TxID().String(), built.Transactions[len(built.Transactions)-1].GetTxID())
+
+		beefBytes, err := built.Bytes()
+		require.NoError(t, err)
+		beefHex, err := built.Hex()
+		require.NoError(t, err)
+
+		decoded, err := DecodeBEEF(beefHex)
+		require.NoError(t, err)
+		assert.Equal(t, built.BUMPs, decoded.BUMPs)
+		require.Len(t, decoded.Transactions, len(built.Transactions))
+		for j, td := range decoded.Transactions {
+			assert.Equal(t, built.Transactions[j].GetTxID(), td.GetTxID())
+			assert.Equal(t, built.Transactions[j].BumpIndex, td.BumpIndex)
+		}
+
+		reencoded, err := decoded.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, beefBytes, reencoded)
+	}
+}
+
+func TestBuilder_Build_SharedBUMP(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	first, second := randomTx(r, nil), randomTx(r, nil)
+	bump := &BUMP{BlockHeight: 800000, Path: [][]BUMPLeaf{{
+		{Offset: 0, Hash: first.TxID().String(), TxId: true},
+		{Offset: 1, Hash: second.TxID().String(), TxId: true},
+	}}}
+
+	built, err := NewBuilder(randomTx(r, []*sdk.Transaction{first, second})).
+		AddAncestor(first, bump).
+		AddAncestor(second, bump).
+		Build()
+	require.NoError(t, err)
+	require.Len(t, built.BUMPs, 1)
+	assert.Equal(t, sdk.VarInt(0), *built.Transactions[0].BumpIndex)
+	assert.Equal(t, sdk.VarInt(0), *built.Transactions[1].BumpIndex)
+}
+
+func TestBuilder_Build_HandlingErrors(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	parent := randomTx(r, nil)
+	parentBump := &BUMP{BlockHeight: 1, Path: [][]BUMPLeaf{{{Offset: 0, Hash: parent.TxID().String(), TxId: true}}}}
+	subject := randomTx(r, []*sdk.Transaction{parent})
+
+	testCases := []struct {
+		name    string
+		builder *Builder
+	}{
+		{"missing subject", NewBuilder(nil).AddAncestor(parent, parentBump)},
+		{"missing ancestor", NewBuilder(subject).AddAncestor(nil, nil)},
+		{"no BUMPs", NewBuilder(subject).AddAncestor(parent, nil)},
+		{"duplicate ancestor", NewBuilder(subject).AddAncestor(parent, parentBump).AddAncestor(parent, parentBump)},
+		{"subject as ancestor", NewBuilder(subject).AddAncestor(subject, nil).AddAncestor(parent, parentBump)},
+		{"subject is a parent", NewBuilder(parent).AddAncestor(subject, parentBump)},
+		{"BUMP without txid", NewBuilder(subject).AddAncestor(parent, &BUMP{Path: [][]BUMPLeaf{{{Offset: 0, Hash: parent.TxID().String()}}}})},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.builder.Build()
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestDecodedBEEF_Bytes_HandlingErrors(t *testing.T) {
+	index := sdk.VarInt(1)
+	testCases := []struct {
+		name    string
+		decoded *DecodedBEEF
+	}{
+		{"missing transaction", &DecodedBEEF{Transactions: []*TxData{{}}}},
+		{"BUMP index out of range", &DecodedBEEF{Transactions: []*TxData{{Transaction: sdk.NewTransaction(), BumpIndex: &index}}}},
+		{"invalid leaf hash", &DecodedBEEF{BUMPs: BUMPs{{Path: [][]BUMPLeaf{{{Hash: "zz"}}}}}}},
+		{"tree too high", &DecodedBEEF{BUMPs: BUMPs{{Path: make([][]BUMPLeaf, maxTreeHeight+1)}}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := tc.decoded.Bytes()
+			assert.Error(t, err)
+		})
+	}
+}
+
+type randomAncestor struct {
+	bump *BUMP
+	tx   *sdk.Transaction
+}
+
+// randomTxGraph will return a subject with random ancestors (mined or spending earlier ancestors)
+func randomTxGraph(r *rand.Rand) (*sdk.Transaction, []*randomAncestor) {
+	ancestors := make([]*randomAncestor, 0)
+	for n := 1 + r.Intn(6); len(ancestors) < n; {
+		var parents []*sdk.Transaction
+		if len(ancestors) > 0 && r.Intn(2) == 0 {
+			for _, index := range r.Perm(len(ancestors))[:1+r.Intn(len(ancestors))] {
+				parents = append(parents, ancestors[index].tx)
+			}
+		}
+
+		tx := randomTx(r, parents)
+		ancestor := &randomAncestor{tx: tx}
+		if len(parents) == 0 {
+			ancestor.bump = randomBUMP(r, tx.TxID().String())
+		}
+		ancestors = append(ancestors, ancestor)
+	}
+
+	var parents []*sdk.Transaction
+	for _, ancestor := range ancestors[len(ancestors)-1-r.Intn(len(ancestors)):] {
+		parents = append(parents, ancestor.tx)
+	}
+	return randomTx(r, parents), ancestors
+}
+
+// randomTx will return a random transaction spending the parents (or random outpoints)
+func randomTx(r *rand.Rand, parents []*sdk.Transaction) *sdk.Transaction {
+	tx := sdk.NewTransaction()
+	tx.Version = r.Uint32()
+	tx.LockTime = r.Uint32()
+
+	for _, parent := range parents {
+		tx.AddInputFromTx(parent, 0, nil)
+	}
+	if len(parents) == 0 {
+		for i := 0; i <= r.Intn(3); i++ {
+			hash := chainhash.Hash(randomBytes(r, 32))
+			tx.AddInput(&sdk.TransactionInput{SourceTXID: &hash, SourceTxOutIndex: r.Uint32(), SequenceNumber: r.Uint32()})
+		}
+	}
+	for _, input := range tx.Inputs {
+		unlockingScript := script.NewFromBytes(randomBytes(r, r.Intn(120)))
+		input.UnlockingScript = unlockingScript
+	}
+
+	for i := 0; i <= r.Intn(3); i++ {
+		tx.AddOutput(&sdk.TransactionOutput{
+			LockingScript: script.NewFromBytes(randomBytes(r, 1+r.Intn(60))),
+			Satoshis:      r.Uint64() % 21e14,
+		})
+	}
+	return tx
+}
+
+// randomBUMP will return a random (structurally valid) BUMP containing the txid
+func randomBUMP(r *rand.Rand, txID string) *BUMP {
+	bump := &BUMP{BlockHeight: r.Uint64() % (1 << 40), Path: make([][]BUMPLeaf, 1+r.Intn(12))}
+	for level := range bump.Path {
+		bump.Path[level] = make([]BUMPLeaf, 0) // decoded levels are never nil
+		for i := 0; i < r.Intn(4); i++ {
+			leaf := BUMPLeaf{Offset: r.Uint64() % (1 << (32 - level))}
+			switch r.Intn(3) {
+			case 0:
+				leaf.Duplicate = true
+			case 1:
+				leaf.TxId = level == 0
+				leaf.Hash = chainhash.Hash(randomBytes(r, 32)).String()
+			default:
+				leaf.Hash = chainhash.Hash(randomBytes(r, 32)).String()
+			}
+			bump.Path[level] = append(bump.Path[level], leaf)
+		}
+	}
+	bump.Path[0] = append(bump.Path[0], BUMPLeaf{Offset: r.Uint64() % 1000, Hash: txID, TxId: true})
+	return bump
+}
+
+func randomBytes(r *rand.Rand, n int) []byte {
+	b := make([]byte, n)
+	_, _ = r.Read(b)
+	return b
+}
+
+// assertTopologicalOrder will check that every parent in the BEEF comes before its children
+func assertTopologicalOrder(t *testing.T, decoded *DecodedBEEF) {
+	positions := make(map[string]int, len(decoded.Transactions))
+	for i, td := range decoded.Transactions {
+		positions[td.GetTxID()] = i
+	}
+	for i, td := range decoded.Transactions {
+		for _, input := range td.Transaction.Inputs {
+			if position, ok := positions[input.SourceTXID.String()]; ok {
+				assert.Less(t, position, i)
+			}
+		}
+	}
+}
@@ -1,10 +1,13 @@
 package beef
 
 import (
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	script "github.com/bitcoin-sv/go-sdk/script"
 	sdk "github.com/bitcoin-sv/go-sdk/transaction"
@@ -151,6 +154,18 @@ func TestDecodeBEEF_DecodeBEEF_HandlingErrors(t *testing.T) {
 			expectedError:                errors.New("invalid format of transaction, BEEF marker not found"),
 			expectedCMPForTheOldestInput: false,
 		},
+		{
+			name:                "unable to decode BEEF - unknown version",
+			hexStream:           "0300beef01",
+			expectedDecodedBEEF: nil,
+			expectedError:       errors.New("invalid BEEF version: 4022206467"),
+		},
+		{
+			name:                "unable to decode Atomic BEEF - not enough bytes for subject txid",
+			hexStream:           "010101010100beef",
+			expectedDecodedBEEF: nil,
+			expectedError:       errors.New("invalid atomic beef - insufficient bytes to extract subject txid"),
+		},
 		{
 			name:                "unable to decode BUMP block height - proper BEEF marker and number of bumps",
 			hexStream:           "0100beef01",
@@ -274,3 +289,82 @@ func TestDecodeBEEF_InvalidBeef_RetunrError(t *testing.T) {
 		})
 	}
 }
+
+func TestDecodeBEEF_V2AndAtomic(t *testing.T) {
+	v1, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	require.Equal(t, BEEFVersion1, v1.Version)
+	require.False(t, v1.IsAtomic())
+
+	parentTxID, subjectTxID := v1.Transactions[0].GetTxID(), v1.Transactions[1].GetTxID()
+
+	t.Run("BEEF V2", func(t *testing.T) {
+		v2 := &DecodedBEEF{BUMPs: v1.BUMPs, Transactions: v1.Transactions, Version: BEEFVersion2}
+		beefBytes, err := v2.Bytes()
+		require.NoError(t, err)
+		assert.Equal(t, "0200beef", hex.EncodeToString(beefBytes[:4]))
+
+		// then the go-sdk decodes the same BEEF V2
+		sdkBEEF, err := sdk.NewBeefFromBytes(beefBytes)
+		require.NoError(t, err)
+		assert.Contains(t, sdkBEEF.Transactions, parentTxID)
+		assert.Contains(t, sdkBEEF.Transactions, subjectTxID)
+
+		decoded, err := DecodeBEEF(hex.EncodeToString(beefBytes))
+		require.NoError(t, err)
+		assert.Equal(t, BEEFVersion2, decoded.Version)
+		assert.Equal(t, v1.BUMPs, decoded.BUMPs)
+		require.Len(t, decoded.Transactions, 2)
+		assert.Equal(t, parentTxID, decoded.Transactions[0].GetTxID())
+		assert.Equal(t, v1.Transactions[0].BumpIndex, decoded.Transactions[0].BumpIndex)
+		assert.Equal(t, subjectTxID, decoded.GetLatestTx().TxID().String())
+	})
+
+	t.Run("BEEF V2 with txid only transaction", func(t *testing.T) {
+		v2 := &DecodedBEEF{BUMPs: v1.BUMPs, Transactions: []*TxData{NewTxIDOnly(parentTxID), v1.Transactions[1]}, Version: BEEFVersion2}
+		beefHex, err := v2.Hex()
+		require.NoError(t, err)
+
+		decoded, err := DecodeBEEF(beefHex)
+		require.NoError(t, err)
+		assert.True(t, decoded.Transactions[0].IsTxIDOnly())
+		assert.True(t, decoded.Transactions[0].Unmined())
+		assert.Equal(t, parentTxID, decoded.Transactions[0].GetTxID())
+		assert.False(t, decoded.Transactions[1].IsTxIDOnly())
+
+		reencoded, err := decoded.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, beefHex, reencoded)
+	})
+
+	t.Run("BEEF V2 with txid only last transaction", func(t *testing.T) {
+		v2 := &DecodedBEEF{BUMPs: v1.BUMPs, Transactions: []*TxData{v1.Transactions[0], NewTxIDOnly(subjectTxID)}, Version: BEEFVersion2}
+		beefHex, err := v2.Hex()
+		require.NoError(t, err)
+
+		_, err = DecodeBEEF(beefHex)
+		require.EqualError(t, err, "invalid BEEF - the last transaction cannot be txid only")
+	})
+
+	t.Run("txid only transaction in BEEF V1", func(t *testing.T) {
+		v2 := &DecodedBEEF{BUMPs: v1.BUMPs, Transactions: []*TxData{NewTxIDOnly(parentTxID), v1.Transactions[1]}, Version: BEEFVersion1}
+		_, err := v2.Bytes()
+		require.Error(t, err)
+	})
+
+	for _, version := range []uint32{BEEFVersion1, BEEFVersion2} {
+		t.Run(fmt.Sprintf("Atomic BEEF version %d", version), func(t *testing.T) {
+			atomic := &DecodedBEEF{AtomicTxID: subjectTxID, BUMPs: v1.BUMPs, Transactions: v1.Transactions, Version: version}
+			beefHex, err := atomic.Hex()
+			require.NoError(t, err)
+			assert.Equal(t, "01010101", beefHex[:8])
+
+			decoded, err := DecodeBEEF(beefHex)
+			require.NoError(t, err)
+			assert.True(t, decoded.IsAtomic())
+			assert.Equal(t, subjectTxID, decoded.AtomicTxID)
+			assert.Equal(t, version, decoded.Version)
+			assert.Equal(t, subjectTxID, decoded.GetLatestTx().TxID().String())
+		})
+	}
+}
@@ -1,7 +1,12 @@
 package beef
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/hex"
 	"errors"
+	"fmt"
+	"slices"
 )
 
 // BUMPs represents a slice of BUMPs - BSV Unified Merkle Paths
@@ -28,6 +33,199 @@ const (
 	txIDFlag
 )
 
+// NewBUMPFromBytes will decode a BUMP (BRC-74 binary format)
+func NewBUMPFromBytes(bumpBytes []byte) (*BUMP, error) {
+	d := NewDecoder(bytes.NewReader(bumpBytes), Limits{})
+	d.format = binaryFormat
+	d.init()
+
+	bump, err := d.readBUMP()
+	if err != nil {
+		return nil, err
+	}
+	if _, err = d.r.ReadByte(); err == nil {
+		return nil, errors.New("invalid BUMP - unexpected bytes after the BUMP")
+	}
+	return bump, nil
+}
+
+// NewBUMPFromHex will decode a hex encoded BUMP (BRC-74)
+func NewBUMPFromHex(bumpHex string) (*BUMP, error) {
+	bumpBytes, err := hex.DecodeString(bumpHex)
+	if err != nil {
+		return nil, errors.New("invalid BUMP hex stream")
+	}
+	return NewBUMPFromBytes(bumpBytes)
+}
+
+// Bytes will serialize the BUMP (BRC-74 binary format)
+func (b *BUMP) Bytes() ([]byte, error) {
+	return b.appendBytes(nil)
+}
+
+// Hex will serialize the BUMP as a hex string (BRC-74)
+func (b *BUMP) Hex() (string, error) {
+	bumpBytes, err := b.Bytes()
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bumpBytes), nil
+}
+
+// TxIDs will return the txids of the transactions proven by the BUMP (the txid leaves of the base level)
+func (b *BUMP) TxIDs() []string {
+	if len(b.Path) == 0 {
+		return nil
+	}
+
+	txIDs := make([]string, 0, len(b.Path[0]))
+	for _, leaf := range b.Path[0] {
+		if leaf.TxId {
+			txIDs = append(txIDs, leaf.Hash)
+		}
+	}
+	return txIDs
+}
+
+// Merge will combine the BUMP with another BUMP of the same block into one compound BUMP
+//
+// Both BUMPs must have the same merkle root, the result proves the txids of both
+// and contains only the leaves required to compute the merkle root
+func (b *BUMP) Merge(other *BUMP) error {
+	if other == nil {
+		return errors.New("cannot merge BUMPs - BUMP is missing")
+	}
+	if b.BlockHeight != other.BlockHeight {
+		return fmt.Errorf("cannot merge BUMPs of different blocks (%d and %d)", b.BlockHeight, other.BlockHeight)
+	}
+	if len(b.Path) != len(other.Path) {
+		return errors.New("cannot merge BUMPs with different tree heights")
+	}
+
+	root, err := b.CalculateMerkleRoot()
+	if err != nil {
+		return err
+	}
+	otherRoot, err := other.CalculateMerkleRoot()
+	if err != nil {
+		return err
+	}
+	if len(root) == 0 || root != otherRoot {
+		return errors.New("cannot merge BUMPs with different merkle roots")
+	}
+
+	merged := &BUMP{BlockHeight: b.BlockHeight, Path: make([][]BUMPLeaf, len(b.Path))}
+	for level := range b.Path {
+		merged.Path[level] = slices.Clone(b.Path[level])
+		for _, leaf := range other.Path[level] {
+			existing := findLeafIndexByOffset(leaf.Offset, merged.Path[level])
+			if existing < 0 {
+				merged.Path[level] = append(merged.Path[level], leaf)
+				continue
+			}
+
+			if merged.Path[level][existing].Hash != leaf.Hash || merged.Path[level][existing].Duplicate != leaf.Duplicate {
+				return fmt.Errorf("cannot merge BUMPs - conflicting leaves at offset %d of level %d", leaf.Offset, level)
+			}
+			merged.Path[level][existing].TxId = merged.Path[level][existing].TxId || leaf.TxId
+		}
+	}
+
+	path, err := merged.trimmedPath(merged.TxIDs())
+	if err != nil {
+		return err
+	}
+	b.Path = path
+	return nil
+}
+
+// Trim will remove the txids (and the leaves) which are not required to prove the given txids
+func (b *BUMP) Trim(txIDs []string) error {
+	if len(txIDs) == 0 {
+		return errors.New("cannot trim BUMP - no txids provided")
+	}
+
+	path, err := b.trimmedPath(txIDs)
+	if err != nil {
+		return err
+	}
+	b.Path = path
+	return nil
+}
+
+// trimmedPath will return the path with the txids and the leaves required to compute the merkle root
+func (b *BUMP) trimmedPath(txIDs []string) ([][]BUMPLeaf, error) {
+	if len(b.Path) == 0 {
+		return nil, errors.New("cannot trim BUMP - path is empty")
+	}
+
+	path := make([][]BUMPLeaf, len(b.Path))
+	ancestors := make(map[uint64]bool, len(txIDs))
+	for _, txID := range txIDs {
+		index := slices.IndexFunc(b.Path[0], func(leaf BUMPLeaf) bool { return leaf.TxId && leaf.Hash == txID })
+		if index < 0 {
+			return nil, fmt.Errorf("cannot trim BUMP - txid %s not found", txID)
+		}
+		if !ancestors[b.Path[0][index].Offset] {
+			ancestors[b.Path[0][index].Offset] = true
+			path[0] = append(path[0], b.Path[0][index])
+		}
+	}
+
+	for level := range b.Path {
+		parents := make(map[uint64]bool, len(ancestors))
+		for offset := range ancestors {
+			parents[offset/2] = true
+			if ancestors[getOffsetPair(offset)] {
+				continue
+			}
+
+			leaf, err := b.nodeAt(level, getOffsetPair(offset))
+			if err != nil {
+				return nil, err
+			}
+			leaf.TxId = false
+			path[level] = append(path[level], leaf)
+		}
+		ancestors = parents
+
+		if path[level] == nil {
+			path[level] = make([]BUMPLeaf, 0)
+		}
+		slices.SortFunc(path[level], func(a, b BUMPLeaf) int {
+			return cmp.Compare(a.Offset, b.Offset)
+		})
+	}
+
+	return path, nil
+}
+
+// nodeAt will return the leaf at the offset of the level (computed from the lower levels if missing)
+func (b *BUMP) nodeAt(level int, offset uint64) (BUMPLeaf, error) {
+	if leaf := findLeafByOffset(offset, b.Path[level]); leaf != nil {
+		return *leaf, nil
+	}
+	if level == 0 {
+		return BUMPLeaf{}, fmt.Errorf("invalid BUMP - missing leaf at offset %d of level 0", offset)
+	}
+
+	left, err := b.nodeAt(level-1, offset*2)
+	if err != nil {
+		return BUMPLeaf{}, err
+	}
+	right, err := b.nodeAt(level-1, offset*2+1)
+	if err != nil {
+		return BUMPLeaf{}, err
+	}
+
+	leftHash, rightHash := prepareNodes(left, offset*2, right, offset*2+1)
+	hash, err := merkleTreeParentStr(leftHash, rightHash)
+	if err != nil {
+		return BUMPLeaf{}, err
+	}
+	return BUMPLeaf{Hash: hash, Offset: offset}, nil
+}
+
 // CalculateMerkleRoot will calculate the merkle root for the BUMP
 func (b BUMP) CalculateMerkleRoot() (string, error) {
 	merkleRoot := ""
@@ -110,6 +308,10 @@ func findLeafByOffset(offset uint64, bumpLeaves []BUMPLeaf) *BUMPLeaf {
 	return nil
 }
 
+func findLeafIndexByOffset(offset uint64, bumpLeaves []BUMPLeaf) int {
+	return slices.IndexFunc(bumpLeaves, func(leaf BUMPLeaf) bool { return leaf.Offset == offset })
+}
+
 func calculateFromChildren(offset uint64, bumpLeaves []BUMPLeaf) (*BUMPLeaf, error) {
 	offsetChild := offset * 2
 	offsetChildPair := offsetChild + 1
@@ -0,0 +1,165 @@
+package beef
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// BUMP of the mainnet block 813706 (BRC-74 example)
+const (
+	brc74Hex   = "fe8a6a0c000c04fde80b0011774f01d26412f0d16ea3f0447be0b5ebec67b0782e321a7a01cbdf7f734e30fde90b02004e53753e3fe4667073063a17987292cfdea278824e9888e52180581d7188d8fdea0b025e441996fc53f0191d649e68a200e752fb5f39e0d5617083408fa179ddc5c998fdeb0b0102fdf405000671394f72237d08a4277f4435e5b6edf7adc272f25effef27cdfe805ce71a81fdf50500262bccabec6c4af3ed00cc7a7414edea9c5efa92fb8623dd6160a001450a528201fdfb020101fd7c010093b3efca9b77ddec914f8effac691ecb54e2c81d0ab81cbc4c4b93befe418e8501bf01015e005881826eb6973c54003a02118fe270f03d46d02681c8bc71cd44c613e86302f8012e00e07a2bb8bb75e5accff266022e1e5e6e7b4d6d943a04faadcf2ab4a22f796ff30116008120cafa17309c0bb0e0ffce835286b3a2dcae48e4497ae2d2b7ced4f051507d010a00502e59ac92f46543c23006bff855d96f5e648043f0fb87a7a5949e6a9bebae430104001ccd9f8f64f4d0489b30cc815351cf425e0e78ad79a589350e4341ac165dbe45010301010000af8764ce7e1cc132ab5ed2229a005c87201c9a5ee15c0f91dd53eff31ab30cd4"
+	brc74Root  = "57aab6e6fb1b697174ffb64e062c4728f2ffd33ddcfa02a43b64d8cd29b483b4"
+	brc74TxID1 = "d888711d588021e588984e8278a2decf927298173a06737066e43f3e75534e00"
+	brc74TxID2 = "98c9c5dd79a18f40837061d5e0395ffb52e700a2689e641d19f053fc9619445e"
+)
+
+func TestNewBUMPFromHex(t *testing.T) {
+	t.Run("mainnet block", func(t *testing.T) {
+		bump, err := NewBUMPFromHex(brc74Hex)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(813706), bump.BlockHeight)
+		assert.Len(t, bump.Path, 12)
+		assert.Equal(t, []string{brc74TxID1, brc74TxID2}, bump.TxIDs())
+
+		root, err := bump.CalculateMerkleRoot()
+		require.NoError(t, err)
+		assert.Equal(t, brc74Root, root)
+
+		bumpHex, err := bump.Hex()
+		require.NoError(t, err)
+		assert.Equal(t, brc74Hex, bumpHex)
+	})
+
+	t.Run("BUMP of the BEEF", func(t *testing.T) {
+		decoded, err := DecodeBEEF(testBEEFHex)
+		require.NoError(t, err)
+
+		bumpHex, err := decoded.BUMPs[0].Hex()
+		require.NoError(t, err)
+		bump, err := NewBUMPFromHex(bumpHex)
+		require.NoError(t, err)
+		assert.Equal(t, decoded.BUMPs[0], bump)
+	})
+
+	testCases := map[string]string{
+		"invalid hex":       "zz",
+		"empty":             "",
+		"truncated":         brc74Hex[:len(brc74Hex)-2],
+		"trailing bytes":    brc74Hex + "00",
+		"tree too high":     "fe8a6a0c0041",
+		"invalid leaf flag": "fe8a6a0c000101fde80b03",
+	}
+	for name, bumpHex := range testCases {
+		t.Run(name, func(t *testing.T) {
+			bump, err := NewBUMPFromHex(bumpHex)
+			require.Error(t, err)
+			assert.Nil(t, bump)
+		})
+	}
+}
+
+func TestBUMP_Trim(t *testing.T) {
+	t.Run("trim to both txids removes the computable leaves", func(t *testing.T) {
+		bump, err := NewBUMPFromHex(brc74Hex)
+		require.NoError(t, err)
+
+		require.NoError(t, bump.Trim([]string{brc74TxID1, brc74TxID2}))
+		assert.Equal(t, []string{brc74TxID1, brc74TxID2}, bump.TxIDs())
+		assert.Empty(t, bump.Path[1]) // both nodes of the level are computed from the base level
+		assertMerkleRoot(t, brc74Root, bump)
+	})
+
+	for _, txID := range []string{brc74TxID1, brc74TxID2} {
+		t.Run("trim to "+txID, func(t *testing.T) {
+			bump, err := NewBUMPFromHex(brc74Hex)
+			require.NoError(t, err)
+
+			require.NoError(t, bump.Trim([]string{txID}))
+			assert.Equal(t, []string{txID}, bump.TxIDs())
+			assertMerkleRoot(t, brc74Root, bump)
+			assert.Len(t, bump.Path[0], 2) // the txid and its pair
+			for _, leaves := range bump.Path[1:] {
+				assert.Len(t, leaves, 1)
+			}
+
+			// the trimmed BUMP can be serialized and decoded
+			bumpHex, err := bump.Hex()
+			require.NoError(t, err)
+			decoded, err := NewBUMPFromHex(bumpHex)
+			require.NoError(t, err)
+			assert.Equal(t, bump, decoded)
+		})
+	}
+
+	t.Run("unknown txid", func(t *testing.T) {
+		bump, err := NewBUMPFromHex(brc74Hex)
+		require.NoError(t, err)
+
+		require.Error(t, bump.Trim([]string{brc74Root}))
+		require.Error(t, bump.Trim(nil))
+		assert.Equal(t, []string{brc74TxID1, brc74TxID2}, bump.TxIDs())
+	})
+}
+
+func TestBUMP_Merge(t *testing.T) {
+	single := func(t *testing.T, txID string) *BUMP {
+		bump, err := NewBUMPFromHex(brc74Hex)
+		require.NoError(t, err)
+		require.NoError(t, bump.Trim([]string{txID}))
+		return bump
+	}
+
+	t.Run("merge paths of the same block", func(t *testing.T) {
+		expected, err := NewBUMPFromHex(brc74Hex)
+		require.NoError(t, err)
+		require.NoError(t, expected.Trim(expected.TxIDs()))
+
+		bump := single(t, brc74TxID1)
+		require.NoError(t, bump.Merge(single(t, brc74TxID2)))
+		assert.Equal(t, expected, bump)
+		assertMerkleRoot(t, brc74Root, bump)
+	})
+
+	t.Run("merge with itself", func(t *testing.T) {
+		bump := single(t, brc74TxID1)
+		require.NoError(t, bump.Merge(single(t, brc74TxID1)))
+		assert.Equal(t, single(t, brc74TxID1), bump)
+	})
+
+	t.Run("different block", func(t *testing.T) {
+		other := single(t, brc74TxID2)
+		other.BlockHeight++
+
+		bump := single(t, brc74TxID1)
+		require.Error(t, bump.Merge(other))
+		assert.Equal(t, single(t, brc74TxID1), bump)
+	})
+
+	t.Run("different merkle root", func(t *testing.T) {
+		other := single(t, brc74TxID2)
+		other.Path[len(other.Path)-1][0].Hash = brc74Root
+
+		bump := single(t, brc74TxID1)
+		require.Error(t, bump.Merge(other))
+		assert.Equal(t, single(t, brc74TxID1), bump)
+	})
+
+	t.Run("different tree height", func(t *testing.T) {
+		other := single(t, brc74TxID2)
+		other.Path = other.Path[:len(other.Path)-1]
+		require.Error(t, single(t, brc74TxID1).Merge(other))
+	})
+
+	t.Run("missing BUMP", func(t *testing.T) {
+		require.Error(t, single(t, brc74TxID1).Merge(nil))
+	})
+}
+
+func assertMerkleRoot(t *testing.T, expected string, bump *BUMP) {
+	t.Helper()
+	root, err := bump.CalculateMerkleRoot()
+	require.NoError(t, err)
+	assert.Equal(t, expected, root)
+}
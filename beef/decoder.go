@@ -0,0 +1,500 @@
+package beef
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+)
+
+// Limits caps the size of a decoded BEEF (zero values are unlimited)
+type Limits struct {
+	MaxBumps      int   // Maximum number of BUMPs
+	MaxBytes      int64 // Maximum size of the binary BEEF (hex streams are twice as long)
+	MaxTreeHeight int   // Maximum height of a BUMP (can not be greater than 64)
+	MaxTxs        int   // Maximum number of transactions (txid only transactions included)
+}
+
+// DefaultLimits are the limits for decoding untrusted BEEFs (used by the paymail server)
+var DefaultLimits = Limits{
+	MaxBumps:      1000,
+	MaxBytes:      32 << 20,
+	MaxTreeHeight: maxTreeHeight,
+	MaxTxs:        10000,
+}
+
+// ErrLimitExceeded is returned (wrapped) when the BEEF exceeds the Limits of the Decoder
+var ErrLimitExceeded = errors.New("BEEF limit exceeded")
+
+type streamFormat int
+
+const (
+	detectFormat streamFormat = iota
+	binaryFormat
+	hexFormat
+)
+
+// Decoder decodes a BEEF V1, BEEF V2 or Atomic BEEF incrementally from a stream
+//
+// The stream can contain the raw bytes or the hex encoded BEEF (detected from the first byte),
+// decoding stops as soon as one of the limits is exceeded
+type Decoder struct {
+	format  streamFormat
+	limited *limitReader
+	limits  Limits
+	r       *bufio.Reader
+	source  io.Reader
+}
+
+// NewDecoder will return a Decoder reading the raw or hex encoded BEEF from the reader
+func NewDecoder(r io.Reader, limits Limits) *Decoder {
+	if limits.MaxTreeHeight <= 0 || limits.MaxTreeHeight > maxTreeHeight {
+		limits.MaxTreeHeight = maxTreeHeight
+	}
+	return &Decoder{format: detectFormat, limits: limits, source: r}
+}
+
+// newHexDecoder will return a Decoder reading only hex encoded BEEF
+func newHexDecoder(r io.Reader, limits Limits) *Decoder {
+	d := NewDecoder(r, limits)
+	d.format = hexFormat
+	return d
+}
+
+// Decode will decode the BEEF (bytes following the BEEF are not read)
+func (d *Decoder) Decode() (*DecodedBEEF, error) {
+	d.init()
+
+	decoded, err := d.decode()
+	if err != nil && d.limited.err != nil {
+		if errors.Is(d.limited.err, ErrLimitExceeded) {
+			return nil, d.limited.err
+		} else if d.format == hexFormat {
+			return nil, errors.New("invalid beef hex stream")
+		}
+		return nil, d.limited.err
+	}
+	return decoded, err
+}
+
+// init will detect the format of the stream and set up the readers
+func (d *Decoder) init() {
+	source := bufio.NewReader(d.source)
+	if d.format == detectFormat {
+		d.format = binaryFormat
+		if first, err := source.Peek(1); err == nil && isHexDigit(first[0]) {
+			d.format = hexFormat
+		}
+	}
+
+	var r io.Reader = source
+	if d.format == hexFormat {
+		r = hex.NewDecoder(source)
+	}
+	d.limited = &limitReader{max: d.limits.MaxBytes, r: r}
+	d.r = bufio.NewReader(d.limited)
+}
+
+func (d *Decoder) decode() (*DecodedBEEF, error) {
+	atomicTxID, version, err := d.readHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	bumps, err := d.readBUMPs()
+	if err != nil {
+		return nil, err
+	}
+
+	transactions, err := d.readTransactions(version, len(bumps))
+	if err != nil {
+		return nil, err
+	}
+
+	return &DecodedBEEF{
+		AtomicTxID:   atomicTxID,
+		BUMPs:        bumps,
+		Transactions: transactions,
+		Version:      version,
+	}, nil
+}
+
+// readHeader will read the Atomic BEEF prefix and subject txid (if present), the version and the marker
+func (d *Decoder) readHeader() (string, uint32, error) {
+	header := make([]byte, versionBytesCount+markerBytesCount)
+	if _, err := io.ReadFull(d.r, header); err != nil {
+		return "", 0, d.invalidStreamError()
+	}
+
+	var atomicTxID string
+	if binary.LittleEndian.Uint32(header) == AtomicBEEFPrefix {
+		subjectTxID, err := d.readHash()
+		if err != nil {
+			return "", 0, errors.New("invalid atomic beef - insufficient bytes to extract subject txid")
+		}
+		atomicTxID = subjectTxID.String()
+
+		if _, err = io.ReadFull(d.r, header); err != nil {
+			return "", 0, d.invalidStreamError()
+		}
+	}
+
+	if err := validateMarker(header[versionBytesCount:]); err != nil {
+		return "", 0, err
+	}
+
+	version := binary.LittleEndian.Uint32(header)
+	if version != BEEFVersion1 && version != BEEFVersion2 {
+		return "", 0, fmt.Errorf("invalid BEEF version: %d", version)
+	}
+
+	return atomicTxID, version, nil
+}
+
+func (d *Decoder) readBUMPs() ([]*BUMP, error) {
+	nBump, err := d.readVarInt()
+	if err != nil {
+		return nil, errors.New("cannot decode BUMP - no bytes provided")
+	}
+	if nBump == 0 {
+		return nil, errors.New("invalid BEEF- lack of BUMPs")
+	}
+	if d.limits.MaxBumps > 0 && uint64(nBump) > uint64(d.limits.MaxBumps) {
+		return nil, fmt.Errorf("%w - %d BUMPs (max %d)", ErrLimitExceeded, uint64(nBump), d.limits.MaxBumps)
+	}
+
+	bumps := make([]*BUMP, 0, initialCapacity(uint64(nBump)))
+	for i := uint64(0); i < uint64(nBump); i++ {
+		bump, err := d.readBUMP()
+		if err != nil {
+			return nil, err
+		}
+		bumps = append(bumps, bump)
+	}
+
+	return bumps, nil
+}
+
+func (d *Decoder) readBUMP() (*BUMP, error) {
+	blockHeight, err := d.readVarInt()
+	if err != nil {
+		return nil, errors.New("insufficient bytes to extract BUMP blockHeight")
+	}
+
+	treeHeight, err := d.r.ReadByte()
+	if err != nil {
+		return nil, errors.New("insufficient bytes to extract BUMP treeHeight")
+	}
+	if int(treeHeight) > maxTreeHeight {
+		return nil, fmt.Errorf("invalid BEEF - treeHeight cannot be grater than %d", maxTreeHeight)
+	}
+	if int(treeHeight) > d.limits.MaxTreeHeight {
+		return nil, fmt.Errorf("%w - BUMP treeHeight %d (max %d)", ErrLimitExceeded, treeHeight, d.limits.MaxTreeHeight)
+	}
+
+	bumpPaths := make([][]BUMPLeaf, 0, treeHeight)
+	for i := 0; i < int(treeHeight); i++ {
+		nLeaves, err := d.readVarInt()
+		if err != nil {
+			return nil, errors.New("cannot decode BUMP paths number of leaves from stream - no bytes provided")
+		}
+		bumpPath, err := d.readBUMPLevel(nLeaves)
+		if err != nil {
+			return nil, err
+		}
+		bumpPaths = append(bumpPaths, bumpPath)
+	}
+
+	return &BUMP{
+		BlockHeight: uint64(blockHeight),
+		Path:        bumpPaths,
+	}, nil
+}
+
+func (d *Decoder) readBUMPLevel(nLeaves sdk.VarInt) ([]BUMPLeaf, error) {
+	bumpPath := make([]BUMPLeaf, 0)
+	for i := uint64(0); i < uint64(nLeaves); i++ {
+		offset, err := d.readVarInt()
+		if err != nil {
+			return nil, fmt.Errorf("insufficient bytes to extract offset for %d leaf of %d leaves", i, uint64(nLeaves))
+		}
+
+		flag, err := d.r.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("insufficient bytes to extract flag for %d leaf of %d leaves", i, uint64(nLeaves))
+		}
+
+		if flag != dataFlag && flag != duplicateFlag && flag != txIDFlag {
+			return nil, fmt.Errorf("invalid flag: %d for %d leaf of %d leaves", flag, i, uint64(nLeaves))
+		}
+
+		if flag == duplicateFlag {
+			bumpPath = append(bumpPath, BUMPLeaf{
+				Offset:    uint64(offset),
+				Duplicate: true,
+			})
+			continue
+		}
+
+		hash, err := d.readHash()
+		if err != nil {
+			return nil, errors.New("insufficient bytes to extract hash of path")
+		}
+
+		bumpPath = append(bumpPath, BUMPLeaf{
+			Hash:   hash.String(),
+			Offset: uint64(offset),
+			TxId:   flag == txIDFlag,
+		})
+	}
+
+	return bumpPath, nil
+}
+
+func (d *Decoder) readTransactions(version uint32, nBumps int) ([]*TxData, error) {
+	nTransactions, err := d.readVarInt()
+	if err != nil {
+		return nil, errors.New("insufficient bytes to extract number of transactions")
+	}
+	if nTransactions < 2 {
+		return nil, errors.New("invalid BEEF- not enough transactions provided to decode BEEF")
+	}
+	if d.limits.MaxTxs > 0 && uint64(nTransactions) > uint64(d.limits.MaxTxs) {
+		return nil, fmt.Errorf("%w - %d transactions (max %d)", ErrLimitExceeded, uint64(nTransactions), d.limits.MaxTxs)
+	}
+
+	transactions := make([]*TxData, 0, initialCapacity(uint64(nTransactions)))
+	for i := 0; uint64(i) < uint64(nTransactions); i++ {
+		var txData *TxData
+		if version == BEEFVersion2 {
+			txData, err = d.readTransactionV2(i)
+		} else {
+			txData, err = d.readTransactionV1(i)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if !txData.Unmined() && uint64(*txData.BumpIndex) >= uint64(nBumps) {
+			return nil, fmt.Errorf("invalid BUMP index %d for transaction at index %d", uint64(*txData.BumpIndex), i)
+		}
+		transactions = append(transactions, txData)
+	}
+
+	if transactions[len(transactions)-1].IsTxIDOnly() {
+		return nil, errors.New("invalid BEEF - the last transaction cannot be txid only")
+	}
+
+	return transactions, nil
+}
+
+// readTransactionV1 will read the transaction followed by the HasBump flag (BRC-62)
+func (d *Decoder) readTransactionV1(i int) (*TxData, error) {
+	tx, err := d.readTransaction(i)
+	if err != nil {
+		return nil, err
+	}
+
+	flag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("insufficient bytes to extract HasCMP flag for transaction at index %d", i)
+	}
+
+	txData := &TxData{Transaction: tx}
+	switch flag {
+	case HasBump:
+		if txData.BumpIndex, err = d.readBumpIndex(i); err != nil {
+			return nil, err
+		}
+	case HasNoBump:
+	default:
+		return nil, fmt.Errorf("invalid HasCMP flag for transaction at index %d", i)
+	}
+
+	return txData, nil
+}
+
+// readTransactionV2 will read the format flag followed by the BUMP index and transaction or the txid (BRC-96)
+func (d *Decoder) readTransactionV2(i int) (*TxData, error) {
+	flag, err := d.r.ReadByte()
+	if err != nil {
+		return nil, fmt.Errorf("insufficient bytes to extract format flag for transaction at index %d", i)
+	}
+
+	var bumpIndex *sdk.VarInt
+	switch flag {
+	case TxIDOnly:
+		txID, err := d.readHash()
+		if err != nil {
+			return nil, fmt.Errorf("insufficient bytes to extract txid for transaction at index %d", i)
+		}
+		return NewTxIDOnly(txID.String()), nil
+	case HasBump:
+		if bumpIndex, err = d.readBumpIndex(i); err != nil {
+			return nil, err
+		}
+	case HasNoBump:
+	default:
+		return nil, fmt.Errorf("invalid format flag for transaction at index %d", i)
+	}
+
+	tx, err := d.readTransaction(i)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TxData{
+		Transaction: tx,
+		BumpIndex:   bumpIndex,
+	}, nil
+}
+
+// readTransaction will read the raw transaction
+//
+// Scripts are copied as they are read (declared lengths are not allocated upfront)
+func (d *Decoder) readTransaction(i int) (*sdk.Transaction, error) {
+	var raw bytes.Buffer
+	r := io.TeeReader(d.r, &raw)
+
+	if err := readRawTransaction(r); err != nil {
+		return nil, fmt.Errorf("insufficient bytes to extract transaction at index %d: %w", i, err)
+	}
+
+	tx, err := sdk.NewTransactionFromBytes(raw.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode transaction at index %d: %w", i, err)
+	}
+	return tx, nil
+}
+
+func (d *Decoder) readBumpIndex(i int) (*sdk.VarInt, error) {
+	bumpIndex, err := d.readVarInt()
+	if err != nil {
+		return nil, fmt.Errorf("insufficient bytes to extract BUMP index for transaction at index %d", i)
+	}
+	return &bumpIndex, nil
+}
+
+func (d *Decoder) readVarInt() (sdk.VarInt, error) {
+	var v sdk.VarInt
+	_, err := v.ReadFrom(d.r)
+	return v, err
+}
+
+// readHash will read a hash (stored in the internal byte order)
+func (d *Decoder) readHash() (chainhash.Hash, error) {
+	var hash chainhash.Hash
+	_, err := io.ReadFull(d.r, hash[:])
+	return hash, err
+}
+
+func (d *Decoder) invalidStreamError() error {
+	if d.format == hexFormat {
+		return errors.New("invalid beef hex stream")
+	}
+	return errors.New("invalid beef stream")
+}
+
+// readRawTransaction will read (and discard) the fields of a raw transaction
+func readRawTransaction(r io.Reader) error {
+	skip := func(n uint64) error {
+		if n > math.MaxInt64 {
+			return fmt.Errorf("invalid length %d", n)
+		}
+		_, err := io.CopyN(io.Discard, r, int64(n))
+		return err
+	}
+	readVarInt := func() (uint64, error) {
+		var v sdk.VarInt
+		_, err := v.ReadFrom(r)
+		return uint64(v), err
+	}
+
+	if err := skip(4); err != nil { // version
+		return err
+	}
+
+	nInputs, err := readVarInt()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nInputs; i++ {
+		if err = skip(36); err != nil { // outpoint
+			return err
+		}
+		length, err := readVarInt()
+		if err != nil {
+			return err
+		}
+		if err = skip(length); err != nil { // unlocking script
+			return err
+		}
+		if err = skip(4); err != nil { // sequence
+			return err
+		}
+	}
+
+	nOutputs, err := readVarInt()
+	if err != nil {
+		return err
+	}
+	for i := uint64(0); i < nOutputs; i++ {
+		if err = skip(8); err != nil { // satoshis
+			return err
+		}
+		length, err := readVarInt()
+		if err != nil {
+			return err
+		}
+		if err = skip(length); err != nil { // locking script
+			return err
+		}
+	}
+
+	return skip(4) // lock time
+}
+
+// limitReader returns ErrLimitExceeded once more than max bytes are read (no limit if zero)
+//
+// The first error of the underlying reader (other than io.EOF) is kept in err
+type limitReader struct {
+	err  error
+	max  int64
+	read int64
+	r    io.Reader
+}
+
+func (l *limitReader) Read(p []byte) (int, error) {
+	if l.err != nil {
+		return 0, l.err
+	}
+
+	n, err := l.r.Read(p)
+	l.read += int64(n)
+	if l.max > 0 && l.read > l.max {
+		n -= int(l.read - l.max)
+		l.read = l.max
+		l.err = fmt.Errorf("%w - more than %d bytes", ErrLimitExceeded, l.max)
+		return n, l.err
+	}
+	if err != nil && !errors.Is(err, io.EOF) {
+		l.err = err
+	}
+	return n, err
+}
+
+// initialCapacity will cap the capacity allocated for a declared (not yet read) number of items
+func initialCapacity(n uint64) uint64 {
+	return min(n, 1024)
+}
+
+func isHexDigit(b byte) bool {
+	return (b >= '0' && b <= '9') || (b >= 'a' && b <= 'f') || (b >= 'A' && b <= 'F')
+}
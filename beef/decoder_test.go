@@ -0,0 +1,158 @@
+package beef
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecoder_Decode_Streams(t *testing.T) {
+	expected, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	beefBytes, err := hex.DecodeString(testBEEFHex)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		stream []byte
+	}{
+		{"hex stream", []byte(testBEEFHex)},
+		{"upper case hex stream", []byte(strings.ToUpper(testBEEFHex))},
+		{"binary stream", beefBytes},
+		{"binary stream followed by other data", append(bytes.Clone(beefBytes), []byte("other data")...)},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// one byte at a time to make sure nothing relies on a full buffer
+			decoded, err := NewDecoder(iotest.OneByteReader(bytes.NewReader(tc.stream)), DefaultLimits).Decode()
+			require.NoError(t, err)
+			assert.Equal(t, expected, decoded)
+		})
+	}
+
+	t.Run("atomic BEEF V2", func(t *testing.T) {
+		atomic := &DecodedBEEF{
+			AtomicTxID:   expected.Transactions[1].GetTxID(),
+			BUMPs:        expected.BUMPs,
+			Transactions: expected.Transactions,
+			Version:      BEEFVersion2,
+		}
+		atomicBytes, err := atomic.Bytes()
+		require.NoError(t, err)
+
+		decoded, err := NewDecoder(bytes.NewReader(atomicBytes), DefaultLimits).Decode()
+		require.NoError(t, err)
+		assert.Equal(t, atomic.AtomicTxID, decoded.AtomicTxID)
+		assert.Equal(t, BEEFVersion2, decoded.Version)
+		assert.Equal(t, atomic.Transactions[1].GetTxID(), decoded.Transactions[1].GetTxID())
+	})
+}
+
+func TestDecoder_Decode_Limits(t *testing.T) {
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	size := int64(len(testBEEFHex) / 2)
+
+	twoBUMPs, err := (&DecodedBEEF{BUMPs: BUMPs{decoded.BUMPs[0], decoded.BUMPs[0]}, Transactions: decoded.Transactions}).Hex()
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name        string
+		beef        string
+		limits      Limits
+		expectLimit bool
+	}{
+		{"exact size", testBEEFHex, Limits{MaxBytes: size}, false},
+		{"too large", testBEEFHex, Limits{MaxBytes: size - 1}, true},
+		{"too large for the header", testBEEFHex, Limits{MaxBytes: 2}, true},
+		{"exact number of transactions", testBEEFHex, Limits{MaxTxs: 2}, false},
+		{"too many transactions", testBEEFHex, Limits{MaxTxs: 1}, true},
+		{"exact number of BUMPs", twoBUMPs, Limits{MaxBumps: 2}, false},
+		{"too many BUMPs", twoBUMPs, Limits{MaxBumps: 1}, true},
+		{"exact BUMP height", testBEEFHex, Limits{MaxTreeHeight: len(decoded.BUMPs[0].Path)}, false},
+		{"too high BUMP", testBEEFHex, Limits{MaxTreeHeight: len(decoded.BUMPs[0].Path) - 1}, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewDecoder(strings.NewReader(tc.beef), tc.limits).Decode()
+			if tc.expectLimit {
+				require.ErrorIs(t, err, ErrLimitExceeded)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDecoder_Decode_HandlingErrors(t *testing.T) {
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	bump, err := decoded.BUMPs[0].appendBytes(nil)
+	require.NoError(t, err)
+
+	// beefWith will return a binary BEEF with one BUMP followed by the bytes
+	beefWith := func(s string) []byte {
+		b, err := hex.DecodeString("0100beef01")
+		require.NoError(t, err)
+		rest, err := hex.DecodeString(s)
+		require.NoError(t, err)
+		return append(append(b, bump...), rest...)
+	}
+
+	testCases := []struct {
+		name          string
+		stream        []byte
+		expectedError string
+	}{
+		{
+			name:          "invalid hex character",
+			stream:        []byte(testBEEFHex[:100] + "zz" + testBEEFHex[102:]),
+			expectedError: "invalid beef hex stream",
+		},
+		{
+			name:          "truncated binary stream",
+			stream:        []byte{0x01, 0x00},
+			expectedError: "invalid beef stream",
+		},
+		{
+			name:          "huge number of transactions",
+			stream:        beefWith("ffffffffffffffffff"),
+			expectedError: "insufficient bytes to extract transaction at index 0",
+		},
+		{
+			name:          "huge unlocking script length",
+			stream:        beefWith("0201000000" + "01" + strings.Repeat("00", 36) + "ffffffffffffffffff"),
+			expectedError: "insufficient bytes to extract transaction at index 0",
+		},
+		{
+			name:          "huge locking script length",
+			stream:        beefWith("0201000000" + "00" + "01" + strings.Repeat("00", 8) + "feffffffff"),
+			expectedError: "insufficient bytes to extract transaction at index 0",
+		},
+		{
+			name:          "BUMP index out of range",
+			stream:        beefWith("02" + decodedTxHex(t, decoded, 0) + "0101"),
+			expectedError: "invalid BUMP index 1 for transaction at index 0",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := NewDecoder(bytes.NewReader(tc.stream), Limits{}).Decode()
+			require.ErrorContains(t, err, tc.expectedError)
+			assert.Nil(t, result)
+		})
+	}
+}
+
+func decodedTxHex(t *testing.T, decoded *DecodedBEEF, i int) string {
+	t.Helper()
+	return hex.EncodeToString(decoded.Transactions[i].Transaction.Bytes())
+}
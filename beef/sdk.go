@@ -0,0 +1,260 @@
+package beef
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+)
+
+// NewBUMPFromMerklePath will convert the go-sdk MerklePath to a BUMP
+func NewBUMPFromMerklePath(mp *sdk.MerklePath) (*BUMP, error) {
+	if mp == nil {
+		return nil, errors.New("cannot convert MerklePath - MerklePath is missing")
+	}
+
+	bump := &BUMP{BlockHeight: uint64(mp.BlockHeight), Path: make([][]BUMPLeaf, len(mp.Path))}
+	for level, elements := range mp.Path {
+		bump.Path[level] = make([]BUMPLeaf, 0, len(elements))
+		for _, element := range elements {
+			leaf := BUMPLeaf{
+				Offset:    element.Offset,
+				Duplicate: element.Duplicate != nil && *element.Duplicate,
+				TxId:      element.Txid != nil && *element.Txid,
+			}
+			if !leaf.Duplicate {
+				if element.Hash == nil {
+					return nil, fmt.Errorf("cannot convert MerklePath - missing hash at offset %d of level %d", element.Offset, level)
+				}
+				leaf.Hash = element.Hash.String()
+			}
+			bump.Path[level] = append(bump.Path[level], leaf)
+		}
+	}
+	return bump, nil
+}
+
+// ToMerklePath will convert the BUMP to a go-sdk MerklePath
+func (b *BUMP) ToMerklePath() (*sdk.MerklePath, error) {
+	if b.BlockHeight > math.MaxUint32 {
+		return nil, fmt.Errorf("cannot convert BUMP - block height %d is too high", b.BlockHeight)
+	}
+
+	mp := &sdk.MerklePath{BlockHeight: uint32(b.BlockHeight), Path: make([][]*sdk.PathElement, len(b.Path))}
+	for level, leaves := range b.Path {
+		mp.Path[level] = make([]*sdk.PathElement, 0, len(leaves))
+		for _, leaf := range leaves {
+			element := &sdk.PathElement{Offset: leaf.Offset}
+			if leaf.Duplicate {
+				element.Duplicate = &leaf.Duplicate
+			} else {
+				hash, err := chainhash.NewHashFromHex(leaf.Hash)
+				if err != nil {
+					return nil, fmt.Errorf("cannot convert BUMP - invalid hash at offset %d of level %d", leaf.Offset, level)
+				}
+				element.Hash = hash
+			}
+			if leaf.TxId {
+				element.Txid = &leaf.TxId
+			}
+			mp.Path[level] = append(mp.Path[level], element)
+		}
+	}
+	return mp, nil
+}
+
+// FromSDK will convert the go-sdk Beef to a DecodedBEEF
+//
+// The go-sdk Beef does not keep the order of the transactions, they are ordered topologically
+// (parents first) and the Beef must have exactly one subject (a transaction not spent in the Beef)
+func FromSDK(b *sdk.Beef) (*DecodedBEEF, error) {
+	return FromSDKWithSubject(b, "")
+}
+
+// FromSDKWithSubject will convert the go-sdk Beef to a DecodedBEEF with the given subject transaction last,
+// use it when the Beef has more than one transaction not spent in the Beef
+func FromSDKWithSubject(b *sdk.Beef, subjectTxID string) (*DecodedBEEF, error) {
+	if b == nil {
+		return nil, errors.New("cannot convert Beef - Beef is missing")
+	}
+
+	decoded := &DecodedBEEF{BUMPs: make(BUMPs, 0, len(b.BUMPs)), Version: b.Version}
+	for _, mp := range b.BUMPs {
+		bump, err := NewBUMPFromMerklePath(mp)
+		if err != nil {
+			return nil, err
+		}
+		decoded.BUMPs = append(decoded.BUMPs, bump)
+	}
+
+	transactions := make([]*TxData, 0, len(b.Transactions))
+	for txID, btx := range b.Transactions {
+		td, err := txDataFromSDK(b, btx)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert transaction %s: %w", txID, err)
+		}
+		transactions = append(transactions, td)
+	}
+
+	ordered, err := orderTransactions(transactions, subjectTxID)
+	if err != nil {
+		return nil, err
+	}
+	decoded.Transactions = ordered
+	return decoded, nil
+}
+
+// ToSDK will convert the DecodedBEEF to a go-sdk Beef
+//
+// The transactions are cloned and linked (source transactions and merkle paths) as in a Beef
+// parsed by the go-sdk. The Atomic BEEF subject is not part of the go-sdk Beef
+func (d *DecodedBEEF) ToSDK() (*sdk.Beef, error) {
+	b := &sdk.Beef{
+		Version:      d.Version,
+		BUMPs:        make([]*sdk.MerklePath, 0, len(d.BUMPs)),
+		Transactions: make(map[string]*sdk.BeefTx, len(d.Transactions)),
+	}
+	if b.Version == 0 {
+		b.Version = BEEFVersion1
+	}
+
+	for _, bump := range d.BUMPs {
+		mp, err := bump.ToMerklePath()
+		if err != nil {
+			return nil, err
+		}
+		b.BUMPs = append(b.BUMPs, mp)
+	}
+
+	for i, td := range d.Transactions {
+		btx, err := d.txDataToSDK(b, td)
+		if err != nil {
+			return nil, fmt.Errorf("cannot convert transaction at index %d: %w", i, err)
+		}
+		b.Transactions[td.GetTxID()] = btx
+	}
+	return b, nil
+}
+
+// txDataToSDK will convert the TxData, parents must already be in the Beef (linked as source transactions)
+func (d *DecodedBEEF) txDataToSDK(b *sdk.Beef, td *TxData) (*sdk.BeefTx, error) {
+	if td.IsTxIDOnly() {
+		txID, err := chainhash.NewHashFromHex(td.GetTxID())
+		if err != nil {
+			return nil, err
+		}
+		return &sdk.BeefTx{DataFormat: sdk.TxIDOnly, KnownTxID: txID, Transaction: &sdk.Transaction{}}, nil
+	}
+
+	btx := &sdk.BeefTx{DataFormat: sdk.RawTx, Transaction: td.Transaction.ShallowClone()}
+	if !td.Unmined() {
+		if uint64(*td.BumpIndex) >= uint64(len(b.BUMPs)) {
+			return nil, fmt.Errorf("BUMP index %d out of range", uint64(*td.BumpIndex))
+		}
+		btx.DataFormat = sdk.RawTxAndBumpIndex
+		btx.BumpIndex = int(*td.BumpIndex)
+		btx.Transaction.MerklePath = b.BUMPs[btx.BumpIndex]
+	}
+
+	for _, input := range btx.Transaction.Inputs {
+		if parent, ok := b.Transactions[input.SourceTXID.String()]; ok && parent.DataFormat != sdk.TxIDOnly {
+			input.SourceTransaction = parent.Transaction
+		}
+	}
+	return btx, nil
+}
+
+// txDataFromSDK will convert the BeefTx (the BUMP index is taken from the merkle path of the transaction if set)
+func txDataFromSDK(b *sdk.Beef, btx *sdk.BeefTx) (*TxData, error) {
+	if btx.DataFormat == sdk.TxIDOnly {
+		if btx.KnownTxID == nil {
+			return nil, errors.New("txid is missing")
+		}
+		return NewTxIDOnly(btx.KnownTxID.String()), nil
+	}
+	if btx.Transaction == nil {
+		return nil, errors.New("transaction is missing")
+	}
+
+	td := &TxData{Transaction: btx.Transaction}
+	bumpIndex := btx.BumpIndex
+	if btx.Transaction.MerklePath != nil {
+		bumpIndex = slices.Index(b.BUMPs, btx.Transaction.MerklePath)
+		if bumpIndex < 0 {
+			return nil, errors.New("merkle path is not in the BUMPs")
+		}
+	} else if btx.DataFormat != sdk.RawTxAndBumpIndex {
+		return td, nil
+	}
+
+	if bumpIndex < 0 || bumpIndex >= len(b.BUMPs) {
+		return nil, fmt.Errorf("BUMP index %d out of range", bumpIndex)
+	}
+	index := sdk.VarInt(bumpIndex)
+	td.BumpIndex = &index
+	return td, nil
+}
+
+// orderTransactions will sort the transactions topologically (ties by txid) with the subject last,
+// the subject is found when subjectTxID is empty
+func orderTransactions(transactions []*TxData, subjectTxID string) ([]*TxData, error) {
+	slices.SortFunc(transactions, func(a, b *TxData) int {
+		return strings.Compare(a.GetTxID(), b.GetTxID())
+	})
+
+	byTxID := make(map[string]*TxData, len(transactions))
+	for _, td := range transactions {
+		byTxID[td.GetTxID()] = td
+	}
+
+	inDegree := make(map[string]int, len(transactions))
+	children := make(map[string][]*TxData, len(transactions))
+	for _, td := range transactions {
+		if td.IsTxIDOnly() {
+			continue
+		}
+		for _, input := range td.Transaction.Inputs {
+			parentTxID := sourceTxID(input)
+			if _, ok := byTxID[parentTxID]; ok && !slices.Contains(children[parentTxID], td) {
+				inDegree[td.GetTxID()]++
+				children[parentTxID] = append(children[parentTxID], td)
+			}
+		}
+	}
+
+	var subject *TxData
+	for _, td := range transactions {
+		if td.IsTxIDOnly() || len(children[td.GetTxID()]) > 0 || (subjectTxID != "" && td.GetTxID() != subjectTxID) {
+			continue
+		} else if subject != nil {
+			return nil, errors.New("cannot convert Beef - more than one subject transaction")
+		}
+		subject = td
+	}
+	if subject == nil {
+		return nil, errors.New("cannot convert Beef - subject transaction not found")
+	}
+
+	ordered := make([]*TxData, 0, len(transactions))
+	for _, td := range transactions {
+		if inDegree[td.GetTxID()] == 0 && td != subject {
+			ordered = append(ordered, td)
+		}
+	}
+	for i := 0; i < len(ordered); i++ {
+		for _, child := range children[ordered[i].GetTxID()] {
+			if inDegree[child.GetTxID()]--; inDegree[child.GetTxID()] == 0 && child != subject {
+				ordered = append(ordered, child)
+			}
+		}
+	}
+
+	if len(ordered) != len(transactions)-1 || inDegree[subject.GetTxID()] != 0 {
+		return nil, errors.New("cannot convert Beef - transactions contain a cycle")
+	}
+	return append(ordered, subject), nil
+}
@@ -0,0 +1,167 @@
+package beef
+
+import (
+	"math/rand"
+	"testing"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBUMP_ToMerklePath(t *testing.T) {
+	bump, err := NewBUMPFromHex(brc74Hex)
+	require.NoError(t, err)
+
+	mp, err := bump.ToMerklePath()
+	require.NoError(t, err)
+	assert.Equal(t, brc74Hex, mp.Hex())
+
+	for _, txID := range bump.TxIDs() {
+		root, err := mp.ComputeRootHex(&txID)
+		require.NoError(t, err)
+		assert.Equal(t, brc74Root, root)
+	}
+
+	sdkPath, err := sdk.NewMerklePathFromHex(brc74Hex)
+	require.NoError(t, err)
+	converted, err := NewBUMPFromMerklePath(sdkPath)
+	require.NoError(t, err)
+	assert.Equal(t, bump, converted)
+
+	t.Run("invalid BUMP", func(t *testing.T) {
+		_, err := (&BUMP{BlockHeight: 1 << 32}).ToMerklePath()
+		require.Error(t, err)
+		_, err = (&BUMP{Path: [][]BUMPLeaf{{{Hash: "zz"}}}}).ToMerklePath()
+		require.Error(t, err)
+		_, err = NewBUMPFromMerklePath(&sdk.MerklePath{Path: [][]*sdk.PathElement{{{Offset: 1}}}})
+		require.Error(t, err)
+	})
+}
+
+func TestDecodedBEEF_ToSDK(t *testing.T) {
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+	parent, subject := decoded.Transactions[0], decoded.Transactions[1]
+
+	t.Run("round trip", func(t *testing.T) {
+		b, err := decoded.ToSDK()
+		require.NoError(t, err)
+		assert.Equal(t, BEEFVersion1, b.Version)
+		require.Len(t, b.Transactions, 2)
+
+		sdkSubject := b.FindTransactionForSigning(subject.GetTxID())
+		require.NotNil(t, sdkSubject)
+		assert.Same(t, b.Transactions[parent.GetTxID()].Transaction, sdkSubject.Inputs[0].SourceTransaction)
+		assert.Same(t, b.BUMPs[0], b.Transactions[parent.GetTxID()].Transaction.MerklePath)
+		assert.Nil(t, parent.Transaction.MerklePath, "the decoded transactions are not modified")
+
+		converted, err := FromSDK(b)
+		require.NoError(t, err)
+		assertSameBEEF(t, decoded, converted)
+	})
+
+	t.Run("BEEF V2 parsed by go-sdk", func(t *testing.T) {
+		v2 := *decoded
+		v2.Version = BEEFVersion2
+		beefBytes, err := v2.Bytes()
+		require.NoError(t, err)
+
+		parsed, err := sdk.NewBeefFromBytes(beefBytes)
+		require.NoError(t, err)
+		converted, err := FromSDK(parsed)
+		require.NoError(t, err)
+		assert.Equal(t, BEEFVersion2, converted.Version)
+		assertSameBEEF(t, decoded, converted)
+	})
+
+	t.Run("txid only transaction", func(t *testing.T) {
+		withTxIDOnly := &DecodedBEEF{BUMPs: decoded.BUMPs, Transactions: []*TxData{NewTxIDOnly(parent.GetTxID()), subject}, Version: BEEFVersion2}
+		b, err := withTxIDOnly.ToSDK()
+		require.NoError(t, err)
+		assert.Equal(t, sdk.TxIDOnly, b.Transactions[parent.GetTxID()].DataFormat)
+
+		converted, err := FromSDK(b)
+		require.NoError(t, err)
+		assertSameBEEF(t, withTxIDOnly, converted)
+	})
+
+	t.Run("random graphs", func(t *testing.T) {
+		r := rand.New(rand.NewSource(14))
+		for i := 0; i < 100; i++ {
+			subject, ancestors := randomTxGraph(r)
+			builder := NewBuilder(subject)
+			for _, ancestor := range ancestors {
+				if ancestor.bump != nil {
+					ancestor.bump.BlockHeight %= 1 << 32 // go-sdk block heights are uint32
+				}
+				builder.AddAncestor(ancestor.tx, ancestor.bump)
+			}
+			built, err := builder.Build()
+			require.NoError(t, err)
+
+			b, err := built.ToSDK()
+			require.NoError(t, err)
+			converted, err := FromSDKWithSubject(b, subject.TxID().String())
+			require.NoError(t, err)
+
+			assert.Equal(t, built.BUMPs, converted.BUMPs)
+			assertTopologicalOrder(t, converted)
+			require.Len(t, converted.Transactions, len(built.Transactions))
+			assert.Equal(t, subject.TxID().String(), converted.Transactions[len(converted.Transactions)-1].GetTxID())
+			for _, td := range converted.Transactions {
+				if !td.Unmined() {
+					assert.True(t, converted.BUMPs[*td.BumpIndex].containsTxID(td.GetTxID()))
+				}
+			}
+		}
+	})
+}
+
+func TestFromSDK_HandlingErrors(t *testing.T) {
+	decoded, err := DecodeBEEF(testBEEFHex)
+	require.NoError(t, err)
+
+	_, err = FromSDK(nil)
+	require.Error(t, err)
+
+	t.Run("more than one subject", func(t *testing.T) {
+		b, err := decoded.ToSDK()
+		require.NoError(t, err)
+		other := sdk.NewTransaction()
+		other.AddInputFromTx(decoded.Transactions[0].Transaction, 0, nil)
+		b.Transactions[other.TxID().String()] = &sdk.BeefTx{Transaction: other}
+
+		_, err = FromSDK(b)
+		require.ErrorContains(t, err, "more than one subject")
+
+		converted, err := FromSDKWithSubject(b, other.TxID().String())
+		require.NoError(t, err)
+		assert.Equal(t, other.TxID().String(), converted.Transactions[2].GetTxID())
+
+		_, err = FromSDKWithSubject(b, decoded.Transactions[0].GetTxID())
+		require.ErrorContains(t, err, "subject transaction not found")
+	})
+
+	t.Run("BUMP index out of range", func(t *testing.T) {
+		b, err := decoded.ToSDK()
+		require.NoError(t, err)
+		parent := b.Transactions[decoded.Transactions[0].GetTxID()]
+		parent.Transaction.MerklePath, parent.BumpIndex = nil, 1
+
+		_, err = FromSDK(b)
+		require.ErrorContains(t, err, "out of range")
+	})
+}
+
+// assertSameBEEF will check that the BEEFs have the same BUMPs and transactions (in the same order)
+func assertSameBEEF(t *testing.T, expected, actual *DecodedBEEF) {
+	t.Helper()
+	assert.Equal(t, expected.BUMPs, actual.BUMPs)
+	require.Len(t, actual.Transactions, len(expected.Transactions))
+	for i, td := range expected.Transactions {
+		assert.Equal(t, td.GetTxID(), actual.Transactions[i].GetTxID())
+		assert.Equal(t, td.BumpIndex, actual.Transactions[i].BumpIndex)
+		assert.Equal(t, td.IsTxIDOnly(), actual.Transactions[i].IsTxIDOnly())
+	}
+}
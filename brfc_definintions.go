@@ -21,6 +21,9 @@ const (
 	BRFCPike                           = "8c4ed5ef8ace"
 	BRFCPikeInvite                     = "invite"
 	BRFCPikeOutputs                    = "outputs"
+	BRFCPikeAccept                     = "accept"
+	BRFCPikeReject                     = "reject"
+	BRFCPikeStatus                     = "status"
 )
 
 // BRFCKnownSpecifications is a running list of all known BRFC specifications
@@ -1,6 +1,8 @@
 package paymail
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,8 +37,11 @@ type CapabilitiesPayload struct {
 
 // PikeCapability represents the structure of the PIKE capability
 type PikeCapability struct {
+	Accept  *string `json:"accept,omitempty"`
 	Invite  *string `json:"invite,omitempty"`
 	Outputs *string `json:"outputs,omitempty"`
+	Reject  *string `json:"reject,omitempty"`
+	Status  *string `json:"status,omitempty"`
 }
 
 // PikeOutputs represents the structure of the PIKE outputs
@@ -92,6 +97,13 @@ func (c *CapabilitiesPayload) GetBool(brfcID, alternateID string) bool {
 //
 // Specs: http://bsvalias.org/02-02-capability-discovery.html
 func (c *Client) GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error) {
+	return c.GetCapabilitiesCtx(context.Background(), target, port)
+}
+
+// GetCapabilitiesCtx is the context-aware version of GetCapabilities()
+//
+// With the discovery cache, the responses served from the cache carry no tracing information
+func (c *Client) GetCapabilitiesCtx(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error) {
 
 	// Basic requirements for the request
 	if len(target) == 0 {
@@ -106,9 +118,119 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 	// https://<host-discovery-target>:<host-discovery-port>/.well-known/bsvalias[network]
 	reqURL := fmt.Sprintf("https://%s:%d/.well-known/%s%s", target, port, DefaultServiceName, c.options.network.URLSuffix())
 
+	// No cache, always fetch the capabilities
+	if c.options.discoveryCache == nil {
+		return c.fetchCapabilities(ctx, reqURL)
+	}
+
+	// Use the discovery cache (honouring the Cache-Control of the response)
+	var (
+		entry   *DiscoveryCacheEntry
+		fetched bool
+		latest  *CapabilitiesResponse // Only read if fetched (the background refresh also sets it)
+	)
+	if entry, fetched, err = c.discover(ctx, discoveryKeyCapabilities+reqURL, func(ctx context.Context) (*DiscoveryCacheEntry, error) {
+		response, fetchErr := c.fetchCapabilities(ctx, reqURL)
+		if fetchErr != nil {
+			if isUnknownDomainError(fetchErr) {
+				return c.newNegativeDiscoveryEntry(reqURL, fetchErr), nil
+			}
+			return nil, fetchErr
+		}
+		latest = response
+		return c.newCapabilitiesEntry(response), nil
+	}); err != nil {
+		return
+	} else if fetched {
+		return latest, nil
+	}
+
+	// Cached response (without tracing)
+	return &CapabilitiesResponse{
+		StandardResponse: StandardResponse{
+			Body:       bytes.Clone(entry.Body),
+			Header:     entry.Header.Clone(),
+			StatusCode: entry.StatusCode,
+		},
+		CapabilitiesPayload: *entry.Capabilities.clone(),
+	}, nil
+}
+
+// newCapabilitiesEntry will return the discovery cache entry for the capabilities (using the Cache-Control)
+func (c *Client) newCapabilitiesEntry(response *CapabilitiesResponse) *DiscoveryCacheEntry {
+	ttl, staleTTL := c.options.discoveryTTL, c.options.discoveryStaleTTL
+	if cc := parseCacheControl(response.Header.Get("Cache-Control")); cc.noStore {
+		ttl, staleTTL = 0, 0
+	} else {
+		if cc.hasMaxAge {
+			ttl = cc.maxAge
+		}
+		if cc.hasStale {
+			staleTTL = cc.staleWhileRevalidate
+		}
+	}
+
+	entry := newDiscoveryEntry(ttl, staleTTL)
+	entry.Body = bytes.Clone(response.Body)
+	entry.Capabilities = response.CapabilitiesPayload.clone()
+	entry.Header = response.Header.Clone()
+	entry.StatusCode = response.StatusCode
+	return entry
+}
+
+// clone will return a deep copy of the payload (the cached payload is never shared with the callers)
+func (c *CapabilitiesPayload) clone() *CapabilitiesPayload {
+	clone := &CapabilitiesPayload{BsvAlias: c.BsvAlias}
+	if c.Capabilities != nil {
+		clone.Capabilities = cloneCapabilityValue(c.Capabilities).(map[string]interface{})
+	}
+	if c.Pike != nil {
+		clone.Pike = &PikeCapability{
+			Accept:  cloneString(c.Pike.Accept),
+			Invite:  cloneString(c.Pike.Invite),
+			Outputs: cloneString(c.Pike.Outputs),
+			Reject:  cloneString(c.Pike.Reject),
+			Status:  cloneString(c.Pike.Status),
+		}
+	}
+	return clone
+}
+
+// cloneCapabilityValue will return a deep copy of the decoded JSON value (maps and slices are copied)
+func cloneCapabilityValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		clone := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			clone[key] = cloneCapabilityValue(val)
+		}
+		return clone
+	case []interface{}:
+		clone := make([]interface{}, len(v))
+		for i, val := range v {
+			clone[i] = cloneCapabilityValue(val)
+		}
+		return clone
+	default:
+		return v
+	}
+}
+
+// cloneString will return a copy of the string pointer
+func cloneString(value *string) *string {
+	if value == nil {
+		return nil
+	}
+	clone := *value
+	return &clone
+}
+
+// fetchCapabilities will fetch and parse the capabilities from the url
+func (c *Client) fetchCapabilities(ctx context.Context, reqURL string) (response *CapabilitiesResponse, err error) {
+
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return
 	}
 
@@ -117,11 +239,9 @@ func (c *Client) GetCapabilities(target string, port int) (response *Capabilitie
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		providerErr := newProviderError(reqURL, &resp)
+		providerErr.Discovery = true
+		err = providerErr
 		return
 	}
 
@@ -176,6 +296,30 @@ func (c *CapabilitiesPayload) ExtractPikeInviteURL() string {
 	return ""
 }
 
+// ExtractPikeAcceptURL extracts the accept URL from the PIKE capability
+func (c *CapabilitiesPayload) ExtractPikeAcceptURL() string {
+	if c.Pike != nil && c.Pike.Accept != nil {
+		return *c.Pike.Accept
+	}
+	return ""
+}
+
+// ExtractPikeRejectURL extracts the reject URL from the PIKE capability
+func (c *CapabilitiesPayload) ExtractPikeRejectURL() string {
+	if c.Pike != nil && c.Pike.Reject != nil {
+		return *c.Pike.Reject
+	}
+	return ""
+}
+
+// ExtractPikeStatusURL extracts the contact status URL from the PIKE capability
+func (c *CapabilitiesPayload) ExtractPikeStatusURL() string {
+	if c.Pike != nil && c.Pike.Status != nil {
+		return *c.Pike.Status
+	}
+	return ""
+}
+
 // parsePikeCapability parses the PIKE capability from the capabilities response
 func parsePikeCapability(response *CapabilitiesResponse) error {
 	if pike, ok := response.Capabilities[BRFCPike].(map[string]interface{}); ok {
@@ -189,6 +333,18 @@ func parsePikeCapability(response *CapabilitiesResponse) error {
 		if outputsStr, ok := pike["outputs"].(string); ok {
 			response.Pike.Outputs = &outputsStr
 		}
+
+		if acceptStr, ok := pike[BRFCPikeAccept].(string); ok {
+			response.Pike.Accept = &acceptStr
+		}
+
+		if rejectStr, ok := pike[BRFCPikeReject].(string); ok {
+			response.Pike.Reject = &rejectStr
+		}
+
+		if statusStr, ok := pike[BRFCPikeStatus].(string); ok {
+			response.Pike.Status = &statusStr
+		}
 	}
 	return nil
 }
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
@@ -245,6 +246,46 @@ func TestClient_GetCapabilities(t *testing.T) {
 		require.NotNil(t, response.Pike)
 		require.Equal(t, "https://examples.com/v1/bsvalias/pike/outputs/{alias}@{domain.tld}", *response.Pike.Outputs)
 		require.Equal(t, "https://examples.com/v1/bsvalias/contact/invite/{alias}@{domain.tld}", *response.Pike.Invite)
+
+		// Check PIKE contact lifecycle capabilities
+		require.Equal(t, "https://examples.com/v1/bsvalias/contact/accept/{alias}@{domain.tld}", response.ExtractPikeAcceptURL())
+		require.Equal(t, "https://examples.com/v1/bsvalias/contact/reject/{alias}@{domain.tld}", response.ExtractPikeRejectURL())
+		require.Equal(t, "https://examples.com/v1/bsvalias/contact/status/{alias}@{domain.tld}", response.ExtractPikeStatusURL())
+	})
+}
+
+// TestClient_GetCapabilitiesCtx will test the method GetCapabilitiesCtx()
+func TestClient_GetCapabilitiesCtx(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("successful response", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockCapabilities(http.StatusOK)
+
+		response, err := client.GetCapabilitiesCtx(context.Background(), testDomain, DefaultPort)
+		require.NoError(t, err)
+		require.NotNil(t, response)
+		require.Equal(t, DefaultBsvAliasVersion, response.BsvAlias)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		client := newTestClient(t)
+
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, "https://"+testDomain+":443/.well-known/"+DefaultServiceName,
+			func(req *http.Request) (*http.Response, error) {
+				return nil, req.Context().Err()
+			},
+		)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		response, err := client.GetCapabilitiesCtx(ctx, testDomain, DefaultPort)
+		require.ErrorIs(t, err, context.Canceled)
+		require.Nil(t, response)
 	})
 }
 
@@ -279,8 +320,11 @@ func mockCapabilitiesWithPIKE(statusCode int) {
 					"pki": "https://examples.com/{alias}@{domain.tld}/id",
 					"paymentDestination": "https://examples.com/{alias}@{domain.tld}/payment-destination",
 					"8c4ed5ef8ace": {
+						"accept": "https://examples.com/v1/bsvalias/contact/accept/{alias}@{domain.tld}",
 						"invite": "https://examples.com/v1/bsvalias/contact/invite/{alias}@{domain.tld}",
-						"outputs": "https://examples.com/v1/bsvalias/pike/outputs/{alias}@{domain.tld}"
+						"outputs": "https://examples.com/v1/bsvalias/pike/outputs/{alias}@{domain.tld}",
+						"reject": "https://examples.com/v1/bsvalias/contact/reject/{alias}@{domain.tld}",
+						"status": "https://examples.com/v1/bsvalias/contact/status/{alias}@{domain.tld}"
 					}
 				}
 			}`,
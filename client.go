@@ -1,34 +1,46 @@
 package paymail
 
 import (
+	"context"
+	"sync"
 	"time"
 
 	"github.com/bitcoin-sv/go-paymail/interfaces"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
 	"github.com/go-resty/resty/v2"
 )
 
 type (
 	// Client is the Paymail client configuration and options
 	Client struct {
-		httpClient *resty.Client          // HTTP client for GET/POST requests
-		options    *ClientOptions         // Options are all the default settings / configuration
-		resolver   interfaces.DNSResolver // Resolver for DNS look ups
+		background   sync.WaitGroup         // Background refreshes of the discovery cache
+		backgroundMu sync.Mutex             // Guards closed and the additions to the background refreshes
+		closed       bool                   // Client is closed (no new background refreshes)
+		httpClient   *resty.Client          // HTTP client for GET/POST requests
+		options      *ClientOptions         // Options are all the default settings / configuration
+		resolver     interfaces.DNSResolver // Resolver for DNS look ups
+		revalidating sync.Map               // Discovery cache keys being refreshed in the background
 	}
 
 	// ClientOptions holds all the configuration for client requests and default resources
 	ClientOptions struct {
-		brfcSpecs         []*BRFCSpec   // List of BRFC specifications
-		dnsPort           string        // Default DNS port for SRV checks
-		dnsTimeout        time.Duration // Default timeout in seconds for DNS fetching
-		httpTimeout       time.Duration // Default timeout in seconds for GET requests
-		nameServer        string        // Default name server for DNS checks
-		nameServerNetwork string        // Default name server network
-		requestTracing    bool          // If enabled, it will trace the request timing
-		retryCount        int           // Default retry count for HTTP requests
-		sslDeadline       time.Duration // Default timeout in seconds for SSL deadline
-		sslTimeout        time.Duration // Default timeout in seconds for SSL timeout
-		userAgent         string        // User agent for all outgoing requests
-		network           Network       // The bitcoin network to operate on
+		brfcSpecs            []*BRFCSpec    // List of BRFC specifications
+		discoveryCache       DiscoveryCache // Cache for SRV records and capabilities (disabled if nil)
+		discoveryNegativeTTL time.Duration  // How long unknown domains are cached
+		discoveryStaleTTL    time.Duration  // How long stale entries are served while revalidating
+		discoveryTTL         time.Duration  // Default TTL when the SRV TTL or Cache-Control is unknown
+		dnsPort              string         // Default DNS port for SRV checks
+		dnsTimeout           time.Duration  // Default timeout in seconds for DNS fetching
+		httpTimeout          time.Duration  // Default timeout in seconds for GET requests
+		nameServer           string         // Default name server for DNS checks
+		nameServerNetwork    string         // Default name server network
+		pikeSigningKey       *ec.PrivateKey // PKI key signing the PIKE contact requests and answers (not signed if nil)
+		requestTracing       bool           // If enabled, it will trace the request timing
+		retryCount           int            // Default retry count for HTTP requests
+		sslDeadline          time.Duration  // Default timeout in seconds for SSL deadline
+		sslTimeout           time.Duration  // Default timeout in seconds for SSL timeout
+		userAgent            string         // User agent for all outgoing requests
+		network              Network        // The bitcoin network to operate on
 	}
 )
 
@@ -63,8 +75,7 @@ func NewClient(opts ...ClientOps) (ClientInterface, error) {
 
 	// Set the resolver
 	if client.resolver == nil {
-		r := client.defaultResolver()
-		client.resolver = &r
+		client.resolver = &dnsResolver{Resolver: client.defaultResolver(), options: client.options}
 	}
 
 	// Set the Resty HTTP client
@@ -78,6 +89,19 @@ func NewClient(opts ...ClientOps) (ClientInterface, error) {
 	return client, nil
 }
 
+// Close will wait for the background refreshes of the discovery cache and close the idle connections
+//
+// The client can still be used after Close (new connections are opened when needed),
+// stale entries of the discovery cache are then refreshed before they are returned
+func (c *Client) Close() {
+	c.backgroundMu.Lock()
+	c.closed = true
+	c.backgroundMu.Unlock()
+
+	c.background.Wait()
+	c.httpClient.GetClient().CloseIdleConnections()
+}
+
 // GetBRFCs will return the list of specs
 func (c *Client) GetBRFCs() []*BRFCSpec {
 	return c.options.brfcSpecs
@@ -94,15 +118,17 @@ func (c *Client) GetUserAgent() string {
 }
 
 // GetResolver will return the internal resolver from the client
+//
+// The default resolver is not a *net.Resolver (it also returns the TTL of the SRV records)
 func (c *Client) GetResolver() interfaces.DNSResolver {
 	return c.resolver
 }
 
 // getRequest is a standard GET request for all outgoing HTTP requests
-func (c *Client) getRequest(requestURL string) (response StandardResponse, err error) {
+func (c *Client) getRequest(ctx context.Context, requestURL string) (response StandardResponse, err error) {
 
 	// Set the user agent
-	req := c.httpClient.R().SetHeader("User-Agent", c.options.userAgent)
+	req := c.httpClient.R().SetContext(ctx).SetHeader("User-Agent", c.options.userAgent)
 
 	// Enable tracing
 	if c.options.requestTracing {
@@ -120,8 +146,9 @@ func (c *Client) getRequest(requestURL string) (response StandardResponse, err e
 		response.Tracing = resp.Request.TraceInfo()
 	}
 
-	// Set the status code
+	// Set the status code and headers
 	response.StatusCode = resp.StatusCode()
+	response.Header = resp.Header()
 
 	// Set the body
 	response.Body = resp.Body()
@@ -129,10 +156,10 @@ func (c *Client) getRequest(requestURL string) (response StandardResponse, err e
 }
 
 // postRequest is a standard POST request for all outgoing HTTP requests
-func (c *Client) postRequest(requestURL string, data interface{}) (response StandardResponse, err error) {
+func (c *Client) postRequest(ctx context.Context, requestURL string, data interface{}) (response StandardResponse, err error) {
 
 	// Set the user agent
-	req := c.httpClient.R().SetBody(data).SetHeader("User-Agent", c.options.userAgent)
+	req := c.httpClient.R().SetContext(ctx).SetBody(data).SetHeader("User-Agent", c.options.userAgent)
 
 	// Enable tracing
 	if c.options.requestTracing {
@@ -150,8 +177,9 @@ func (c *Client) postRequest(requestURL string, data interface{}) (response Stan
 		response.Tracing = resp.Request.TraceInfo()
 	}
 
-	// Set the status code
+	// Set the status code and headers
 	response.StatusCode = resp.StatusCode()
+	response.Header = resp.Header()
 
 	// Set the body
 	response.Body = resp.Body()
@@ -0,0 +1,90 @@
+package paymail
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors returned by the client (use errors.Is)
+var (
+	// ErrPaymailNotFound is when the paymail address is not found by its provider
+	ErrPaymailNotFound = errors.New("paymail address not found")
+
+	// ErrCapabilitiesNotFound is when the capability discovery of the domain is not found (or the domain does not exist)
+	ErrCapabilitiesNotFound = errors.New("paymail capabilities not found")
+
+	// ErrCapabilityMissing is when the provider does not support a required capability
+	ErrCapabilityMissing = errors.New("capability is missing")
+
+	// ErrInvalidURL is when the (capability) url is empty or not https
+	ErrInvalidURL = errors.New("invalid url")
+)
+
+// codePaymailNotFound is the code returned by go-paymail servers when the paymail is not found
+const codePaymailNotFound = "error-paymail-not-found"
+
+// ProviderError is a bad response from a paymail provider (use errors.As)
+//
+// The Code and Message are taken from the response body (ServerError), a go-paymail server
+// returns the codes of its errors (e.g. "error-spv-failed"), so errors.Is(err, errors.ErrSPVFailed) works
+type ProviderError struct {
+	Code       string `json:"code"`                // Error code returned by the provider (if any)
+	Discovery  bool   `json:"discovery,omitempty"` // Set on the errors of the capability discovery
+	Message    string `json:"message"`             // Error message (or body) returned by the provider
+	Paymail    string `json:"paymail,omitempty"`   // Paymail address of the lookups (PKI, address resolution, P2P destination, PIKE...)
+	StatusCode int    `json:"status_code"`         // HTTP status code of the response
+	URL        string `json:"url"`                 // URL of the request
+}
+
+// Error returns the error message string for ProviderError, satisfying the error interface
+func (e *ProviderError) Error() string {
+	if len(e.Message) == 0 {
+		return fmt.Sprintf("bad response from paymail provider: code %d", e.StatusCode)
+	}
+	return fmt.Sprintf("bad response from paymail provider: code %d, message: %s", e.StatusCode, e.Message)
+}
+
+// Is will return true if the target has the same error code (e.g. an errors.SPVError), or on a 404 response:
+// ErrPaymailNotFound for the lookups of a paymail (unless the provider returned another code),
+// ErrCapabilitiesNotFound for the capability discovery
+func (e *ProviderError) Is(target error) bool {
+	switch target {
+	case ErrPaymailNotFound:
+		return e.Code == codePaymailNotFound ||
+			(e.StatusCode == http.StatusNotFound && len(e.Paymail) > 0 && len(e.Code) == 0)
+	case ErrCapabilitiesNotFound:
+		return e.Discovery && e.StatusCode == http.StatusNotFound
+	}
+	if coded, ok := target.(interface{ GetCode() string }); ok {
+		return len(e.Code) > 0 && e.Code == coded.GetCode()
+	}
+	return false
+}
+
+// newProviderError will create the ProviderError from the response (and its ServerError body)
+func newProviderError(requestURL string, response *StandardResponse) *ProviderError {
+	providerErr := &ProviderError{StatusCode: response.StatusCode, URL: requestURL}
+
+	serverError := &ServerError{}
+	_ = json.Unmarshal(response.Body, serverError)
+	providerErr.Code = serverError.Code
+	if providerErr.Message = serverError.Message; len(providerErr.Message) == 0 {
+		providerErr.Message = strings.TrimSpace(string(response.Body))
+	}
+	return providerErr
+}
+
+// newPaymailProviderError will create the ProviderError of a lookup of the paymail address
+func newPaymailProviderError(requestURL, alias, domain string, response *StandardResponse) *ProviderError {
+	providerErr := newProviderError(requestURL, response)
+	providerErr.Paymail = alias + "@" + domain
+	return providerErr
+}
+
+// invalidURLError will return the ErrInvalidURL for the url
+func invalidURLError(url string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidURL, url)
+}
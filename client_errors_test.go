@@ -0,0 +1,158 @@
+package paymail
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	spverrors "github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// TestProviderError will test the ProviderError type
+func TestProviderError(t *testing.T) {
+	t.Parallel()
+
+	t.Run("error message", func(t *testing.T) {
+		err := &ProviderError{StatusCode: http.StatusBadRequest, Message: "invalid request"}
+		assert.Equal(t, "bad response from paymail provider: code 400, message: invalid request", err.Error())
+
+		err = &ProviderError{StatusCode: http.StatusBadGateway}
+		assert.Equal(t, "bad response from paymail provider: code 502", err.Error())
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		assert.ErrorIs(t, &ProviderError{Paymail: "alias@domain.tld", StatusCode: http.StatusNotFound}, ErrPaymailNotFound)
+		assert.ErrorIs(t, &ProviderError{StatusCode: http.StatusBadRequest, Code: "error-paymail-not-found"}, ErrPaymailNotFound)
+		assert.NotErrorIs(t, &ProviderError{Paymail: "alias@domain.tld", StatusCode: http.StatusBadRequest}, ErrPaymailNotFound)
+		assert.NotErrorIs(t, &ProviderError{Paymail: "alias@domain.tld", StatusCode: http.StatusNotFound, Code: "error-contact-not-found"},
+			ErrPaymailNotFound, "the provider returned another reason")
+		assert.NotErrorIs(t, &ProviderError{StatusCode: http.StatusNotFound}, ErrPaymailNotFound, "not a lookup of a paymail")
+		assert.NotErrorIs(t, &ProviderError{Discovery: true, StatusCode: http.StatusNotFound}, ErrPaymailNotFound)
+	})
+
+	t.Run("capabilities not found", func(t *testing.T) {
+		assert.ErrorIs(t, &ProviderError{Discovery: true, StatusCode: http.StatusNotFound}, ErrCapabilitiesNotFound)
+		assert.NotErrorIs(t, &ProviderError{Discovery: true, StatusCode: http.StatusBadGateway}, ErrCapabilitiesNotFound)
+		assert.NotErrorIs(t, &ProviderError{Paymail: "alias@domain.tld", StatusCode: http.StatusNotFound}, ErrCapabilitiesNotFound)
+	})
+
+	t.Run("server error codes", func(t *testing.T) {
+		err := &ProviderError{StatusCode: http.StatusExpectationFailed, Code: spverrors.ErrSPVFailed.Code}
+		assert.ErrorIs(t, err, spverrors.ErrSPVFailed)
+		assert.NotErrorIs(t, err, spverrors.ErrInvalidSignature)
+		assert.NotErrorIs(t, &ProviderError{StatusCode: http.StatusBadRequest}, spverrors.ErrInvalidSignature)
+	})
+
+	t.Run("response body", func(t *testing.T) {
+		err := newProviderError("https://test.com", &StandardResponse{
+			StatusCode: http.StatusBadRequest,
+			Body:       []byte(`{"code":"error-signature-invalid","message":"invalid signature"}`),
+		})
+		assert.Equal(t, "error-signature-invalid", err.Code)
+		assert.Equal(t, "invalid signature", err.Message)
+		assert.Equal(t, "https://test.com", err.URL)
+
+		err = newProviderError("https://test.com", &StandardResponse{
+			StatusCode: http.StatusInternalServerError,
+			Body:       []byte("internal server error\n"),
+		})
+		assert.Empty(t, err.Code)
+		assert.Equal(t, "internal server error", err.Message)
+	})
+}
+
+// TestClient_TypedErrors will test the errors returned by the client methods
+func TestClient_TypedErrors(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	reqURL := testServerURL + "receive-transaction/" + testAlias + "@" + testDomain
+	transaction := &P2PTransaction{
+		Hex:       "01000000",
+		MetaData:  &P2PMetaData{Note: testMessage, Sender: testAlias + "@" + testDomain},
+		Reference: "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7",
+	}
+
+	t.Run("server error code round-trips", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, reqURL,
+			httpmock.NewStringResponder(
+				spverrors.ErrSPVFailed.StatusCode,
+				`{"code":"`+spverrors.ErrSPVFailed.Code+`","message":"`+spverrors.ErrSPVFailed.Message+`"}`,
+			),
+		)
+
+		_, err := client.SendP2PTransaction(testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, transaction)
+		require.ErrorIs(t, err, spverrors.ErrSPVFailed)
+
+		var providerErr *ProviderError
+		require.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, spverrors.ErrSPVFailed.StatusCode, providerErr.StatusCode)
+		assert.Equal(t, spverrors.ErrSPVFailed.Message, providerErr.Message)
+		assert.Equal(t, reqURL, providerErr.URL)
+	})
+
+	t.Run("paymail not found", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, reqURL,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"code":"error-paymail-not-found","message":"paymail not found"}`),
+		)
+
+		_, err := client.SendP2PTransaction(testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, transaction)
+		require.ErrorIs(t, err, ErrPaymailNotFound)
+		require.ErrorIs(t, err, spverrors.ErrCouldNotFindPaymail)
+	})
+
+	t.Run("404 of a paymail lookup", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testServerURL+"id/"+testAlias+"@"+testDomain,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"message": "not found"}`),
+		)
+
+		_, err := client.GetPKI(testServerURL+"id/{alias}@{domain.tld}", testAlias, testDomain)
+		require.ErrorIs(t, err, ErrPaymailNotFound)
+
+		var providerErr *ProviderError
+		require.True(t, errors.As(err, &providerErr))
+		assert.Equal(t, testAlias+"@"+testDomain, providerErr.Paymail)
+	})
+
+	t.Run("404 of a transaction is not a missing paymail", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, reqURL,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"message": "not found"}`),
+		)
+
+		_, err := client.SendP2PTransaction(testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, transaction)
+		require.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPaymailNotFound)
+		assert.NotErrorIs(t, err, ErrCapabilitiesNotFound)
+	})
+
+	t.Run("404 of the capability discovery", func(t *testing.T) {
+		client := newTestClient(t)
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testCapabilitiesURL,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"message": "not found"}`),
+		)
+
+		_, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.ErrorIs(t, err, ErrCapabilitiesNotFound)
+		assert.NotErrorIs(t, err, ErrPaymailNotFound)
+	})
+
+	t.Run("invalid url", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.SendP2PTransaction("invalid-url", testAlias, testDomain, transaction)
+		require.ErrorIs(t, err, ErrInvalidURL)
+		assert.Equal(t, "invalid url: invalid-url", err.Error())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"github.com/bitcoin-sv/go-paymail/interfaces"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -17,17 +18,20 @@ type ClientOps func(c *ClientOptions)
 func defaultClientOptions() (opts *ClientOptions, err error) {
 	// Set the default options
 	opts = &ClientOptions{
-		dnsPort:           defaultDNSPort,
-		dnsTimeout:        defaultDNSTimeout,
-		httpTimeout:       defaultHTTPTimeout,
-		nameServer:        defaultNameServer,
-		nameServerNetwork: defaultNameServerNetwork,
-		requestTracing:    false,
-		retryCount:        defaultRetryCount,
-		sslDeadline:       defaultSSLDeadline,
-		sslTimeout:        defaultSSLTimeout,
-		userAgent:         defaultUserAgent,
-		network:           Network(defaultNetwork),
+		discoveryNegativeTTL: defaultDiscoveryNegativeTTL,
+		discoveryStaleTTL:    defaultDiscoveryStaleTTL,
+		discoveryTTL:         defaultDiscoveryTTL,
+		dnsPort:              defaultDNSPort,
+		dnsTimeout:           defaultDNSTimeout,
+		httpTimeout:          defaultHTTPTimeout,
+		nameServer:           defaultNameServer,
+		nameServerNetwork:    defaultNameServerNetwork,
+		requestTracing:       false,
+		retryCount:           defaultRetryCount,
+		sslDeadline:          defaultSSLDeadline,
+		sslTimeout:           defaultSSLTimeout,
+		userAgent:            defaultUserAgent,
+		network:              Network(defaultNetwork),
 	}
 
 	// Load the default BRFC specs
@@ -131,6 +135,39 @@ func WithNetwork(n Network) ClientOps {
 	}
 }
 
+// WithPikeSigningKey will sign the PIKE contact requests and answers with the PKI key of the client paymail.
+// AddContactRequest, AddInviteRequest, AcceptContactRequest, RejectContactRequest and GetContactStatus
+// sign the payloads which are not signed yet.
+// The payloads are not signed by default (see AddSignedContactRequest).
+func WithPikeSigningKey(privateKey *ec.PrivateKey) ClientOps {
+	return func(c *ClientOptions) {
+		c.pikeSigningKey = privateKey
+	}
+}
+
+// WithDiscoveryCache will cache the SRV records and capabilities of paymail providers.
+// The SRV TTL and the Cache-Control of the capabilities are honoured.
+// Use NewMemoryDiscoveryCache() for an in-memory LRU cache.
+// The cache is disabled by default.
+func WithDiscoveryCache(cache DiscoveryCache) ClientOps {
+	return func(c *ClientOptions) {
+		c.discoveryCache = cache
+	}
+}
+
+// WithDiscoveryCacheTTL will overwrite the default TTLs of the discovery cache:
+// ttl is used when the SRV TTL or Cache-Control max-age is unknown,
+// staleTTL is how long stale entries are served while revalidating in the background,
+// negativeTTL is how long unknown domains are cached.
+// Defaults are 5 minutes, 1 minute and 1 minute.
+func WithDiscoveryCacheTTL(ttl, staleTTL, negativeTTL time.Duration) ClientOps {
+	return func(c *ClientOptions) {
+		c.discoveryTTL = ttl
+		c.discoveryStaleTTL = staleTTL
+		c.discoveryNegativeTTL = negativeTTL
+	}
+}
+
 // WithCustomResolver will allow you to supply a custom  dns resolver,
 // useful for testing etc.
 func (c *Client) WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface {
@@ -7,7 +7,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/bitcoin-sv/go-paymail/tester"
+	"github.com/bitcoin-sv/go-paymail/internal/mocks"
 	"github.com/go-resty/resty/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -17,7 +17,7 @@ import (
 func newTestClient(t *testing.T, opts ...ClientOps) ClientInterface {
 
 	// Create a Resty Client
-	httpClient := tester.MockResty()
+	httpClient := mocks.MockResty()
 	if t != nil {
 		require.NotNil(t, httpClient)
 	}
@@ -32,7 +32,7 @@ func newTestClient(t *testing.T, opts ...ClientOps) ClientInterface {
 	_ = client.WithCustomHTTPClient(httpClient)
 
 	// Set the customer resolver with known defaults
-	r := tester.NewCustomResolver(
+	r := mocks.NewCustomResolver(
 		client.GetResolver(),
 		map[string][]string{
 			testDomain:      {"44.225.125.175", "35.165.117.200", "54.190.182.236"},
@@ -141,7 +141,7 @@ func TestNewClient(t *testing.T) {
 	})
 
 	t.Run("custom resolver", func(t *testing.T) {
-		r := tester.NewCustomResolver(nil, nil, nil, nil)
+		r := mocks.NewCustomResolver(nil, nil, nil, nil)
 		client, err := NewClient()
 		assert.NotNil(t, client)
 		assert.NoError(t, err)
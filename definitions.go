@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-resty/resty/v2"
@@ -8,17 +9,21 @@ import (
 
 // Defaults for paymail functions
 const (
-	defaultDNSPort           = "53"                     // Default port for DNS / NameServer checks
-	defaultDNSTimeout        = 5 * time.Second          // In seconds
-	defaultHTTPTimeout       = 20 * time.Second         // Default timeout for all GET requests in seconds
-	defaultNameServer        = "8.8.8.8"                // Default DNS NameServer
-	defaultNameServerNetwork = "udp"                    // Default for NS dialer
-	defaultRetryCount        = 2                        // Default retry count for HTTP requests
-	defaultSSLDeadline       = 10 * time.Second         // Default deadline in seconds
-	defaultSSLTimeout        = 10 * time.Second         // Default timeout in seconds
-	defaultUserAgent         = "go-paymail: " + version // Default user agent
-	defaultNetwork           = byte(Mainnet)            // Default network
-	version                  = "v0.9.3"                 // Go-Paymail version
+	defaultDiscoveryCacheSize   = 1000                     // Default max entries of the in-memory discovery cache
+	defaultDiscoveryNegativeTTL = 1 * time.Minute          // Default TTL for unknown domains
+	defaultDiscoveryStaleTTL    = 1 * time.Minute          // Default time to serve stale entries while revalidating
+	defaultDiscoveryTTL         = 5 * time.Minute          // Default TTL if the SRV TTL or Cache-Control is unknown
+	defaultDNSPort              = "53"                     // Default port for DNS / NameServer checks
+	defaultDNSTimeout           = 5 * time.Second          // In seconds
+	defaultHTTPTimeout          = 20 * time.Second         // Default timeout for all GET requests in seconds
+	defaultNameServer           = "8.8.8.8"                // Default DNS NameServer
+	defaultNameServerNetwork    = "udp"                    // Default for NS dialer
+	defaultRetryCount           = 2                        // Default retry count for HTTP requests
+	defaultSSLDeadline          = 10 * time.Second         // Default deadline in seconds
+	defaultSSLTimeout           = 10 * time.Second         // Default timeout in seconds
+	defaultUserAgent            = "go-paymail: " + version // Default user agent
+	defaultNetwork              = byte(Mainnet)            // Default network
+	version                     = "v0.9.3"                 // Go-Paymail version
 )
 
 // Public defaults for paymail specs
@@ -50,6 +55,7 @@ const (
 // StandardResponse is the standard fields returned on all responses
 type StandardResponse struct {
 	Body       []byte          `json:"-"` // Body of the response request
+	Header     http.Header     `json:"-"` // Headers returned on the request
 	StatusCode int             `json:"-"` // Status code returned on the request
 	Tracing    resty.TraceInfo `json:"-"` // Trace information if enabled on the request
 }
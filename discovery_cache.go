@@ -0,0 +1,246 @@
+package paymail
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Prefixes for the discovery cache keys
+const (
+	discoveryKeyCapabilities = "capabilities:"
+	discoveryKeySRV          = "srv:"
+)
+
+// DiscoveryCache is a cache for the host discovery (SRV records) and capability discovery (/.well-known/bsvalias)
+//
+// Entries are stored until their StaleUntil time, the client decides if an entry is fresh or stale
+type DiscoveryCache interface {
+	Delete(key string)
+	Get(key string) (entry *DiscoveryCacheEntry, ok bool)
+	Set(key string, entry *DiscoveryCacheEntry)
+}
+
+// DiscoveryCacheEntry is a single (positive or negative) result of a discovery lookup
+//
+// The capabilities entries keep the body, headers and status code of the response (no tracing)
+type DiscoveryCacheEntry struct {
+	Body         []byte               `json:"body,omitempty"`         // Body of the capabilities response
+	Capabilities *CapabilitiesPayload `json:"capabilities,omitempty"` // Capabilities of the provider
+	Error        *ProviderError       `json:"error,omitempty"`        // Set on negative entries (unknown domain)
+	ExpiresAt    time.Time            `json:"expires_at"`             // Entry is fresh until this time
+	Header       http.Header          `json:"header,omitempty"`       // Headers of the capabilities response
+	SRV          *net.SRV             `json:"srv,omitempty"`          // SRV record of the domain
+	StaleUntil   time.Time            `json:"stale_until"`            // Entry is served (while revalidating) until this time
+	StatusCode   int                  `json:"status_code,omitempty"`  // Status code of the capabilities response
+}
+
+// err will return the error of a negative entry
+func (e *DiscoveryCacheEntry) err() error {
+	if e.Error == nil {
+		return nil
+	}
+	providerErr := *e.Error
+	return &providerErr
+}
+
+// discoveryFetcher fetches a fresh entry for the discovery cache
+type discoveryFetcher func(ctx context.Context) (*DiscoveryCacheEntry, error)
+
+// discover will return the cached entry for the key, or fetch (and cache) it
+//
+// Stale entries are returned right away while they are refreshed in the background.
+// Returns fetched if the entry was fetched by this call (fetch has returned before discover)
+func (c *Client) discover(ctx context.Context, key string, fetch discoveryFetcher) (entry *DiscoveryCacheEntry,
+	fetched bool, err error) {
+	now := time.Now()
+	var ok bool
+	if entry, ok = c.options.discoveryCache.Get(key); ok && entry != nil {
+		if now.Before(entry.ExpiresAt) {
+			return entry, false, entry.err()
+		} else if now.Before(entry.StaleUntil) && c.revalidate(ctx, key, fetch) {
+			return entry, false, entry.err()
+		}
+	}
+	entry, err = c.refresh(ctx, key, fetch)
+	return entry, true, err
+}
+
+// refresh will fetch the entry and store it in the discovery cache (if cacheable)
+func (c *Client) refresh(ctx context.Context, key string, fetch discoveryFetcher) (*DiscoveryCacheEntry, error) {
+	entry, err := fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if entry.StaleUntil.After(time.Now()) {
+		c.options.discoveryCache.Set(key, entry)
+	}
+	return entry, entry.err()
+}
+
+// revalidate will refresh the entry in the background (only one refresh per key at a time)
+//
+// Returns false if the client is closed (no refresh is started, the entry must be refreshed by the caller)
+func (c *Client) revalidate(ctx context.Context, key string, fetch discoveryFetcher) bool {
+	c.backgroundMu.Lock()
+	defer c.backgroundMu.Unlock()
+	if c.closed {
+		return false
+	}
+
+	if _, running := c.revalidating.LoadOrStore(key, struct{}{}); running {
+		return true
+	}
+	c.background.Add(1)
+	go func() {
+		defer c.background.Done()
+		defer c.revalidating.Delete(key)
+		_, _ = c.refresh(context.WithoutCancel(ctx), key, fetch)
+	}()
+	return true
+}
+
+// newDiscoveryEntry will return an entry which is fresh for the ttl and then stale for staleTTL
+func newDiscoveryEntry(ttl, staleTTL time.Duration) *DiscoveryCacheEntry {
+	now := time.Now()
+	return &DiscoveryCacheEntry{
+		ExpiresAt:  now.Add(ttl),
+		StaleUntil: now.Add(ttl + staleTTL),
+	}
+}
+
+// newNegativeDiscoveryEntry will return a negative entry for the error (never served stale)
+//
+// Unknown domains (DNS errors) are stored as a ProviderError of the discovery with a 404 status code (ErrCapabilitiesNotFound)
+func (c *Client) newNegativeDiscoveryEntry(requestURL string, err error) *DiscoveryCacheEntry {
+	entry := newDiscoveryEntry(c.options.discoveryNegativeTTL, 0)
+	if !errors.As(err, &entry.Error) {
+		entry.Error = &ProviderError{Discovery: true, Message: err.Error(), StatusCode: http.StatusNotFound, URL: requestURL}
+	}
+	return entry
+}
+
+// isUnknownDomainError will return true if the error means the domain (or provider) does not exist
+func isUnknownDomainError(err error) bool {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.StatusCode == http.StatusNotFound
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
+
+// cacheControl is the parsed Cache-Control header (only the directives used by the discovery cache)
+type cacheControl struct {
+	maxAge               time.Duration
+	hasMaxAge            bool
+	noStore              bool
+	staleWhileRevalidate time.Duration
+	hasStale             bool
+}
+
+// parseCacheControl will parse the Cache-Control header
+func parseCacheControl(header string) (cc cacheControl) {
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(name) {
+		case "no-store", "no-cache":
+			cc.noStore = true
+		case "max-age":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				cc.maxAge, cc.hasMaxAge = time.Duration(seconds)*time.Second, true
+			}
+		case "stale-while-revalidate":
+			if seconds, err := strconv.Atoi(strings.Trim(value, `"`)); err == nil && seconds >= 0 {
+				cc.staleWhileRevalidate, cc.hasStale = time.Duration(seconds)*time.Second, true
+			}
+		}
+	}
+	return
+}
+
+// memoryDiscoveryCache is an in-memory LRU implementation of the DiscoveryCache
+type memoryDiscoveryCache struct {
+	items      map[string]*list.Element
+	lru        *list.List
+	maxEntries int
+	sync.Mutex
+}
+
+// memoryDiscoveryItem is a single item in the LRU list
+type memoryDiscoveryItem struct {
+	entry *DiscoveryCacheEntry
+	key   string
+}
+
+// NewMemoryDiscoveryCache will return an in-memory LRU DiscoveryCache
+//
+// If maxEntries is zero, the default size is used
+func NewMemoryDiscoveryCache(maxEntries int) DiscoveryCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultDiscoveryCacheSize
+	}
+	return &memoryDiscoveryCache{
+		items:      make(map[string]*list.Element),
+		lru:        list.New(),
+		maxEntries: maxEntries,
+	}
+}
+
+// Get will return the entry (if found and not past its StaleUntil time)
+func (m *memoryDiscoveryCache) Get(key string) (*DiscoveryCacheEntry, bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*memoryDiscoveryItem)
+	if time.Now().After(item.entry.StaleUntil) {
+		m.remove(element)
+		return nil, false
+	}
+	m.lru.MoveToFront(element)
+	return item.entry, true
+}
+
+// Set will store the entry, evicting the least recently used entries when full
+func (m *memoryDiscoveryCache) Set(key string, entry *DiscoveryCacheEntry) {
+	m.Lock()
+	defer m.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		element.Value.(*memoryDiscoveryItem).entry = entry
+		m.lru.MoveToFront(element)
+		return
+	}
+
+	m.items[key] = m.lru.PushFront(&memoryDiscoveryItem{entry: entry, key: key})
+	for m.lru.Len() > m.maxEntries {
+		m.remove(m.lru.Back())
+	}
+}
+
+// Delete will remove the entry
+func (m *memoryDiscoveryCache) Delete(key string) {
+	m.Lock()
+	defer m.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+}
+
+// remove will remove the element from the list and the map (lock must be held)
+func (m *memoryDiscoveryCache) remove(element *list.Element) {
+	m.lru.Remove(element)
+	delete(m.items, element.Value.(*memoryDiscoveryItem).key)
+}
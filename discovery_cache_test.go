@@ -0,0 +1,393 @@
+package paymail
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/interfaces"
+)
+
+// testCapabilitiesURL is the mocked capabilities url of the test domain
+const testCapabilitiesURL = "https://" + testDomain + ":443/.well-known/" + DefaultServiceName
+
+// ttlResolver is a resolver returning a fixed TTL for the SRV records (and counting the lookups)
+type ttlResolver struct {
+	interfaces.DNSResolver
+	lookups atomic.Int32
+	ttl     time.Duration
+}
+
+// LookupSRVWithTTL will look up the SRV record and return the fixed TTL
+func (r *ttlResolver) LookupSRVWithTTL(ctx context.Context, service, proto,
+	name string) (string, []*net.SRV, time.Duration, error) {
+	r.lookups.Add(1)
+	cname, records, err := r.LookupSRV(ctx, service, proto, name)
+	return cname, records, r.ttl, err
+}
+
+// mockCachedCapabilities will mock the capabilities with the given Cache-Control header
+func mockCachedCapabilities(statusCode int, cacheControl string) {
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, testCapabilitiesURL,
+		func(_ *http.Request) (*http.Response, error) {
+			resp := httpmock.NewStringResponse(statusCode, `{"`+DefaultServiceName+`": "`+DefaultBsvAliasVersion+`","capabilities": {"`+BRFCPki+`": "`+testServerURL+`id/{alias}@{domain.tld}"}}`)
+			if len(cacheControl) > 0 {
+				resp.Header.Set("Cache-Control", cacheControl)
+			}
+			return resp, nil
+		},
+	)
+}
+
+// capabilitiesCalls will return the number of capabilities requests
+func capabilitiesCalls() int {
+	return httpmock.GetCallCountInfo()[http.MethodGet+" "+testCapabilitiesURL]
+}
+
+// TestClient_GetCapabilitiesCached will test the method GetCapabilities() using the discovery cache
+func TestClient_GetCapabilitiesCached(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	t.Run("cached using max-age", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "public, max-age=60")
+
+		for i := 0; i < 3; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+			require.NotNil(t, response)
+			assert.Equal(t, http.StatusOK, response.StatusCode)
+			assert.True(t, response.Has(BRFCPki, BRFCPkiAlternate))
+		}
+		assert.Equal(t, 1, capabilitiesCalls())
+
+		entry, ok := cache.Get(discoveryKeyCapabilities + testCapabilitiesURL)
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(60*time.Second), entry.ExpiresAt, 5*time.Second)
+		assert.WithinDuration(t, entry.ExpiresAt.Add(defaultDiscoveryStaleTTL), entry.StaleUntil, time.Second)
+	})
+
+	t.Run("cached response keeps the metadata", func(t *testing.T) {
+		client := newTestClient(t, WithDiscoveryCache(NewMemoryDiscoveryCache(10)), WithRequestTracing())
+		mockCachedCapabilities(http.StatusOK, "max-age=60")
+
+		fetched, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.NotEqual(t, resty.TraceInfo{}, fetched.Tracing, "the fetched response is returned as-is")
+
+		cached, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, 1, capabilitiesCalls())
+		assert.Equal(t, fetched.StatusCode, cached.StatusCode)
+		assert.Equal(t, "max-age=60", cached.Header.Get("Cache-Control"))
+		assert.Equal(t, fetched.Body, cached.Body)
+		assert.Equal(t, resty.TraceInfo{}, cached.Tracing)
+	})
+
+	t.Run("returned capabilities are not shared with the cache", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "max-age=60")
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		response.Capabilities[BRFCPki] = "https://attacker.tld/id/{alias}@{domain.tld}"
+		delete(response.Capabilities, BRFCPki)
+		response.Capabilities["custom"] = true
+
+		response, err = client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, testServerURL+"id/{alias}@{domain.tld}", response.GetString(BRFCPki, BRFCPkiAlternate))
+		assert.False(t, response.Has("custom", ""))
+		assert.Equal(t, 1, capabilitiesCalls())
+
+		entry, ok := cache.Get(discoveryKeyCapabilities + testCapabilitiesURL)
+		require.True(t, ok)
+		assert.Len(t, entry.Capabilities.Capabilities, 1)
+	})
+
+	t.Run("nested capabilities are copied", func(t *testing.T) {
+		outputs := "https://" + testDomain + "/pike/outputs"
+		payload := &CapabilitiesPayload{
+			BsvAlias:     DefaultBsvAliasVersion,
+			Capabilities: map[string]interface{}{BRFCPike: map[string]interface{}{BRFCPikeOutputs: outputs}, "list": []interface{}{"a"}},
+			Pike:         &PikeCapability{Outputs: &outputs},
+		}
+
+		clone := payload.clone()
+		clone.Capabilities[BRFCPike].(map[string]interface{})[BRFCPikeOutputs] = "changed"
+		clone.Capabilities["list"].([]interface{})[0] = "changed"
+		*clone.Pike.Outputs = "changed"
+
+		assert.Equal(t, outputs, payload.Capabilities[BRFCPike].(map[string]interface{})[BRFCPikeOutputs])
+		assert.Equal(t, "a", payload.Capabilities["list"].([]interface{})[0])
+		assert.Equal(t, outputs, *payload.Pike.Outputs)
+	})
+
+	t.Run("no-store is not cached", func(t *testing.T) {
+		client := newTestClient(t, WithDiscoveryCache(NewMemoryDiscoveryCache(10)))
+		mockCachedCapabilities(http.StatusOK, "no-store")
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 2, capabilitiesCalls())
+	})
+
+	t.Run("stale entry is served while revalidating", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "max-age=60, stale-while-revalidate=30")
+
+		key := discoveryKeyCapabilities + testCapabilitiesURL
+		cache.Set(key, &DiscoveryCacheEntry{
+			Capabilities: &CapabilitiesPayload{BsvAlias: "stale", Capabilities: map[string]interface{}{}},
+			ExpiresAt:    time.Now().Add(-time.Second),
+			StaleUntil:   time.Now().Add(time.Minute),
+		})
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, "stale", response.BsvAlias)
+
+		require.Eventually(t, func() bool {
+			entry, ok := cache.Get(key)
+			return ok && entry.Capabilities.BsvAlias == DefaultBsvAliasVersion
+		}, time.Second, 10*time.Millisecond)
+		assert.Equal(t, 1, capabilitiesCalls())
+
+		entry, _ := cache.Get(key)
+		assert.WithinDuration(t, entry.ExpiresAt.Add(30*time.Second), entry.StaleUntil, time.Second)
+	})
+
+	t.Run("close waits for the revalidation", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "max-age=60")
+
+		key := discoveryKeyCapabilities + testCapabilitiesURL
+		cache.Set(key, &DiscoveryCacheEntry{
+			Capabilities: &CapabilitiesPayload{BsvAlias: "stale", Capabilities: map[string]interface{}{}},
+			ExpiresAt:    time.Now().Add(-time.Second),
+			StaleUntil:   time.Now().Add(time.Minute),
+		})
+
+		_, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+
+		client.Close()
+		entry, ok := cache.Get(key)
+		require.True(t, ok)
+		assert.Equal(t, DefaultBsvAliasVersion, entry.Capabilities.BsvAlias)
+	})
+
+	t.Run("stale entry is refreshed in the foreground after close", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "max-age=60")
+		client.Close()
+
+		cache.Set(discoveryKeyCapabilities+testCapabilitiesURL, &DiscoveryCacheEntry{
+			Capabilities: &CapabilitiesPayload{BsvAlias: "stale", Capabilities: map[string]interface{}{}},
+			ExpiresAt:    time.Now().Add(-time.Second),
+			StaleUntil:   time.Now().Add(time.Minute),
+		})
+
+		response, err := client.GetCapabilities(testDomain, DefaultPort)
+		require.NoError(t, err)
+		assert.Equal(t, DefaultBsvAliasVersion, response.BsvAlias)
+		assert.Equal(t, 1, capabilitiesCalls())
+	})
+
+	t.Run("close while revalidating", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		mockCachedCapabilities(http.StatusOK, "max-age=0")
+		cache.Set(discoveryKeyCapabilities+testCapabilitiesURL, &DiscoveryCacheEntry{
+			Capabilities: &CapabilitiesPayload{BsvAlias: "stale", Capabilities: map[string]interface{}{}},
+			ExpiresAt:    time.Now().Add(-time.Second),
+			StaleUntil:   time.Now().Add(time.Minute),
+		})
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := client.GetCapabilities(testDomain, DefaultPort)
+				assert.NoError(t, err)
+			}()
+		}
+		client.Close()
+		wg.Wait()
+		client.Close()
+	})
+
+	t.Run("unknown domain is cached (negative)", func(t *testing.T) {
+		client := newTestClient(t, WithDiscoveryCache(NewMemoryDiscoveryCache(10)))
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testCapabilitiesURL,
+			httpmock.NewStringResponder(http.StatusNotFound, `{"message": "not found"}`),
+		)
+
+		for i := 0; i < 2; i++ {
+			response, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.Error(t, err)
+			assert.Nil(t, response)
+			assert.ErrorIs(t, err, ErrCapabilitiesNotFound)
+			assert.NotErrorIs(t, err, ErrPaymailNotFound)
+			assert.Contains(t, err.Error(), "code 404")
+		}
+		assert.Equal(t, 1, capabilitiesCalls())
+	})
+
+	t.Run("server errors are not cached", func(t *testing.T) {
+		client := newTestClient(t, WithDiscoveryCache(NewMemoryDiscoveryCache(10)))
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodGet, testCapabilitiesURL,
+			httpmock.NewStringResponder(http.StatusServiceUnavailable, `{"message": "unavailable"}`),
+		)
+
+		for i := 0; i < 2; i++ {
+			_, err := client.GetCapabilities(testDomain, DefaultPort)
+			require.Error(t, err)
+		}
+		assert.Equal(t, 2, capabilitiesCalls())
+	})
+}
+
+// TestClient_GetSRVRecordCached will test the method GetSRVRecord() using the discovery cache
+func TestClient_GetSRVRecordCached(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ttl of the record is used", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t, WithDiscoveryCache(cache))
+		resolver := &ttlResolver{DNSResolver: client.GetResolver(), ttl: 2 * time.Hour}
+		client.WithCustomResolver(resolver)
+
+		for i := 0; i < 2; i++ {
+			srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+			require.NoError(t, err)
+			require.NotNil(t, srv)
+			assert.Equal(t, "www."+testDomain, srv.Target)
+			assert.Equal(t, uint16(DefaultPort), srv.Port)
+		}
+		assert.Equal(t, int32(1), resolver.lookups.Load())
+
+		entry, ok := cache.Get(discoveryKeySRV + DefaultServiceName + "." + DefaultProtocol + "." + testDomain)
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(2*time.Hour), entry.ExpiresAt, 5*time.Second)
+	})
+
+	t.Run("cached record is a copy", func(t *testing.T) {
+		client := newTestClient(t, WithDiscoveryCache(NewMemoryDiscoveryCache(10)))
+
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		srv.Target = "modified"
+
+		srv, err = client.GetSRVRecord(DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		assert.Equal(t, "www."+testDomain, srv.Target)
+	})
+
+	t.Run("missing record uses the negative ttl", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(10)
+		client := newTestClient(t,
+			WithDiscoveryCache(cache), WithDiscoveryCacheTTL(time.Hour, time.Minute, 10*time.Second),
+		)
+
+		srv, err := client.GetSRVRecord(DefaultServiceName, DefaultProtocol, "norecords.com")
+		require.NoError(t, err)
+		assert.Equal(t, "norecords.com", srv.Target)
+
+		entry, ok := cache.Get(discoveryKeySRV + DefaultServiceName + "." + DefaultProtocol + ".norecords.com")
+		require.True(t, ok)
+		assert.WithinDuration(t, time.Now().Add(10*time.Second), entry.ExpiresAt, 5*time.Second)
+	})
+}
+
+// TestMemoryDiscoveryCache will test the in-memory LRU DiscoveryCache
+func TestMemoryDiscoveryCache(t *testing.T) {
+	t.Parallel()
+
+	fresh := func() *DiscoveryCacheEntry {
+		return newDiscoveryEntry(time.Minute, time.Minute)
+	}
+
+	t.Run("set, get and delete", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(0)
+		entry := fresh()
+		cache.Set("key", entry)
+
+		found, ok := cache.Get("key")
+		require.True(t, ok)
+		assert.Equal(t, entry, found)
+
+		cache.Delete("key")
+		_, ok = cache.Get("key")
+		assert.False(t, ok)
+	})
+
+	t.Run("least recently used is evicted", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(2)
+		cache.Set("a", fresh())
+		cache.Set("b", fresh())
+		_, _ = cache.Get("a")
+		cache.Set("c", fresh())
+
+		_, ok := cache.Get("a")
+		assert.True(t, ok)
+		_, ok = cache.Get("b")
+		assert.False(t, ok)
+		_, ok = cache.Get("c")
+		assert.True(t, ok)
+	})
+
+	t.Run("expired entry is removed", func(t *testing.T) {
+		cache := NewMemoryDiscoveryCache(2)
+		cache.Set("expired", newDiscoveryEntry(-2*time.Second, time.Second))
+
+		_, ok := cache.Get("expired")
+		assert.False(t, ok)
+	})
+}
+
+// Test_parseCacheControl will test the method parseCacheControl()
+func Test_parseCacheControl(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		header   string
+		expected cacheControl
+	}{
+		{"", cacheControl{}},
+		{"no-store", cacheControl{noStore: true}},
+		{"no-cache", cacheControl{noStore: true}},
+		{"max-age=60", cacheControl{maxAge: time.Minute, hasMaxAge: true}},
+		{"public, MAX-AGE=\"120\"", cacheControl{maxAge: 2 * time.Minute, hasMaxAge: true}},
+		{"max-age=60, stale-while-revalidate=30", cacheControl{
+			maxAge: time.Minute, hasMaxAge: true, staleWhileRevalidate: 30 * time.Second, hasStale: true,
+		}},
+		{"max-age=invalid", cacheControl{}},
+		{"max-age=-1", cacheControl{}},
+	}
+	for _, test := range tests {
+		t.Run(test.header, func(t *testing.T) {
+			assert.Equal(t, test.expected, parseCacheControl(test.header))
+		})
+	}
+}
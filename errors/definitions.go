@@ -27,6 +27,12 @@ var (
 	ErrServiceProviderNil = SPVError{Message: "service provider is nil", StatusCode: 500, Code: "error-configuration-service-provider-nil"}
 )
 
+// SERVER ERRORS
+var (
+	// ErrServerAlreadyStarted is when the server is started more than once
+	ErrServerAlreadyStarted = SPVError{Message: "server was already started", StatusCode: 500, Code: "error-server-already-started"}
+)
+
 // CAPABILITY ERRORS
 var (
 	//ErrPrefixOrDomainMissing is when the prefix or domain is missing
@@ -49,6 +55,12 @@ var (
 
 	// ErrProcessingBEEF is when error occurred during processing beef
 	ErrProcessingBEEF = SPVError{Message: "cannot process beef", StatusCode: 400, Code: "error-processing-beef"}
+
+	// ErrBEEFLimitExceeded is when the beef exceeds the configured limits (size, transactions, BUMPs)
+	ErrBEEFLimitExceeded = SPVError{Message: "beef exceeds the limits", StatusCode: 413, Code: "error-processing-beef-limit-exceeded"}
+
+	// ErrRequestTooLarge is when the request body exceeds the maximum size
+	ErrRequestTooLarge = SPVError{Message: "request body is too large", StatusCode: 413, Code: "error-request-too-large"}
 )
 
 // PAYMAIL ERRORS
@@ -93,6 +105,9 @@ var (
 	// ErrMissingFieldSignature is when the signature field is required but missing
 	ErrMissingFieldSignature = SPVError{Message: "missing required field: signature", StatusCode: 400, Code: "error-missing-field-signature"}
 
+	// ErrMissingFieldNonce is when the nonce field is required but missing
+	ErrMissingFieldNonce = SPVError{Message: "missing required field: nonce", StatusCode: 400, Code: "error-missing-field-nonce"}
+
 	// ErrMissingFieldPubKey is when the pubkey field is required but missing
 	ErrMissingFieldPubKey = SPVError{Message: "missing required field: pubkey", StatusCode: 400, Code: "error-missing-field-pubkey"}
 
@@ -109,6 +124,57 @@ var (
 	ErrDtEmpty = SPVError{Message: "empty dt", StatusCode: 400, Code: "error-dt-empty"}
 )
 
+// REFERENCE ERRORS
+var (
+	// ErrReferenceNotFound is when the reference was not issued by the server (or has expired)
+	ErrReferenceNotFound = SPVError{Message: "reference is unknown or expired", StatusCode: 400, Code: "error-reference-not-found"}
+
+	// ErrReferenceConflict is when the reference was already used by another transaction
+	ErrReferenceConflict = SPVError{Message: "reference was already used by another transaction", StatusCode: 409, Code: "error-reference-txid-conflict"}
+
+	// ErrReferenceInProgress is when the transaction for the reference is still being recorded
+	ErrReferenceInProgress = SPVError{Message: "transaction for the reference is being processed", StatusCode: 409, Code: "error-reference-in-progress"}
+
+	// ErrReferencePaymailMismatch is when the reference was issued for another paymail than the receiving paymail
+	ErrReferencePaymailMismatch = SPVError{Message: "reference was issued for another paymail", StatusCode: 400, Code: "error-reference-paymail-mismatch"}
+
+	// ErrReferenceOutputMissing is when the transaction does not contain an output script issued for the reference
+	ErrReferenceOutputMissing = SPVError{Message: "transaction does not pay the output script issued for the reference", StatusCode: 417, Code: "error-reference-output-missing"}
+
+	// ErrReferenceOutputSatoshisMismatch is when the output script is paid with different satoshis than issued
+	ErrReferenceOutputSatoshisMismatch = SPVError{Message: "transaction output satoshis do not match the output issued for the reference", StatusCode: 417, Code: "error-reference-output-satoshis-mismatch"}
+
+	// ErrReferenceSatoshisTooLow is when the issued outputs are paid less than the requested amount
+	ErrReferenceSatoshisTooLow = SPVError{Message: "transaction pays less than the amount requested for the reference", StatusCode: 417, Code: "error-reference-satoshis-too-low"}
+)
+
+// REPLAY ERRORS
+var (
+	// ErrTimestampExpired is when the timestamp of the signed request is older than the replay window
+	ErrTimestampExpired = SPVError{Message: "timestamp is older than the replay window", StatusCode: 400, Code: "error-replay-timestamp-expired"}
+
+	// ErrTimestampInFuture is when the timestamp of the signed request is later than the replay window
+	ErrTimestampInFuture = SPVError{Message: "timestamp is later than the replay window", StatusCode: 400, Code: "error-replay-timestamp-in-future"}
+
+	// ErrRequestReplayed is when the signed request was already accepted
+	ErrRequestReplayed = SPVError{Message: "signed request was already used", StatusCode: 409, Code: "error-replay-request-reused"}
+)
+
+// RATE LIMIT ERRORS
+var (
+	// ErrTooManyRequests is when the client or the target paymail exceeded the rate limit of the capability
+	ErrTooManyRequests = SPVError{Message: "too many requests", StatusCode: 429, Code: "error-rate-limit-exceeded"}
+)
+
+// PIKE ERRORS
+var (
+	// ErrContactNotFound is when the paymail has no contact (or contact request) with the requesting paymail
+	ErrContactNotFound = SPVError{Message: "contact not found", StatusCode: 404, Code: "error-pike-contact-not-found"}
+
+	// ErrContactStatusInvalid is when the contact cannot change to the requested status (e.g. accepting a rejected request)
+	ErrContactStatusInvalid = SPVError{Message: "contact status cannot be changed", StatusCode: 409, Code: "error-pike-contact-status-invalid"}
+)
+
 // SPV ERRORS
 var (
 	// ErrNoOutputs is when there are no outputs
@@ -121,7 +187,7 @@ var (
 	ErrInvalidParentTransactions = SPVError{Message: "invalid parent transactions, no matching transactions for input", StatusCode: 417, Code: "error-spv-parent-tx-invalid"}
 
 	// ErrLockTimeAndSequence is when the locktime and sequence are invalid
-	ErrLockTimeAndSequence = SPVError{Message: "nLocktime is set and nSequence is not max, therefore this could be a non-final tx which is not currently supported", StatusCode: 417, Code: "error-spv-locktime-sequence-invalid"}
+	ErrLockTimeAndSequence = SPVError{Message: "nLocktime is set and nSequence is not max, the transaction is not final", StatusCode: 417, Code: "error-spv-locktime-sequence-invalid"}
 
 	// ErrOutputValueTooHigh is when the satoshis output is too high on a transaction
 	ErrOutputValueTooHigh = SPVError{Message: "invalid input and output sum, outputs can not be larger than inputs", StatusCode: 417, Code: "error-spv-output-value-too-high"}
@@ -135,6 +201,39 @@ var (
 	// ErrNoMatchingTransactionsForInput is when no matching transaction for input can be found
 	ErrNoMatchingTransactionsForInput = SPVError{Message: "invalid parent transactions, no matching transactions for input", StatusCode: 417, Code: "error-spv-bump-ancestor-not-present"}
 
+	// ErrMerkleRootNotConfirmed is when the merkle root is not confirmed in the longest chain
+	ErrMerkleRootNotConfirmed = SPVError{Message: "invalid BUMP - merkle root is not confirmed in the longest chain", StatusCode: 417, Code: "error-spv-merkle-root-not-confirmed"}
+
+	// ErrMerkleRootVerifierMissing is when the SPV is executed without a merkle root verifier
+	ErrMerkleRootVerifierMissing = SPVError{Message: "merkle root verifier is missing", StatusCode: 500, Code: "error-spv-merkle-root-verifier-missing"}
+
+	// ErrMerkleRootInvalid is when the headers service reports the merkle root as invalid (not in the longest chain)
+	ErrMerkleRootInvalid = SPVError{Message: "invalid BUMP - merkle root is invalid", StatusCode: 417, Code: "error-spv-merkle-root-invalid"}
+
+	// ErrMerkleRootUnableToVerify is when the headers service cannot verify the merkle root yet (e.g. the block is not synced)
+	ErrMerkleRootUnableToVerify = SPVError{Message: "merkle root cannot be verified yet", StatusCode: 503, Code: "error-spv-merkle-root-unable-to-verify"}
+
+	// ErrHeadersServiceFailed is when the request to the headers service has failed
+	ErrHeadersServiceFailed = SPVError{Message: "headers service request has failed", StatusCode: 502, Code: "error-spv-headers-service-failed"}
+
+	// ErrAtomicSubjectMismatch is when the subject of an Atomic BEEF is not the verified transaction
+	ErrAtomicSubjectMismatch = SPVError{Message: "invalid atomic BEEF - subject txid does not match the verified transaction", StatusCode: 417, Code: "error-spv-atomic-subject-mismatch"}
+
+	// ErrTxIDOnlyParent is when the parent transaction is provided only as a txid (BEEF V2) and cannot be verified
+	ErrTxIDOnlyParent = SPVError{Message: "invalid parent transactions, parent transaction is provided as txid only", StatusCode: 417, Code: "error-spv-parent-tx-txid-only"}
+
+	// ErrChainTipUnavailable is when the chain tip for the lock time evaluation cannot be retrieved
+	ErrChainTipUnavailable = SPVError{Message: "chain tip is unavailable, cannot evaluate the lock time", StatusCode: 503, Code: "error-spv-chain-tip-unavailable"}
+
+	// ErrFeeTooLow is when the fee rate of a transaction is below the policy minimum
+	ErrFeeTooLow = SPVError{Message: "transaction fee rate is below the minimum", StatusCode: 417, Code: "error-spv-policy-fee-too-low"}
+
+	// ErrDustOutput is when an output of a transaction is below the policy dust limit
+	ErrDustOutput = SPVError{Message: "transaction output is below the dust limit", StatusCode: 417, Code: "error-spv-policy-dust-output"}
+
+	// ErrTxTooLarge is when a transaction is larger than the policy maximum size
+	ErrTxTooLarge = SPVError{Message: "transaction is larger than the maximum size", StatusCode: 417, Code: "error-spv-policy-tx-too-large"}
+
 	// ErrSPVFailed is when the SPV returns an error
 	ErrSPVFailed = SPVError{Message: "simplified payment verification has failed", StatusCode: 417, Code: "error-spv-failed"}
 )
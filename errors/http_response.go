@@ -1,6 +1,7 @@
 package errors
 
 import (
+	"encoding/json"
 	"errors"
 	"net/http"
 
@@ -8,10 +9,17 @@ import (
 	"github.com/rs/zerolog"
 )
 
-// ErrorResponse is a standard way to return errors to the client
+// ErrorResponse is a standard way to return errors to the client (gin adapter of WriteErrorResponse)
 func ErrorResponse(c *gin.Context, err error, log *zerolog.Logger) {
+	WriteErrorResponse(c.Writer, err, log)
+}
+
+// WriteErrorResponse is a standard way to return errors to the client
+func WriteErrorResponse(w http.ResponseWriter, err error, log *zerolog.Logger) {
 	response, statusCode := mapAndLog(err, log)
-	c.JSON(statusCode, response)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(response)
 }
 
 func mapAndLog(err error, log *zerolog.Logger) (ResponseError, int) {
@@ -3,12 +3,20 @@ package main
 import (
 	"log"
 
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+
 	"github.com/bitcoin-sv/go-paymail"
 )
 
 func main() {
-	// Load the client
-	client, err := paymail.NewClient()
+	// Load the PKI key of the requester (johndoe@example.com), a new key is used for the demo
+	privateKey, err := ec.NewPrivateKey()
+	if err != nil {
+		log.Fatalf("error loading the private key: %s", err.Error())
+	}
+
+	// Load the client (the contact requests are signed with the PKI key)
+	client, err := paymail.NewClient(paymail.WithPikeSigningKey(privateKey))
 	if err != nil {
 		log.Fatalf("error loading client: %s", err.Error())
 	}
@@ -33,7 +41,7 @@ func main() {
 		Paymail:  "johndoe@example.com",
 	}
 
-	// Send the contact request using the invite URL
+	// Send the signed contact request using the invite URL
 	var response *paymail.PikeContactRequestResponse
 	if response, err = client.AddInviteRequest(pikeInviteURL, "alias", "domain.tld", request); err != nil {
 		log.Fatalf("error sending invite request: %s", err.Error())
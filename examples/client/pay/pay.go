@@ -0,0 +1,43 @@
+package main
+
+import (
+	"context"
+	"log"
+
+	"github.com/bitcoin-sv/go-paymail"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+)
+
+func main() {
+	// Load the client
+	client, err := paymail.NewClient()
+	if err != nil {
+		log.Fatalf("error loading client: %s", err.Error())
+	}
+
+	// The key of the sender (used to sign the txid or the sender request)
+	senderKey, err := ec.PrivateKeyFromWif("replace-with-sender-wif") // todo: replace with a real key
+	if err != nil {
+		log.Fatalf("error loading sender key: %s", err.Error())
+	}
+
+	// Build (and sign) a transaction paying to the outputs of the receiver
+	buildTx := func(_ context.Context, outputs []*paymail.PaymentOutput) (*sdk.Transaction, error) {
+		tx := sdk.NewTransaction()
+		// todo: add (and sign) inputs from your wallet, then add the outputs of the receiver
+		return tx, nil
+	}
+
+	// Pay the receiver
+	var result *paymail.PaymentResult
+	result, err = client.Pay(
+		context.Background(), senderKey, "satchmo@moneybutton.com", 1000, buildTx,
+		paymail.WithPaymentSender("mrz@moneybutton.com", "MrZ"),
+		paymail.WithPaymentNote("Thanks for dinner Satchmo!"),
+	)
+	if err != nil {
+		log.Fatalf("error paying: %s", err.Error())
+	}
+	log.Printf("paid using %s: txid %s (sent: %t)", result.Capability, result.TxID, result.Sent)
+}
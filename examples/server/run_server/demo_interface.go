@@ -4,13 +4,16 @@ import (
 	"context"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
 	"github.com/bitcoin-sv/go-paymail/server"
 	"github.com/bitcoin-sv/go-paymail/spv"
+	"github.com/bitcoin-sv/go-paymail/spv/headers"
 )
 
 // Example demo implementation of a service provider
 type demoServiceProvider struct {
 	// Extend your dependencies or custom values
+	headers *headers.Store // Local block headers (merkle roots are not confirmed without them)
 }
 
 // GetPaymailByAlias is a demo implementation of this interface
@@ -47,8 +50,11 @@ func (d *demoServiceProvider) RecordTransaction(ctx context.Context,
 
 // VerifyMerkleRoots is a demo implementation of this interface
 func (d *demoServiceProvider) VerifyMerkleRoots(ctx context.Context, merkleProofs []*spv.MerkleRootConfirmationRequestItem) error {
-	// Verify the Merkle roots
-	return nil
+	// Verify the Merkle roots against the local header file (see HEADERS_FILE in run_server.go)
+	if d.headers == nil {
+		return errors.ErrMerkleRootNotConfirmed
+	}
+	return d.headers.VerifyMerkleRoots(ctx, merkleProofs)
 }
 
 func (d *demoServiceProvider) AddContact(
@@ -59,11 +65,35 @@ func (d *demoServiceProvider) AddContact(
 	return nil
 }
 
+func (d *demoServiceProvider) AcceptContact(
+	ctx context.Context,
+	paymailAddress string,
+	contact *paymail.PikeContactResponsePayload,
+) error {
+	return nil
+}
+
+func (d *demoServiceProvider) RejectContact(
+	ctx context.Context,
+	paymailAddress string,
+	contact *paymail.PikeContactResponsePayload,
+) error {
+	return nil
+}
+
+func (d *demoServiceProvider) GetContactStatus(
+	ctx context.Context,
+	paymailAddress string,
+	contact *paymail.PikeContactResponsePayload,
+) (*paymail.PikeContactStatusPayload, error) {
+	return &paymail.PikeContactStatusPayload{Paymail: contact.Paymail, Status: paymail.PikeContactStatusPending}, nil
+}
+
 func (d *demoServiceProvider) CreatePikeOutputResponse(
 	ctx context.Context,
 	alias, domain, senderPubKey string,
 	satoshis uint64,
 	metaData *server.RequestMetadata,
 ) (*paymail.PikePaymentOutputsResponse, error) {
-	return nil, nil
+	return DemoCreatePikeOutputResponse(ctx, alias, domain, senderPubKey, satoshis)
 }
@@ -161,6 +161,29 @@ func DemoCreateP2PDestinationResponse(_ context.Context, alias, domain string,
 	}, nil
 }
 
+// DemoCreatePikeOutputResponse will create the PIKE outputs derived from the key of the alias
+// and the PKI key of the sender (BRC-42)
+func DemoCreatePikeOutputResponse(_ context.Context, alias, domain, senderPubKey string,
+	satoshis uint64) (*paymail.PikePaymentOutputsResponse, error) {
+
+	// Get the paymail record
+	p, err := DemoGetPaymailByAlias(alias, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive the outputs from the identity key
+	key, err := ec.PrivateKeyFromHex(p.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	deriver, err := paymail.NewPikeOutputsDeriver(key)
+	if err != nil {
+		return nil, err
+	}
+	return deriver.CreateOutputs(senderPubKey, "", satoshis)
+}
+
 // DemoRecordTransaction will record the tx in the datalayer
 func DemoRecordTransaction(_ context.Context,
 	p2pTx *paymail.P2PTransaction) (*paymail.P2PTransactionPayload, error) {
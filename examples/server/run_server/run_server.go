@@ -1,15 +1,19 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/gin-gonic/gin"
-
 	"github.com/bitcoin-sv/go-paymail/logging"
 
 	"github.com/bitcoin-sv/go-paymail/server"
+	"github.com/bitcoin-sv/go-paymail/spv/headers"
 )
 
 func main() {
@@ -20,10 +24,21 @@ func main() {
 		logger.Fatal().Msg(err.Error())
 	}
 
+	// load the block headers (80 bytes headers from the genesis block) to confirm the merkle roots
+	provider := new(demoServiceProvider)
+	if headersFile := os.Getenv("HEADERS_FILE"); len(headersFile) > 0 {
+		provider.headers = headers.NewStore()
+		if _, err := provider.headers.ImportFile(headersFile); err != nil {
+			logger.Fatal().Msg(err.Error())
+		}
+	} else {
+		logger.Warn().Msg("HEADERS_FILE is not set, merkle roots will not be confirmed")
+	}
+
 	sl := server.PaymailServiceLocator{}
-	sl.RegisterPaymailService(new(demoServiceProvider))
-	sl.RegisterPikeContactService(new(demoServiceProvider))
-	sl.RegisterPikePaymentService(new(demoServiceProvider))
+	sl.RegisterPaymailService(provider)
+	sl.RegisterPikeContactService(provider)
+	sl.RegisterPikePaymentService(provider)
 
 	// Custom server with lots of customizable goodies
 	config, err := server.NewConfig(
@@ -43,8 +58,13 @@ func main() {
 		logger.Fatal().Msg(err.Error())
 	}
 
-	// Create & start the server
-	server.StartServer(server.CreateServer(config), config.Logger)
+	// Create & start the server (stops gracefully on SIGINT / SIGTERM)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err = server.NewServer(config).Start(ctx); err != nil {
+		logger.Fatal().Msg(err.Error())
+	}
 }
 
 func customCapabilities() map[string]any {
@@ -56,15 +76,15 @@ func customCapabilities() map[string]any {
 		"custom_callable_cap": server.CallableCapability{
 			Path:   fmt.Sprintf("/display_paymail/%s", server.PaymailAddressTemplate),
 			Method: http.MethodGet,
-			Handler: func(c *gin.Context) {
-				incomingPaymail := c.Param(server.PaymailAddressParamName)
+			Handler: func(w http.ResponseWriter, req *http.Request) {
+				incomingPaymail := req.PathValue(server.PaymailAddressParamName)
 
 				response := map[string]string{
 					"paymail": incomingPaymail,
 				}
 
-				c.Header("Content-Type", "application/json")
-				c.JSON(http.StatusOK, response)
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(response)
 			},
 		},
 	}
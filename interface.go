@@ -4,31 +4,60 @@ import (
 	"context"
 	"net"
 
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
 	"github.com/go-resty/resty/v2"
 
 	"github.com/bitcoin-sv/go-paymail/interfaces"
 )
 
 // ClientInterface is the Paymail client interface
+//
+// Methods are added as the client gains features, custom implementations (e.g. mocks)
+// should embed *Client or be updated on upgrade (see Upgrading in the README)
 type ClientInterface interface {
+	AcceptContactRequest(acceptURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error)
+	AcceptContactRequestCtx(ctx context.Context, acceptURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error)
+	AddContactRequest(url, alias, domain string, request *PikeContactRequestPayload) (response *PikeContactRequestResponse, err error)
+	AddContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload) (response *PikeContactRequestResponse, err error)
+	AddInviteRequest(inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error)
+	AddInviteRequestCtx(ctx context.Context, inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error)
+	AddSignedContactRequest(url, alias, domain string, request *PikeContactRequestPayload, privateKey *ec.PrivateKey) (*PikeContactRequestResponse, error)
+	AddSignedContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload, privateKey *ec.PrivateKey) (*PikeContactRequestResponse, error)
 	CheckDNSSEC(domain string) (result *DNSCheckResult)
+	CheckDNSSECCtx(ctx context.Context, domain string) (result *DNSCheckResult)
 	CheckSSL(host string) (valid bool, err error)
+	CheckSSLCtx(ctx context.Context, host string) (valid bool, err error)
+	Close()
 	GetBRFCs() []*BRFCSpec
 	GetCapabilities(target string, port int) (response *CapabilitiesResponse, err error)
+	GetCapabilitiesCtx(ctx context.Context, target string, port int) (response *CapabilitiesResponse, err error)
+	GetContactStatus(statusURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactStatusResponse, error)
+	GetContactStatusCtx(ctx context.Context, statusURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactStatusResponse, error)
 	GetOptions() *ClientOptions
+	GetOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error)
+	GetOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error)
 	GetP2PPaymentDestination(p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
+	GetP2PPaymentDestinationCtx(ctx context.Context, p2pURL, alias, domain string, paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error)
 	GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error)
+	GetPKICtx(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error)
 	GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
+	GetPublicProfileCtx(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error)
 	GetResolver() interfaces.DNSResolver
 	GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error)
+	GetSRVRecordCtx(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error)
 	GetUserAgent() string
+	GetVerifiedOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload, senderKey *ec.PrivateKey, receiverPubKey string) (*PikePaymentOutputsResponse, error)
+	GetVerifiedOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload, senderKey *ec.PrivateKey, receiverPubKey string) (*PikePaymentOutputsResponse, error)
+	Pay(ctx context.Context, senderKey *ec.PrivateKey, paymailAddress string, satoshis uint64, buildTx PaymentTxBuilder, opts ...PayOps) (*PaymentResult, error)
+	RejectContactRequest(rejectURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error)
+	RejectContactRequestCtx(ctx context.Context, rejectURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error)
 	ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
+	ResolveAddressCtx(ctx context.Context, resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error)
 	SendP2PTransaction(p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
+	SendP2PTransactionCtx(ctx context.Context, p2pURL, alias, domain string, transaction *P2PTransaction) (response *P2PTransactionResponse, err error)
 	ValidateSRVRecord(ctx context.Context, srv *net.SRV, port, priority, weight uint16) error
 	VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
+	VerifyPubKeyCtx(ctx context.Context, verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error)
 	WithCustomHTTPClient(client *resty.Client) ClientInterface
 	WithCustomResolver(resolver interfaces.DNSResolver) ClientInterface
-	AddContactRequest(url, alias, domain string, request *PikeContactRequestPayload) (response *PikeContactRequestResponse, err error)
-	AddInviteRequest(inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error)
-	GetOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error)
 }
@@ -4,6 +4,7 @@ package interfaces
 import (
 	"context"
 	"net"
+	"time"
 )
 
 // DNSResolver is a custom resolver interface for testing
@@ -12,3 +13,10 @@ type DNSResolver interface {
 	LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error)
 	LookupSRV(ctx context.Context, service, proto, name string) (string, []*net.SRV, error)
 }
+
+// SRVTTLResolver is an optional resolver interface that also returns the TTL of the SRV records
+//
+// Used by the paymail client to honour the SRV TTL when a discovery cache is enabled
+type SRVTTLResolver interface {
+	LookupSRVWithTTL(ctx context.Context, service, proto, name string) (string, []*net.SRV, time.Duration, error)
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -53,10 +54,16 @@ type PaymentOutput struct {
 // Specs: https://docs.moneybutton.com/docs/paymail-07-p2p-payment-destination.html
 func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 	paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error) {
+	return c.GetP2PPaymentDestinationCtx(context.Background(), p2pURL, alias, domain, paymentRequest)
+}
+
+// GetP2PPaymentDestinationCtx is the context-aware version of GetP2PPaymentDestination()
+func (c *Client) GetP2PPaymentDestinationCtx(ctx context.Context, p2pURL, alias, domain string,
+	paymentRequest *PaymentRequest) (response *PaymentDestinationResponse, err error) {
 
 	// Require a valid url
 	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", p2pURL)
+		err = invalidURLError(p2pURL)
 		return
 	}
 
@@ -82,7 +89,7 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, paymentRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, paymentRequest); err != nil {
 		return
 	}
 
@@ -92,21 +99,7 @@ func (c *Client) GetP2PPaymentDestination(p2pURL, alias, domain string,
 	// Test the status code
 	if response.StatusCode != http.StatusOK &&
 		response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			err = fmt.Errorf(
-				"bad response from paymail provider: code %d, message: %s",
-				response.StatusCode, serverError.Message,
-			)
-		}
-
+		err = newPaymailProviderError(reqURL, alias, domain, &resp)
 		return
 	}
 
@@ -1,9 +1,9 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 
@@ -58,10 +58,16 @@ type P2PTransactionPayload struct {
 // Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
 func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 	transaction *P2PTransaction) (response *P2PTransactionResponse, err error) {
+	return c.SendP2PTransactionCtx(context.Background(), p2pURL, alias, domain, transaction)
+}
+
+// SendP2PTransactionCtx is the context-aware version of SendP2PTransaction()
+func (c *Client) SendP2PTransactionCtx(ctx context.Context, p2pURL, alias, domain string,
+	transaction *P2PTransaction) (response *P2PTransactionResponse, err error) {
 
 	// Require a valid url
 	if len(p2pURL) == 0 || !strings.Contains(p2pURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", p2pURL)
+		err = invalidURLError(p2pURL)
 		return
 	} else if len(alias) == 0 {
 		err = errors.New("missing alias")
@@ -90,7 +96,7 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, transaction); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, transaction); err != nil {
 		return
 	}
 
@@ -99,22 +105,7 @@ func (c *Client) SendP2PTransaction(p2pURL, alias, domain string,
 
 	// Test the status code
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			if len(serverError.Message) == 0 {
-				err = fmt.Errorf("bad response from paymail provider: code %d, body: %s", response.StatusCode, string(resp.Body))
-			} else {
-				err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
-			}
-		}
-
+		err = newProviderError(reqURL, &resp)
 		return
 	}
 
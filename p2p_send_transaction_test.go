@@ -1,9 +1,11 @@
 package paymail
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/assert"
@@ -97,6 +99,52 @@ func BenchmarkClient_SendP2PTransaction(b *testing.B) {
 	}
 }
 
+// TestClient_SendP2PTransactionCtx will test the method SendP2PTransactionCtx()
+func TestClient_SendP2PTransactionCtx(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	// Create a client with options
+	client := newTestClient(t)
+
+	// Create mock response (fails if the request context is done)
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+"receive-transaction/"+testAlias+"@"+testDomain,
+		func(req *http.Request) (*http.Response, error) {
+			if err := req.Context().Err(); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(
+				http.StatusOK,
+				`{"note":"test note","txid":"f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1"}`,
+			), nil
+		},
+	)
+
+	// Raw TX
+	rawTransaction := &P2PTransaction{
+		Hex:       "some-raw-hex",
+		MetaData:  &P2PMetaData{Note: "test note", Sender: "someone@" + testDomain},
+		Reference: "1234567",
+	}
+
+	// Fire the request
+	transaction, err := client.SendP2PTransactionCtx(
+		context.Background(), testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, rawTransaction,
+	)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, transaction.StatusCode)
+
+	// Fire the request with an expired deadline
+	ctx, cancel := context.WithTimeout(context.Background(), -time.Second)
+	defer cancel()
+
+	transaction, err = client.SendP2PTransactionCtx(
+		ctx, testServerURL+"receive-transaction/{alias}@{domain.tld}", testAlias, testDomain, rawTransaction,
+	)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	require.Nil(t, transaction)
+}
+
 // TestClient_SendP2PTransactionStatusNotModified will test the method SendP2PTransaction()
 func TestClient_SendP2PTransactionStatusNotModified(t *testing.T) {
 	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
@@ -0,0 +1,274 @@
+package paymail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+)
+
+// Payment workflow steps (used in PaymentStep.Name)
+const (
+	PaymentStepSanitize     = "sanitize"     // Sanitizing & validating the receiver paymail
+	PaymentStepSRV          = "srv"          // Fetching the SRV record of the receiver domain
+	PaymentStepCapabilities = "capabilities" // Fetching the capabilities of the receiver provider
+	PaymentStepDestination  = "destination"  // Fetching the outputs (P2P destination or basic address resolution)
+	PaymentStepBuild        = "build"        // Building the transaction (caller supplied)
+	PaymentStepSend         = "send"         // Sending the transaction to the receiver provider
+)
+
+// PaymentTxBuilder builds (and signs) a transaction paying to the given outputs
+//
+// When the BEEF capability is used, the transaction must carry its source transactions
+// and merkle proofs, so it can be serialized using BEEF
+type PaymentTxBuilder func(ctx context.Context, outputs []*PaymentOutput) (*sdk.Transaction, error)
+
+// PayOps allow functional options to be supplied to the Pay() workflow
+type PayOps func(p *payOptions)
+
+// payOptions holds the optional values used in the Pay() workflow
+type payOptions struct {
+	note         string // Note sent in the P2P metadata
+	purpose      string // Purpose used in the basic address resolution
+	senderHandle string // Paymail of the sender
+	senderName   string // Human-readable name of the sender
+}
+
+// WithPaymentSender will set the sender paymail (and optional name) used in the Pay() workflow
+//
+// The sender is required when the receiver only supports basic address resolution
+func WithPaymentSender(handle, name string) PayOps {
+	return func(p *payOptions) {
+		p.senderHandle = handle
+		p.senderName = name
+	}
+}
+
+// WithPaymentNote will set the human-readable note (and purpose) of the payment
+func WithPaymentNote(note string) PayOps {
+	return func(p *payOptions) {
+		p.note = note
+		p.purpose = note
+	}
+}
+
+// PaymentStep is the timing information of a single step in the Pay() workflow
+type PaymentStep struct {
+	Duration time.Duration `json:"duration"` // How long the step took
+	Name     string        `json:"name"`     // Name of the step (see PaymentStep constants)
+}
+
+// PaymentResult is the result of the Pay() workflow
+type PaymentResult struct {
+	Capability  string           `json:"capability"`          // BRFC ID of the capability used to pay
+	Note        string           `json:"note,omitempty"`      // Note returned by the receiver provider
+	Outputs     []*PaymentOutput `json:"outputs"`             // Outputs returned by the receiver provider
+	Reference   string           `json:"reference,omitempty"` // Reference of the payment (P2P only)
+	Sent        bool             `json:"sent"`                // If false, the caller must broadcast the transaction
+	Steps       []*PaymentStep   `json:"steps"`               // Timing of each step
+	Transaction *sdk.Transaction `json:"-"`                   // Transaction built by the PaymentTxBuilder
+	TxID        string           `json:"txid"`                // The txid of the transaction
+}
+
+// track will run the step and record its duration
+func (r *PaymentResult) track(name string, step func() error) error {
+	start := time.Now()
+	err := step()
+	r.Steps = append(r.Steps, &PaymentStep{Duration: time.Since(start), Name: name})
+	return err
+}
+
+// Pay will pay the given amount of satoshis to the paymail address
+//
+// The best capability of the receiver is used: BEEF, then P2P transactions, then basic address resolution.
+// With P2P capabilities the txid is signed with the senderKey and the transaction is sent to the receiver.
+// With basic address resolution the request is signed with the senderKey, and the caller must broadcast the transaction.
+func (c *Client) Pay(ctx context.Context, senderKey *ec.PrivateKey, paymailAddress string, satoshis uint64,
+	buildTx PaymentTxBuilder, opts ...PayOps) (*PaymentResult, error) {
+
+	// Basic requirements for the request
+	if senderKey == nil {
+		return nil, errors.New("missing sender key")
+	} else if satoshis == 0 {
+		return nil, errors.New("satoshis is required")
+	} else if buildTx == nil {
+		return nil, errors.New("missing transaction builder")
+	}
+
+	options := &payOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	result := &PaymentResult{}
+
+	var alias, domain string
+	if err := result.track(PaymentStepSanitize, func() (err error) {
+		var sanitized *SanitisedPaymail
+		if sanitized, err = ValidateAndSanitisePaymail(paymailAddress, false); err != nil {
+			return
+		}
+		alias, domain = sanitized.Alias, sanitized.Domain
+		return
+	}); err != nil {
+		return result, err
+	}
+
+	var srv *net.SRV
+	if err := result.track(PaymentStepSRV, func() (err error) {
+		srv, err = c.GetSRVRecordCtx(ctx, DefaultServiceName, DefaultProtocol, domain)
+		return
+	}); err != nil {
+		return result, err
+	}
+
+	var capabilities *CapabilitiesResponse
+	if err := result.track(PaymentStepCapabilities, func() (err error) {
+		capabilities, err = c.GetCapabilitiesCtx(ctx, srv.Target, int(srv.Port))
+		return
+	}); err != nil {
+		return result, err
+	}
+
+	destinationURL, sendURL := selectPaymentCapability(capabilities, result)
+	if len(result.Capability) == 0 {
+		return result, fmt.Errorf("%w: paymail provider for %s does not support any payment capability", ErrCapabilityMissing, domain)
+	}
+
+	if result.Capability == BRFCPaymentDestination && len(options.senderHandle) == 0 {
+		return result, errors.New("sender handle is required for basic address resolution")
+	}
+
+	if err := result.track(PaymentStepDestination, func() error {
+		return c.fetchPaymentOutputs(ctx, result, senderKey, options, destinationURL, alias, domain, satoshis)
+	}); err != nil {
+		return result, err
+	}
+
+	if err := result.track(PaymentStepBuild, func() (err error) {
+		if result.Transaction, err = buildTx(ctx, result.Outputs); err != nil {
+			return
+		} else if result.Transaction == nil {
+			return errors.New("transaction builder returned an empty transaction")
+		}
+		result.TxID = result.Transaction.TxID().String()
+		return
+	}); err != nil {
+		return result, err
+	}
+
+	// Basic address resolution has no endpoint to receive the transaction
+	if result.Capability == BRFCPaymentDestination {
+		return result, nil
+	}
+
+	if err := result.track(PaymentStepSend, func() error {
+		return c.sendPayment(ctx, result, senderKey, options, sendURL, alias, domain)
+	}); err != nil {
+		return result, err
+	}
+
+	return result, nil
+}
+
+// selectPaymentCapability will pick the best capability to pay with and return its urls
+func selectPaymentCapability(capabilities *CapabilitiesResponse, result *PaymentResult) (destinationURL, sendURL string) {
+	if destinationURL = capabilities.GetString(BRFCP2PPaymentDestination, ""); len(destinationURL) > 0 {
+		if sendURL = capabilities.GetString(BRFCBeefTransaction, ""); len(sendURL) > 0 {
+			result.Capability = BRFCBeefTransaction
+			return
+		}
+		if sendURL = capabilities.GetString(BRFCP2PTransactions, ""); len(sendURL) > 0 {
+			result.Capability = BRFCP2PTransactions
+			return
+		}
+	}
+
+	if destinationURL = capabilities.GetString(BRFCPaymentDestination, BRFCBasicAddressResolution); len(destinationURL) > 0 {
+		result.Capability = BRFCPaymentDestination
+	}
+	return destinationURL, ""
+}
+
+// fetchPaymentOutputs will get the outputs (and reference) from the receiver provider
+func (c *Client) fetchPaymentOutputs(ctx context.Context, result *PaymentResult, senderKey *ec.PrivateKey,
+	options *payOptions, destinationURL, alias, domain string, satoshis uint64) error {
+
+	if result.Capability != BRFCPaymentDestination {
+		destination, err := c.GetP2PPaymentDestinationCtx(
+			ctx, destinationURL, alias, domain, &PaymentRequest{Satoshis: satoshis},
+		)
+		if err != nil {
+			return err
+		}
+		result.Outputs = destination.Outputs
+		result.Reference = destination.Reference
+		return nil
+	}
+
+	senderRequest := &SenderRequest{
+		Amount:       satoshis,
+		Dt:           time.Now().UTC().Format(time.RFC3339),
+		Purpose:      options.purpose,
+		SenderHandle: options.senderHandle,
+		SenderName:   options.senderName,
+	}
+	signature, err := bsm.SignMessage(senderKey, prepareMessage(senderRequest))
+	if err != nil {
+		return err
+	}
+	senderRequest.Signature = EncodeSignature(signature)
+
+	resolution, err := c.ResolveAddressCtx(ctx, destinationURL, alias, domain, senderRequest)
+	if err != nil {
+		return err
+	}
+	result.Outputs = []*PaymentOutput{{
+		Address:  resolution.Address,
+		Satoshis: satoshis,
+		Script:   resolution.Output,
+	}}
+	return nil
+}
+
+// sendPayment will sign the txid and send the transaction to the receiver provider
+func (c *Client) sendPayment(ctx context.Context, result *PaymentResult, senderKey *ec.PrivateKey,
+	options *payOptions, sendURL, alias, domain string) error {
+
+	signature, err := bsm.SignMessage(senderKey, []byte(result.TxID))
+	if err != nil {
+		return err
+	}
+
+	transaction := &P2PTransaction{
+		MetaData: &P2PMetaData{
+			Note:      options.note,
+			PublicKey: senderKey.PubKey().ToDERHex(),
+			Sender:    options.senderHandle,
+			Signature: EncodeSignature(signature),
+		},
+		Reference: result.Reference,
+	}
+
+	if result.Capability == BRFCBeefTransaction {
+		if transaction.Beef, err = result.Transaction.BEEFHex(); err != nil {
+			return err
+		}
+	} else {
+		transaction.Hex = result.Transaction.Hex()
+	}
+
+	response, err := c.SendP2PTransactionCtx(ctx, sendURL, alias, domain, transaction)
+	if err != nil {
+		return err
+	}
+
+	result.Note = response.Note
+	result.Sent = true
+	return nil
+}
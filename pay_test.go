@@ -0,0 +1,242 @@
+package paymail
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/jarcoal/httpmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+)
+
+// TestClient_Pay will test the method Pay()
+func TestClient_Pay(t *testing.T) {
+	// t.Parallel() (Cannot run in parallel - issues with overriding the mock client)
+
+	senderKey, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	t.Run("p2p transaction", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockPayCapabilities(BRFCP2PPaymentDestination, BRFCP2PTransactions)
+		mockPayP2PDestination()
+		var received P2PTransaction
+		mockPaySend("receive-transaction", &received)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, false),
+			WithPaymentSender("sender@"+testDomain, testName), WithPaymentNote(testMessage),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, BRFCP2PTransactions, result.Capability)
+		assert.True(t, result.Sent)
+		assert.Equal(t, "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7", result.Reference)
+		assert.Equal(t, "test note", result.Note)
+		require.Len(t, result.Outputs, 1)
+		assert.Equal(t, uint64(100), result.Outputs[0].Satoshis)
+
+		// Check the steps
+		require.Len(t, result.Steps, 6)
+		assert.Equal(t, PaymentStepSanitize, result.Steps[0].Name)
+		assert.Equal(t, PaymentStepSend, result.Steps[5].Name)
+
+		// Check the sent transaction
+		assert.Equal(t, result.Transaction.Hex(), received.Hex)
+		assert.Empty(t, received.Beef)
+		assert.Equal(t, result.Reference, received.Reference)
+		assert.Equal(t, testMessage, received.MetaData.Note)
+		assert.Equal(t, "sender@"+testDomain, received.MetaData.Sender)
+		assert.Equal(t, senderKey.PubKey().ToDERHex(), received.MetaData.PublicKey)
+
+		// Check the signature of the txid
+		sig, err := DecodeSignature(received.MetaData.Signature)
+		require.NoError(t, err)
+		address, err := script.NewAddressFromPublicKey(senderKey.PubKey(), true)
+		require.NoError(t, err)
+		require.NoError(t, bsm.VerifyMessage(address.AddressString, sig, []byte(result.TxID)))
+	})
+
+	t.Run("beef transaction is preferred", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockPayCapabilities(BRFCP2PPaymentDestination, BRFCP2PTransactions, BRFCBeefTransaction)
+		mockPayP2PDestination()
+		var received P2PTransaction
+		mockPaySend("beef", &received)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, true),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, BRFCBeefTransaction, result.Capability)
+		assert.True(t, result.Sent)
+		assert.Empty(t, received.Hex)
+		assert.NotEmpty(t, received.Beef)
+	})
+
+	t.Run("basic address resolution", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockPayCapabilities(BRFCPaymentDestination)
+		httpmock.RegisterResponder(http.MethodPost, testServerURL+BRFCPaymentDestination+"/"+testAlias+"@"+testDomain,
+			func(req *http.Request) (*http.Response, error) {
+				var senderRequest SenderRequest
+				if err := json.NewDecoder(req.Body).Decode(&senderRequest); err != nil {
+					return nil, err
+				}
+				address, err := script.NewAddressFromPublicKey(senderKey.PubKey(), true)
+				if err != nil {
+					return nil, err
+				}
+				if err = senderRequest.Verify(address.AddressString, senderRequest.Signature); err != nil {
+					return httpmock.NewStringResponse(http.StatusBadRequest, `{"message":"invalid signature"}`), nil
+				}
+				return httpmock.NewStringResponse(http.StatusOK, `{"output":"`+testOutput+`"}`), nil
+			},
+		)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, false),
+			WithPaymentSender("sender@"+testDomain, testName),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, result)
+		assert.Equal(t, BRFCPaymentDestination, result.Capability)
+		assert.False(t, result.Sent)
+		assert.Empty(t, result.Reference)
+		require.Len(t, result.Outputs, 1)
+		assert.Equal(t, testOutput, result.Outputs[0].Script)
+		assert.Equal(t, testAddress, result.Outputs[0].Address)
+		assert.NotEmpty(t, result.TxID)
+	})
+
+	t.Run("basic address resolution - missing sender", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockPayCapabilities(BRFCPaymentDestination)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, false),
+		)
+		require.Error(t, err)
+		require.NotNil(t, result)
+		assert.Len(t, result.Steps, 3)
+	})
+
+	t.Run("no payment capability", func(t *testing.T) {
+		client := newTestClient(t)
+
+		mockPayCapabilities(BRFCPki)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, false),
+		)
+		require.ErrorIs(t, err, ErrCapabilityMissing)
+		require.NotNil(t, result)
+		assert.Empty(t, result.Capability)
+	})
+
+	t.Run("invalid paymail", func(t *testing.T) {
+		client := newTestClient(t)
+
+		result, err := client.Pay(
+			context.Background(), senderKey, "invalid", 100, testPaymentTxBuilder(t, false),
+		)
+		require.Error(t, err)
+		require.NotNil(t, result)
+		require.Len(t, result.Steps, 1)
+		assert.Equal(t, PaymentStepSanitize, result.Steps[0].Name)
+	})
+
+	t.Run("missing parameters", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err = client.Pay(context.Background(), nil, testAlias+"@"+testDomain, 100, testPaymentTxBuilder(t, false))
+		require.Error(t, err)
+
+		_, err = client.Pay(context.Background(), senderKey, testAlias+"@"+testDomain, 0, testPaymentTxBuilder(t, false))
+		require.Error(t, err)
+
+		_, err = client.Pay(context.Background(), senderKey, testAlias+"@"+testDomain, 100, nil)
+		require.Error(t, err)
+	})
+}
+
+// mockPayCapabilities is used for mocking the capabilities with the given BRFC IDs
+func mockPayCapabilities(brfcIDs ...string) {
+	capabilities := make(map[string]interface{})
+	for _, id := range brfcIDs {
+		capabilities[id] = testServerURL + id + "/{alias}@{domain.tld}"
+	}
+	body, _ := json.Marshal(&CapabilitiesPayload{BsvAlias: DefaultBsvAliasVersion, Capabilities: capabilities})
+
+	httpmock.Reset()
+	httpmock.RegisterResponder(http.MethodGet, "https://www."+testDomain+":443/.well-known/"+DefaultServiceName,
+		httpmock.NewBytesResponder(http.StatusOK, body),
+	)
+}
+
+// mockPayP2PDestination is used for mocking the P2P payment destination response
+func mockPayP2PDestination() {
+	httpmock.RegisterResponder(http.MethodPost, testServerURL+BRFCP2PPaymentDestination+"/"+testAlias+"@"+testDomain,
+		httpmock.NewStringResponder(
+			http.StatusOK,
+			`{"outputs": [{"script": "76a9143e2d1d795f8acaa7957045cc59376177eb04a3c588ac","satoshis": 100}],"reference": "z0bac4ec-6f15-42de-9ef4-e60bfdabf4f7"}`,
+		),
+	)
+}
+
+// mockPaySend is used for mocking the receive transaction response (and capturing the request)
+func mockPaySend(capability string, received *P2PTransaction) {
+	url := testServerURL + BRFCP2PTransactions + "/" + testAlias + "@" + testDomain
+	if capability == "beef" {
+		url = testServerURL + BRFCBeefTransaction + "/" + testAlias + "@" + testDomain
+	}
+	httpmock.RegisterResponder(http.MethodPost, url,
+		func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(received); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(
+				http.StatusOK, `{"note":"test note","txid":"f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1"}`,
+			), nil
+		},
+	)
+}
+
+// testPaymentTxBuilder returns a builder making an (unsigned) transaction paying to the outputs
+func testPaymentTxBuilder(t *testing.T, withProof bool) PaymentTxBuilder {
+	return func(_ context.Context, outputs []*PaymentOutput) (*sdk.Transaction, error) {
+		sourceTx := sdk.NewTransaction()
+		require.NoError(t, sourceTx.AddOpReturnOutput([]byte("source")))
+		sourceTx.Outputs[0].Satoshis = 1000
+
+		if withProof {
+			sourceTx.MerklePath = sdk.NewMerklePath(800000, [][]*sdk.PathElement{{
+				{Offset: 0, Hash: sourceTx.TxID(), Txid: func(b bool) *bool { return &b }(true)},
+				{Offset: 1, Duplicate: func(b bool) *bool { return &b }(true)},
+			}})
+		}
+
+		tx := sdk.NewTransaction()
+		tx.AddInputFromTx(sourceTx, 0, nil)
+		for _, out := range outputs {
+			lockingScript, err := script.NewFromHex(out.Script)
+			if err != nil {
+				return nil, err
+			}
+			tx.AddOutput(&sdk.TransactionOutput{LockingScript: lockingScript, Satoshis: out.Satoshis})
+		}
+		return tx, nil
+	}
+}
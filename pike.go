@@ -1,11 +1,19 @@
 package paymail
 
 import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
+
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
 )
 
 // PikeContactRequestResponse is PIKE wrapper for StandardResponse
@@ -14,9 +22,59 @@ type PikeContactRequestResponse struct {
 }
 
 // PikeContactRequestPayload is a payload used to request a contact
+//
+// The request is signed by the PKI key of the requester (Sign), so the receiver can verify
+// that the requester controls the paymail (Verify)
 type PikeContactRequestPayload struct {
-	FullName string `json:"fullName"`
-	Paymail  string `json:"paymail"`
+	FullName  string `json:"fullName"`
+	Nonce     string `json:"nonce,omitempty"`     // Random value making each signed request unique
+	Paymail   string `json:"paymail"`             // Paymail of the requester
+	Signature string `json:"signature,omitempty"` // Compact Bitcoin message signature (base64) by the PKI key of the requester
+	Timestamp string `json:"timestamp,omitempty"` // RFC3339 timestamp of the signed request
+}
+
+// PikeContactStatus is the state of a PIKE contact
+type PikeContactStatus string
+
+// Statuses of a PIKE contact
+const (
+	PikeContactStatusPending   PikeContactStatus = "pending"   // Invitation sent or received, not answered yet
+	PikeContactStatusAccepted  PikeContactStatus = "accepted"  // Invitation accepted by the invited paymail
+	PikeContactStatusRejected  PikeContactStatus = "rejected"  // Invitation rejected by the invited paymail
+	PikeContactStatusConfirmed PikeContactStatus = "confirmed" // Identity keys confirmed by both parties (see ValidatePikeContactCode)
+)
+
+// PikeContactAction is the action of a signed PikeContactResponsePayload
+type PikeContactAction string
+
+// Actions of a PIKE contact response
+const (
+	PikeContactActionAccept PikeContactAction = "accept" // Accept the contact request
+	PikeContactActionReject PikeContactAction = "reject" // Reject the contact request
+	PikeContactActionStatus PikeContactAction = "status" // Ask for the status of the contact
+)
+
+// PikeContactResponsePayload is a payload used to accept or reject a contact request, or to ask for its status
+//
+// The payload is signed by the PKI key of the contact (Sign) for the action, so the receiver can verify
+// that the contact controls the paymail (Verify)
+type PikeContactResponsePayload struct {
+	Nonce     string `json:"nonce,omitempty"`     // Random value making each signed payload unique
+	Paymail   string `json:"paymail"`             // Paymail of the contact (answering the request or asking for the status)
+	Signature string `json:"signature,omitempty"` // Compact Bitcoin message signature (base64) by the PKI key of the contact
+	Timestamp string `json:"timestamp,omitempty"` // RFC3339 timestamp of the signed payload
+}
+
+// PikeContactStatusPayload is the status of a contact
+type PikeContactStatusPayload struct {
+	Paymail string            `json:"paymail"`
+	Status  PikeContactStatus `json:"status"`
+}
+
+// PikeContactStatusResponse is PIKE wrapper for the status of a contact
+type PikeContactStatusResponse struct {
+	StandardResponse
+	PikeContactStatusPayload
 }
 
 // PikePaymentOutputsPayload is a payload needed to get payment outputs
@@ -37,7 +95,15 @@ type OutputTemplate struct {
 	Satoshis uint64 `json:"satoshis"`
 }
 
+// AddContactRequest sends a PIKE contact request to the given paymail
+//
+// The request is signed with the key set by WithPikeSigningKey (unless it is already signed)
 func (c *Client) AddContactRequest(url, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
+	return c.AddContactRequestCtx(context.Background(), url, alias, domain, request)
+}
+
+// AddContactRequestCtx is the context-aware version of AddContactRequest()
+func (c *Client) AddContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
 
 	if err := c.validateUrlWithPaymail(url, alias, domain); err != nil {
 		return nil, err
@@ -47,21 +113,25 @@ func (c *Client) AddContactRequest(url, alias, domain string, request *PikeConta
 		return nil, err
 	}
 
+	if len(request.Signature) == 0 && c.options.pikeSigningKey != nil {
+		signed := *request
+		if err := signed.Sign(c.options.pikeSigningKey, alias+"@"+domain); err != nil {
+			return nil, err
+		}
+		request = &signed
+	}
+
 	// Set the base url and path, assuming the url is from the prior GetCapabilities() request
 	// https://<host-discovery-target>/{alias}@{domain.tld}/id
 	reqURL := replaceAliasDomain(url, alias, domain)
 
-	response, err := c.postRequest(reqURL, request)
+	response, err := c.postRequest(ctx, reqURL, request)
 	if err != nil {
 		return nil, err
 	}
 
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusCreated {
-		if response.StatusCode == http.StatusNotFound {
-			return nil, errors.New("paymail address not found")
-		} else {
-			return nil, c.prepareServerErrorResponse(&response)
-		}
+		return nil, newPaymailProviderError(reqURL, alias, domain, &response)
 	}
 
 	return &PikeContactRequestResponse{response}, nil
@@ -69,7 +139,7 @@ func (c *Client) AddContactRequest(url, alias, domain string, request *PikeConta
 
 func (c *Client) validateUrlWithPaymail(url, alias, domain string) error {
 	if len(url) == 0 || !strings.HasPrefix(url, "https://") {
-		return fmt.Errorf("invalid url: %s", url)
+		return invalidURLError(url)
 	} else if alias == "" {
 		return errors.New("missing alias")
 	} else if domain == "" {
@@ -78,22 +148,20 @@ func (c *Client) validateUrlWithPaymail(url, alias, domain string) error {
 	return nil
 }
 
-func (c *Client) prepareServerErrorResponse(response *StandardResponse) error {
-	var details string
-
-	serverError := &ServerError{}
-	if err := json.Unmarshal(response.Body, serverError); err != nil || serverError.Message == "" {
-		details = fmt.Sprintf("body: %s", string(response.Body))
-	} else {
-		details = fmt.Sprintf("message: %s", serverError.Message)
+func (r *PikeContactRequestPayload) validate() error {
+	if r.FullName == "" {
+		return errors.New("missing full name")
+	}
+	if r.Paymail == "" {
+		return errors.New("missing paymail address")
 	}
 
-	return fmt.Errorf("bad response from paymail provider: code %d, %s", response.StatusCode, details)
+	return ValidatePaymail(r.Paymail)
 }
 
-func (r *PikeContactRequestPayload) validate() error {
-	if r.FullName == "" {
-		return errors.New("missing full name")
+func (r *PikeContactResponsePayload) validate() error {
+	if r == nil {
+		return errors.New("payload cannot be nil")
 	}
 	if r.Paymail == "" {
 		return errors.New("missing paymail address")
@@ -102,11 +170,124 @@ func (r *PikeContactRequestPayload) validate() error {
 	return ValidatePaymail(r.Paymail)
 }
 
+// Sign will sign the contact request for the receiver with the PKI key of the requester
+//
+// The timestamp (now) and the nonce (random) are set if they are empty
+func (r *PikeContactRequestPayload) Sign(privateKey *ec.PrivateKey, receiverPaymail string) error {
+	if privateKey == nil {
+		return errors.New("missing private key")
+	} else if len(receiverPaymail) == 0 {
+		return errors.New("missing receiver paymail")
+	}
+
+	if err := setSignedTimeAndNonce(&r.Timestamp, &r.Nonce); err != nil {
+		return err
+	}
+
+	sig, err := bsm.SignMessage(privateKey, r.signedMessage(receiverPaymail))
+	if err != nil {
+		return err
+	}
+	r.Signature = EncodeSignature(sig)
+	return nil
+}
+
+// Verify will verify the signature of the contact request for the receiver with the PKI key of the requester
+func (r *PikeContactRequestPayload) Verify(pubKey *ec.PublicKey, receiverPaymail string) error {
+	if pubKey == nil {
+		return errors.New("missing public key")
+	} else if len(r.Signature) == 0 {
+		return errors.New("missing a signature to verify")
+	}
+
+	return verifySignedMessage(pubKey, r.Signature, r.signedMessage(receiverPaymail))
+}
+
+// signedMessage is the message signed by the requester, the full name is last (the only field which can contain a new line)
+func (r *PikeContactRequestPayload) signedMessage(receiverPaymail string) []byte {
+	_, _, receiverPaymail = SanitizePaymail(receiverPaymail)
+	return []byte(strings.Join([]string{receiverPaymail, r.Paymail, r.Timestamp, r.Nonce, r.FullName}, "\n"))
+}
+
+// Sign will sign the action for the receiver (the paymail which sent the contact request) with the PKI key of the contact
+//
+// The timestamp (now) and the nonce (random) are set if they are empty
+func (r *PikeContactResponsePayload) Sign(privateKey *ec.PrivateKey, action PikeContactAction, receiverPaymail string) error {
+	if privateKey == nil {
+		return errors.New("missing private key")
+	} else if len(action) == 0 {
+		return errors.New("missing action")
+	} else if len(receiverPaymail) == 0 {
+		return errors.New("missing receiver paymail")
+	}
+
+	if err := setSignedTimeAndNonce(&r.Timestamp, &r.Nonce); err != nil {
+		return err
+	}
+
+	sig, err := bsm.SignMessage(privateKey, r.signedMessage(action, receiverPaymail))
+	if err != nil {
+		return err
+	}
+	r.Signature = EncodeSignature(sig)
+	return nil
+}
+
+// Verify will verify the signature of the action for the receiver with the PKI key of the contact
+func (r *PikeContactResponsePayload) Verify(pubKey *ec.PublicKey, action PikeContactAction, receiverPaymail string) error {
+	if pubKey == nil {
+		return errors.New("missing public key")
+	} else if len(r.Signature) == 0 {
+		return errors.New("missing a signature to verify")
+	}
+	return verifySignedMessage(pubKey, r.Signature, r.signedMessage(action, receiverPaymail))
+}
+
+// signedMessage is the message signed by the contact (the action cannot be replayed as another action)
+func (r *PikeContactResponsePayload) signedMessage(action PikeContactAction, receiverPaymail string) []byte {
+	_, _, receiverPaymail = SanitizePaymail(receiverPaymail)
+	return []byte(strings.Join([]string{receiverPaymail, string(action), r.Paymail, r.Timestamp, r.Nonce}, "\n"))
+}
+
+// setSignedTimeAndNonce will set the timestamp (now) and the nonce (random) of a signed payload if they are empty
+func setSignedTimeAndNonce(timestamp, nonce *string) error {
+	if len(*timestamp) == 0 {
+		*timestamp = time.Now().UTC().Format(time.RFC3339)
+	}
+	if len(*nonce) == 0 {
+		value := make([]byte, 16)
+		if _, err := rand.Read(value); err != nil {
+			return fmt.Errorf("failed to generate the nonce: %w", err)
+		}
+		*nonce = hex.EncodeToString(value)
+	}
+	return nil
+}
+
+// verifySignedMessage will verify the compact signature (base64) of the message with the public key
+func verifySignedMessage(pubKey *ec.PublicKey, signature string, message []byte) error {
+	sig, err := DecodeSignature(signature)
+	if err != nil {
+		return err
+	}
+
+	address, err := script.NewAddressFromPublicKey(pubKey, true)
+	if err != nil {
+		return err
+	}
+	return bsm.VerifyMessage(address.AddressString, sig, message)
+}
+
 // GetOutputsTemplate calls the PIKE capability outputs subcapability
 func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error) {
+	return c.GetOutputsTemplateCtx(context.Background(), pikeURL, alias, domain, payload)
+}
+
+// GetOutputsTemplateCtx is the context-aware version of GetOutputsTemplate()
+func (c *Client) GetOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload) (response *PikePaymentOutputsResponse, err error) {
 	// Require a valid URL
 	if len(pikeURL) == 0 || !strings.Contains(pikeURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", pikeURL)
+		err = invalidURLError(pikeURL)
 		return
 	}
 
@@ -130,13 +311,13 @@ func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *Pike
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, payload); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, payload); err != nil {
 		return
 	}
 
 	// Test the status code
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("bad response from PIKE outputs: code %d", resp.StatusCode)
+		return nil, newPaymailProviderError(reqURL, alias, domain, &resp)
 	}
 
 	// Decode the body of the response
@@ -148,7 +329,161 @@ func (c *Client) GetOutputsTemplate(pikeURL, alias, domain string, payload *Pike
 	return outputs, nil
 }
 
+// GetVerifiedOutputsTemplate calls the PIKE outputs subcapability and verifies that the output scripts
+// are derived from the receiver PKI key and the sender key (see VerifyPikeOutputs)
+//
+// The sender key is the PKI key of payload.SenderPaymail (the receiver derives the outputs with its public key)
+func (c *Client) GetVerifiedOutputsTemplate(pikeURL, alias, domain string, payload *PikePaymentOutputsPayload,
+	senderKey *ec.PrivateKey, receiverPubKey string,
+) (*PikePaymentOutputsResponse, error) {
+	return c.GetVerifiedOutputsTemplateCtx(context.Background(), pikeURL, alias, domain, payload, senderKey, receiverPubKey)
+}
+
+// GetVerifiedOutputsTemplateCtx is the context-aware version of GetVerifiedOutputsTemplate()
+func (c *Client) GetVerifiedOutputsTemplateCtx(ctx context.Context, pikeURL, alias, domain string, payload *PikePaymentOutputsPayload,
+	senderKey *ec.PrivateKey, receiverPubKey string,
+) (*PikePaymentOutputsResponse, error) {
+	outputs, err := c.GetOutputsTemplateCtx(ctx, pikeURL, alias, domain, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = VerifyPikeOutputs(senderKey, receiverPubKey, outputs); err != nil {
+		return nil, err
+	}
+	return outputs, nil
+}
+
+// AddSignedContactRequest signs the PIKE contact request with the PKI key of the requester and sends it to the given paymail
+func (c *Client) AddSignedContactRequest(url, alias, domain string, request *PikeContactRequestPayload, privateKey *ec.PrivateKey) (*PikeContactRequestResponse, error) {
+	return c.AddSignedContactRequestCtx(context.Background(), url, alias, domain, request, privateKey)
+}
+
+// AddSignedContactRequestCtx is the context-aware version of AddSignedContactRequest()
+func (c *Client) AddSignedContactRequestCtx(ctx context.Context, url, alias, domain string, request *PikeContactRequestPayload, privateKey *ec.PrivateKey) (*PikeContactRequestResponse, error) {
+	if request == nil {
+		return nil, errors.New("payload cannot be nil")
+	}
+
+	signed := *request
+	if err := signed.Sign(privateKey, alias+"@"+domain); err != nil {
+		return nil, err
+	}
+	return c.AddContactRequestCtx(ctx, url, alias, domain, &signed)
+}
+
 // AddInviteRequest sends a contact request using the invite URL from capabilities
 func (c *Client) AddInviteRequest(inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
-	return c.AddContactRequest(inviteURL, alias, domain, request)
+	return c.AddInviteRequestCtx(context.Background(), inviteURL, alias, domain, request)
+}
+
+// AddInviteRequestCtx is the context-aware version of AddInviteRequest()
+func (c *Client) AddInviteRequestCtx(ctx context.Context, inviteURL, alias, domain string, request *PikeContactRequestPayload) (*PikeContactRequestResponse, error) {
+	return c.AddContactRequestCtx(ctx, inviteURL, alias, domain, request)
+}
+
+// AcceptContactRequest notifies the requester (alias@domain) that the contact accepted the contact request
+//
+// The answer is signed with the key set by WithPikeSigningKey (unless it is already signed)
+func (c *Client) AcceptContactRequest(acceptURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error) {
+	return c.AcceptContactRequestCtx(context.Background(), acceptURL, alias, domain, request)
+}
+
+// AcceptContactRequestCtx is the context-aware version of AcceptContactRequest()
+func (c *Client) AcceptContactRequestCtx(ctx context.Context, acceptURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error) {
+	return c.answerContactRequest(ctx, acceptURL, alias, domain, PikeContactActionAccept, request)
+}
+
+// RejectContactRequest notifies the requester (alias@domain) that the contact rejected the contact request
+//
+// The answer is signed with the key set by WithPikeSigningKey (unless it is already signed)
+func (c *Client) RejectContactRequest(rejectURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error) {
+	return c.RejectContactRequestCtx(context.Background(), rejectURL, alias, domain, request)
+}
+
+// RejectContactRequestCtx is the context-aware version of RejectContactRequest()
+func (c *Client) RejectContactRequestCtx(ctx context.Context, rejectURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactRequestResponse, error) {
+	return c.answerContactRequest(ctx, rejectURL, alias, domain, PikeContactActionReject, request)
+}
+
+// answerContactRequest will send the answer (accept or reject) to the requester
+func (c *Client) answerContactRequest(ctx context.Context, url, alias, domain string, action PikeContactAction,
+	request *PikeContactResponsePayload,
+) (*PikeContactRequestResponse, error) {
+	if err := c.validateUrlWithPaymail(url, alias, domain); err != nil {
+		return nil, err
+	}
+
+	request, err := c.signContactResponse(request, action, alias, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := replaceAliasDomain(url, alias, domain)
+
+	response, err := c.postRequest(ctx, reqURL, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode != http.StatusOK {
+		return nil, newPaymailProviderError(reqURL, alias, domain, &response)
+	}
+
+	return &PikeContactRequestResponse{response}, nil
+}
+
+// signContactResponse will validate the payload and return a copy signed with the key set by WithPikeSigningKey
+// (the payload is returned as is if it is already signed or if there is no key)
+func (c *Client) signContactResponse(request *PikeContactResponsePayload, action PikeContactAction,
+	alias, domain string,
+) (*PikeContactResponsePayload, error) {
+	if err := request.validate(); err != nil {
+		return nil, err
+	} else if len(request.Signature) > 0 || c.options.pikeSigningKey == nil {
+		return request, nil
+	}
+
+	signed := *request
+	if err := signed.Sign(c.options.pikeSigningKey, action, alias+"@"+domain); err != nil {
+		return nil, err
+	}
+	return &signed, nil
+}
+
+// GetContactStatus returns the status of the contact (request.Paymail) at the paymail alias@domain
+//
+// The request is signed with the key set by WithPikeSigningKey (unless it is already signed)
+func (c *Client) GetContactStatus(statusURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactStatusResponse, error) {
+	return c.GetContactStatusCtx(context.Background(), statusURL, alias, domain, request)
+}
+
+// GetContactStatusCtx is the context-aware version of GetContactStatus()
+func (c *Client) GetContactStatusCtx(ctx context.Context, statusURL, alias, domain string, request *PikeContactResponsePayload) (*PikeContactStatusResponse, error) {
+	if err := c.validateUrlWithPaymail(statusURL, alias, domain); err != nil {
+		return nil, err
+	}
+
+	request, err := c.signContactResponse(request, PikeContactActionStatus, alias, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	reqURL := replaceAliasDomain(statusURL, alias, domain)
+
+	resp, err := c.postRequest(ctx, reqURL, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, newPaymailProviderError(reqURL, alias, domain, &resp)
+	}
+
+	response := &PikeContactStatusResponse{StandardResponse: resp}
+	if err = json.Unmarshal(resp.Body, &response.PikeContactStatusPayload); err != nil {
+		return nil, err
+	}
+
+	return response, nil
 }
@@ -0,0 +1,145 @@
+package paymail
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+)
+
+// PikeDerivationProtocol is the BRC-43 protocol of the PIKE outputs (invoice number "2-paymail pike-<reference>-<index>")
+const PikeDerivationProtocol = "paymail pike"
+
+// ErrPikeOutputNotDerived is when an output script of the PIKE outputs is not derived from the shared secret
+var ErrPikeOutputNotDerived = errors.New("output script is not derived from the shared secret of the sender and receiver")
+
+// PikeOutputsDeriver derives the PIKE output templates of the receiver (BRC-42)
+//
+// Each output pays a P2PKH of a key derived from the identity key of the receiver, the PKI key
+// of the sender and the reference, so the outputs are unlinkable and deterministic:
+// the receiver derives the private keys and the sender can verify the scripts (VerifyPikeOutputs)
+type PikeOutputsDeriver struct {
+	privateKey *ec.PrivateKey
+}
+
+// NewPikeOutputsDeriver will return the deriver of the receiver identity key
+func NewPikeOutputsDeriver(privateKey *ec.PrivateKey) (*PikeOutputsDeriver, error) {
+	if privateKey == nil {
+		return nil, errors.New("missing private key")
+	}
+	return &PikeOutputsDeriver{privateKey: privateKey}, nil
+}
+
+// NewPikeOutputsDeriverFromXPriv will return the deriver of the receiver xpriv (its key is the identity key)
+func NewPikeOutputsDeriverFromXPriv(xPriv string) (*PikeOutputsDeriver, error) {
+	key, err := bip32.NewKeyFromString(xPriv)
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpriv: %w", err)
+	}
+
+	privateKey, err := key.ECPrivKey()
+	if err != nil {
+		return nil, fmt.Errorf("invalid xpriv: %w", err)
+	}
+	return NewPikeOutputsDeriver(privateKey)
+}
+
+// CreateOutputs will return a single output template paying the satoshis to the key derived for the sender
+// and the reference (a random reference is generated if it is empty)
+func (d *PikeOutputsDeriver) CreateOutputs(senderPubKey, reference string, satoshis uint64) (*PikePaymentOutputsResponse, error) {
+	pubKey, err := ec.PublicKeyFromString(senderPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender pubkey: %w", err)
+	}
+
+	if len(reference) == 0 {
+		if reference, err = newPikeReference(); err != nil {
+			return nil, err
+		}
+	}
+
+	childKey, err := d.DerivePrivateKey(pubKey, reference, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	lockingScript, err := pikeLockingScript(childKey.PubKey())
+	if err != nil {
+		return nil, err
+	}
+
+	return &PikePaymentOutputsResponse{
+		Outputs:   []*OutputTemplate{{Satoshis: satoshis, Script: lockingScript.String()}},
+		Reference: reference,
+	}, nil
+}
+
+// DerivePrivateKey will return the private key of the output at the index (to spend the received output)
+func (d *PikeOutputsDeriver) DerivePrivateKey(senderPubKey *ec.PublicKey, reference string, index int) (*ec.PrivateKey, error) {
+	childKey, err := d.privateKey.DeriveChild(senderPubKey, pikeInvoiceNumber(reference, index))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the key: %w", err)
+	}
+	return childKey, nil
+}
+
+// VerifyPikeOutputs will verify that the output scripts are derived from the receiver PKI key,
+// the sender key and the reference (see PikeOutputsDeriver)
+//
+// Returns ErrPikeOutputNotDerived if any of the scripts is not derived
+func VerifyPikeOutputs(senderKey *ec.PrivateKey, receiverPubKey string, response *PikePaymentOutputsResponse) error {
+	if senderKey == nil {
+		return errors.New("missing private key")
+	} else if response == nil || len(response.Outputs) == 0 {
+		return errors.New("missing outputs")
+	}
+
+	pubKey, err := ec.PublicKeyFromString(receiverPubKey)
+	if err != nil {
+		return fmt.Errorf("invalid receiver pubkey: %w", err)
+	}
+
+	for index, output := range response.Outputs {
+		childPubKey, err := pubKey.DeriveChild(senderKey, pikeInvoiceNumber(response.Reference, index))
+		if err != nil {
+			return fmt.Errorf("failed to derive the key: %w", err)
+		}
+
+		lockingScript, err := pikeLockingScript(childPubKey)
+		if err != nil {
+			return err
+		}
+		if output == nil || output.Script != lockingScript.String() {
+			return fmt.Errorf("%w: output %d", ErrPikeOutputNotDerived, index)
+		}
+	}
+	return nil
+}
+
+// pikeInvoiceNumber is the BRC-43 invoice number of the output at the index
+func pikeInvoiceNumber(reference string, index int) string {
+	return fmt.Sprintf("2-%s-%s-%d", PikeDerivationProtocol, reference, index)
+}
+
+// pikeLockingScript will return the P2PKH locking script of the key
+func pikeLockingScript(pubKey *ec.PublicKey) (*script.Script, error) {
+	address, err := script.NewAddressFromPublicKey(pubKey, true)
+	if err != nil {
+		return nil, err
+	}
+	return p2pkh.Lock(address)
+}
+
+// newPikeReference will return a random reference
+func newPikeReference() (string, error) {
+	reference := make([]byte, 16)
+	if _, err := rand.Read(reference); err != nil {
+		return "", fmt.Errorf("failed to generate the reference: %w", err)
+	}
+	return hex.EncodeToString(reference), nil
+}
@@ -0,0 +1,121 @@
+package paymail
+
+import (
+	"testing"
+
+	bip32 "github.com/bitcoin-sv/go-sdk/compat/bip32"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPikeOutputsDeriver will test the methods of the PikeOutputsDeriver and VerifyPikeOutputs()
+func TestPikeOutputsDeriver(t *testing.T) {
+	t.Parallel()
+
+	receiver, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	sender, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	senderPubKey := sender.PubKey().ToDERHex()
+	receiverPubKey := receiver.PubKey().ToDERHex()
+
+	deriver, err := NewPikeOutputsDeriver(receiver)
+	require.NoError(t, err)
+
+	t.Run("outputs are verified by the sender", func(t *testing.T) {
+		response, err := deriver.CreateOutputs(senderPubKey, "", 1000)
+		require.NoError(t, err)
+		require.Len(t, response.Outputs, 1)
+		assert.Len(t, response.Reference, 32)
+		assert.Equal(t, uint64(1000), response.Outputs[0].Satoshis)
+
+		require.NoError(t, VerifyPikeOutputs(sender, receiverPubKey, response))
+	})
+
+	t.Run("deterministic per sender and reference", func(t *testing.T) {
+		first, err := deriver.CreateOutputs(senderPubKey, "ref-1", 1000)
+		require.NoError(t, err)
+		second, err := deriver.CreateOutputs(senderPubKey, "ref-1", 1000)
+		require.NoError(t, err)
+		assert.Equal(t, first, second)
+
+		otherReference, err := deriver.CreateOutputs(senderPubKey, "ref-2", 1000)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Outputs[0].Script, otherReference.Outputs[0].Script)
+
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+		otherSender, err := deriver.CreateOutputs(other.PubKey().ToDERHex(), "ref-1", 1000)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Outputs[0].Script, otherSender.Outputs[0].Script)
+	})
+
+	t.Run("derived private key spends the output", func(t *testing.T) {
+		response, err := deriver.CreateOutputs(senderPubKey, "ref-1", 1000)
+		require.NoError(t, err)
+
+		childKey, err := deriver.DerivePrivateKey(sender.PubKey(), "ref-1", 0)
+		require.NoError(t, err)
+		lockingScript, err := pikeLockingScript(childKey.PubKey())
+		require.NoError(t, err)
+		assert.Equal(t, lockingScript.String(), response.Outputs[0].Script)
+	})
+
+	t.Run("outputs not derived for the sender", func(t *testing.T) {
+		response, err := deriver.CreateOutputs(senderPubKey, "ref-1", 1000)
+		require.NoError(t, err)
+
+		tampered := &PikePaymentOutputsResponse{
+			Outputs:   []*OutputTemplate{{Satoshis: 1000, Script: "76a914" + "00000000000000000000000000000000000000ff" + "88ac"}},
+			Reference: response.Reference,
+		}
+		require.ErrorIs(t, VerifyPikeOutputs(sender, receiverPubKey, tampered), ErrPikeOutputNotDerived)
+
+		otherReference := &PikePaymentOutputsResponse{Outputs: response.Outputs, Reference: "ref-2"}
+		require.ErrorIs(t, VerifyPikeOutputs(sender, receiverPubKey, otherReference), ErrPikeOutputNotDerived)
+
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+		require.ErrorIs(t, VerifyPikeOutputs(other, receiverPubKey, response), ErrPikeOutputNotDerived)
+		require.ErrorIs(t, VerifyPikeOutputs(sender, other.PubKey().ToDERHex(), response), ErrPikeOutputNotDerived)
+	})
+
+	t.Run("invalid values", func(t *testing.T) {
+		_, err := NewPikeOutputsDeriver(nil)
+		require.Error(t, err)
+
+		_, err = deriver.CreateOutputs("invalid", "", 1000)
+		require.Error(t, err)
+
+		require.Error(t, VerifyPikeOutputs(nil, receiverPubKey, &PikePaymentOutputsResponse{}))
+		require.Error(t, VerifyPikeOutputs(sender, receiverPubKey, nil))
+		require.Error(t, VerifyPikeOutputs(sender, receiverPubKey, &PikePaymentOutputsResponse{}))
+		require.Error(t, VerifyPikeOutputs(sender, "invalid", &PikePaymentOutputsResponse{Outputs: []*OutputTemplate{{}}}))
+	})
+}
+
+// TestNewPikeOutputsDeriverFromXPriv will test the method NewPikeOutputsDeriverFromXPriv()
+func TestNewPikeOutputsDeriverFromXPriv(t *testing.T) {
+	t.Parallel()
+
+	t.Run("valid xpriv", func(t *testing.T) {
+		xPriv, err := bip32.GenerateHDKey(bip32.RecommendedSeedLen)
+		require.NoError(t, err)
+		key, err := xPriv.ECPrivKey()
+		require.NoError(t, err)
+		sender, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		deriver, err := NewPikeOutputsDeriverFromXPriv(xPriv.String())
+		require.NoError(t, err)
+		response, err := deriver.CreateOutputs(sender.PubKey().ToDERHex(), "ref-1", 1000)
+		require.NoError(t, err)
+		require.NoError(t, VerifyPikeOutputs(sender, key.PubKey().ToDERHex(), response))
+	})
+
+	t.Run("invalid xpriv", func(t *testing.T) {
+		_, err := NewPikeOutputsDeriverFromXPriv("invalid")
+		require.Error(t, err)
+	})
+}
@@ -1,11 +1,13 @@
 package paymail
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
 	"testing"
 
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
 	"github.com/jarcoal/httpmock"
 	"github.com/stretchr/testify/require"
 )
@@ -82,6 +84,46 @@ func TestClient_GetOutputsTemplate(t *testing.T) {
 	})
 }
 
+// TestClient_GetVerifiedOutputsTemplate will test the method GetVerifiedOutputsTemplate()
+func TestClient_GetVerifiedOutputsTemplate(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := newTestClient(t)
+	outputsURL := "https://" + testDomain + "/v1/bsvalias/pike/outputs/{alias}@{domain.tld}"
+	payload := &PikePaymentOutputsPayload{SenderPaymail: "joedoe@example.com", Amount: 1000}
+
+	receiver, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	sender, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	deriver, err := NewPikeOutputsDeriver(receiver)
+	require.NoError(t, err)
+	outputs, err := deriver.CreateOutputs(sender.PubKey().ToDERHex(), "", payload.Amount)
+	require.NoError(t, err)
+
+	httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/pike/outputs/alias@domain.tld",
+		httpmock.NewJsonResponderOrPanic(http.StatusOK, outputs),
+	)
+
+	t.Run("outputs derived for the sender", func(t *testing.T) {
+		response, err := client.GetVerifiedOutputsTemplate(outputsURL, "alias", "domain.tld", payload,
+			sender, receiver.PubKey().ToDERHex())
+		require.NoError(t, err)
+		require.Equal(t, outputs, response)
+	})
+
+	t.Run("outputs not derived for the sender", func(t *testing.T) {
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		response, err := client.GetVerifiedOutputsTemplate(outputsURL, "alias", "domain.tld", payload,
+			other, receiver.PubKey().ToDERHex())
+		require.ErrorIs(t, err, ErrPikeOutputNotDerived)
+		require.Nil(t, response)
+	})
+}
+
 // mockPIKEOutputs is used for mocking the PIKE outputs response
 func mockPIKEOutputs(statusCode int, amount uint64) {
 	httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/pike/outputs/alias@domain.tld",
@@ -100,6 +142,299 @@ func mockPIKEOutputs(statusCode int, amount uint64) {
 	)
 }
 
+// TestPikeContactRequestPayload_Sign will test the methods Sign() and Verify()
+func TestPikeContactRequestPayload_Sign(t *testing.T) {
+	t.Parallel()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	t.Run("sign and verify", func(t *testing.T) {
+		request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+		require.NoError(t, request.Sign(key, "alias@domain.tld"))
+		require.NotEmpty(t, request.Nonce)
+		require.NoError(t, ValidateTimestamp(request.Timestamp))
+
+		require.NoError(t, request.Verify(key.PubKey(), "alias@domain.tld"))
+		require.NoError(t, request.Verify(key.PubKey(), "Alias@Domain.tld"), "the receiver is sanitized")
+	})
+
+	t.Run("tampered request", func(t *testing.T) {
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+		require.NoError(t, request.Sign(key, "alias@domain.tld"))
+
+		require.Error(t, request.Verify(other.PubKey(), "alias@domain.tld"), "other key")
+		require.Error(t, request.Verify(key.PubKey(), "other@domain.tld"), "other receiver")
+
+		tampered := *request
+		tampered.FullName = "Jane Doe"
+		require.Error(t, tampered.Verify(key.PubKey(), "alias@domain.tld"))
+
+		tampered = *request
+		tampered.Nonce = "00"
+		require.Error(t, tampered.Verify(key.PubKey(), "alias@domain.tld"))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+		require.Error(t, request.Sign(nil, "alias@domain.tld"))
+		require.Error(t, request.Sign(key, ""))
+		require.Error(t, request.Verify(key.PubKey(), "alias@domain.tld"), "not signed")
+		require.Error(t, request.Verify(nil, "alias@domain.tld"))
+	})
+}
+
+// TestPikeContactResponsePayload_Sign will test the methods Sign() and Verify() of the contact response
+func TestPikeContactResponsePayload_Sign(t *testing.T) {
+	t.Parallel()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	t.Run("sign and verify", func(t *testing.T) {
+		response := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+		require.NoError(t, response.Sign(key, PikeContactActionAccept, "alias@domain.tld"))
+		require.NotEmpty(t, response.Nonce)
+		require.NoError(t, ValidateTimestamp(response.Timestamp))
+
+		require.NoError(t, response.Verify(key.PubKey(), PikeContactActionAccept, "Alias@Domain.tld"))
+	})
+
+	t.Run("tampered response", func(t *testing.T) {
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		response := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+		require.NoError(t, response.Sign(key, PikeContactActionAccept, "alias@domain.tld"))
+
+		require.Error(t, response.Verify(other.PubKey(), PikeContactActionAccept, "alias@domain.tld"), "other key")
+		require.Error(t, response.Verify(key.PubKey(), PikeContactActionReject, "alias@domain.tld"), "other action")
+		require.Error(t, response.Verify(key.PubKey(), PikeContactActionAccept, "other@domain.tld"), "other receiver")
+
+		tampered := *response
+		tampered.Paymail = "janedoe@example.com"
+		require.Error(t, tampered.Verify(key.PubKey(), PikeContactActionAccept, "alias@domain.tld"))
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		response := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+		require.Error(t, response.Sign(nil, PikeContactActionAccept, "alias@domain.tld"))
+		require.Error(t, response.Sign(key, "", "alias@domain.tld"))
+		require.Error(t, response.Sign(key, PikeContactActionAccept, ""))
+		require.Error(t, response.Verify(key.PubKey(), PikeContactActionAccept, "alias@domain.tld"), "not signed")
+	})
+}
+
+// TestClient_AddSignedContactRequest will test the method AddSignedContactRequest()
+func TestClient_AddSignedContactRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := newTestClient(t)
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var received PikeContactRequestPayload
+	httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/invite/alias@domain.tld",
+		func(req *http.Request) (*http.Response, error) {
+			if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(http.StatusCreated, ""), nil
+		},
+	)
+
+	inviteURL := "https://" + testDomain + "/v1/bsvalias/contact/invite/{alias}@{domain.tld}"
+	request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+	response, err := client.AddSignedContactRequest(inviteURL, "alias", "domain.tld", request, key)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, response.StatusCode)
+
+	require.Empty(t, request.Signature, "the request is not modified")
+	require.NotEmpty(t, received.Signature)
+	require.NoError(t, received.Verify(key.PubKey(), "alias@domain.tld"))
+
+	_, err = client.AddSignedContactRequest(inviteURL, "alias", "domain.tld", request, nil)
+	require.Error(t, err)
+}
+
+// TestClient_AddContactRequest will test signing the contact requests with the key of the client
+func TestClient_AddContactRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	var received PikeContactRequestPayload
+	httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/invite/alias@domain.tld",
+		func(req *http.Request) (*http.Response, error) {
+			received = PikeContactRequestPayload{}
+			if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+				return nil, err
+			}
+			return httpmock.NewStringResponse(http.StatusCreated, ""), nil
+		},
+	)
+	inviteURL := "https://" + testDomain + "/v1/bsvalias/contact/invite/{alias}@{domain.tld}"
+
+	t.Run("signed with the client key", func(t *testing.T) {
+		client := newTestClient(t, WithPikeSigningKey(key))
+		request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+
+		_, err := client.AddContactRequest(inviteURL, "alias", "domain.tld", request)
+		require.NoError(t, err)
+		require.Empty(t, request.Signature, "the request is not modified")
+		require.NoError(t, received.Verify(key.PubKey(), "alias@domain.tld"))
+
+		_, err = client.AddInviteRequest(inviteURL, "alias", "domain.tld", request)
+		require.NoError(t, err)
+		require.NoError(t, received.Verify(key.PubKey(), "alias@domain.tld"))
+	})
+
+	t.Run("signed requests are not signed again", func(t *testing.T) {
+		other, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+		client := newTestClient(t, WithPikeSigningKey(key))
+		request := &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"}
+		require.NoError(t, request.Sign(other, "alias@domain.tld"))
+
+		_, err = client.AddContactRequest(inviteURL, "alias", "domain.tld", request)
+		require.NoError(t, err)
+		require.Equal(t, request.Signature, received.Signature)
+	})
+
+	t.Run("not signed without a key", func(t *testing.T) {
+		client := newTestClient(t)
+
+		_, err := client.AddContactRequest(inviteURL, "alias", "domain.tld", &PikeContactRequestPayload{FullName: "John Doe", Paymail: "johndoe@example.com"})
+		require.NoError(t, err)
+		require.Empty(t, received.Signature)
+	})
+}
+
+// TestClient_AnswerContactRequest will test the methods AcceptContactRequest() and RejectContactRequest()
+func TestClient_AnswerContactRequest(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := newTestClient(t)
+	request := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+
+	t.Run("accept", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/accept/alias@domain.tld",
+			httpmock.NewStringResponder(http.StatusOK, ""),
+		)
+
+		acceptURL := "https://" + testDomain + "/v1/bsvalias/contact/accept/{alias}@{domain.tld}"
+		response, err := client.AcceptContactRequest(acceptURL, "alias", "domain.tld", request)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, response.StatusCode)
+	})
+
+	t.Run("reject error", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/reject/alias@domain.tld",
+			httpmock.NewStringResponder(http.StatusConflict, `{"message": "contact status cannot be changed"}`),
+		)
+
+		rejectURL := "https://" + testDomain + "/v1/bsvalias/contact/reject/{alias}@{domain.tld}"
+		response, err := client.RejectContactRequest(rejectURL, "alias", "domain.tld", request)
+		require.Error(t, err)
+		require.Nil(t, response)
+	})
+
+	t.Run("invalid request", func(t *testing.T) {
+		acceptURL := "https://" + testDomain + "/v1/bsvalias/contact/accept/{alias}@{domain.tld}"
+		_, err := client.AcceptContactRequest(acceptURL, "alias", "domain.tld", &PikeContactResponsePayload{})
+		require.Error(t, err)
+
+		_, err = client.AcceptContactRequest(acceptURL, "alias", "domain.tld", nil)
+		require.Error(t, err)
+
+		_, err = client.RejectContactRequest("http://"+testDomain, "alias", "domain.tld", request)
+		require.Error(t, err)
+	})
+}
+
+// TestClient_SignedContactResponses will test signing the answers and status requests with the key of the client
+func TestClient_SignedContactResponses(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	client := newTestClient(t, WithPikeSigningKey(key))
+
+	var received PikeContactResponsePayload
+	for _, action := range []PikeContactAction{PikeContactActionAccept, PikeContactActionReject, PikeContactActionStatus} {
+		httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/"+string(action)+"/alias@domain.tld",
+			func(req *http.Request) (*http.Response, error) {
+				received = PikeContactResponsePayload{}
+				if err := json.NewDecoder(req.Body).Decode(&received); err != nil {
+					return nil, err
+				}
+				return httpmock.NewStringResponse(http.StatusOK, `{"paymail": "johndoe@example.com", "status": "pending"}`), nil
+			},
+		)
+	}
+	contactURL := func(action PikeContactAction) string {
+		return "https://" + testDomain + "/v1/bsvalias/contact/" + string(action) + "/{alias}@{domain.tld}"
+	}
+	request := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+
+	_, err = client.AcceptContactRequest(contactURL(PikeContactActionAccept), "alias", "domain.tld", request)
+	require.NoError(t, err)
+	require.NoError(t, received.Verify(key.PubKey(), PikeContactActionAccept, "alias@domain.tld"))
+
+	_, err = client.RejectContactRequest(contactURL(PikeContactActionReject), "alias", "domain.tld", request)
+	require.NoError(t, err)
+	require.NoError(t, received.Verify(key.PubKey(), PikeContactActionReject, "alias@domain.tld"))
+
+	_, err = client.GetContactStatus(contactURL(PikeContactActionStatus), "alias", "domain.tld", request)
+	require.NoError(t, err)
+	require.NoError(t, received.Verify(key.PubKey(), PikeContactActionStatus, "alias@domain.tld"))
+
+	require.Empty(t, request.Signature, "the request is not modified")
+}
+
+// TestClient_GetContactStatus will test the method GetContactStatus()
+func TestClient_GetContactStatus(t *testing.T) {
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	client := newTestClient(t)
+	statusURL := "https://" + testDomain + "/v1/bsvalias/contact/status/{alias}@{domain.tld}"
+	request := &PikeContactResponsePayload{Paymail: "johndoe@example.com"}
+
+	t.Run("successful status response", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/status/alias@domain.tld",
+			httpmock.NewStringResponder(http.StatusOK, `{"paymail": "johndoe@example.com", "status": "accepted"}`),
+		)
+
+		response, err := client.GetContactStatus(statusURL, "alias", "domain.tld", request)
+		require.NoError(t, err)
+		require.Equal(t, "johndoe@example.com", response.Paymail)
+		require.Equal(t, PikeContactStatusAccepted, response.Status)
+	})
+
+	t.Run("contact not found", func(t *testing.T) {
+		httpmock.Reset()
+		httpmock.RegisterResponder(http.MethodPost, "https://"+testDomain+"/v1/bsvalias/contact/status/alias@domain.tld",
+			httpmock.NewStringResponder(http.StatusNotFound, `{"message": "contact not found"}`),
+		)
+
+		response, err := client.GetContactStatus(statusURL, "alias", "domain.tld", request)
+		require.Error(t, err)
+		require.Nil(t, response)
+	})
+}
+
 // BenchmarkClient_GetOutputsTemplate benchmarks the method GetOutputsTemplate()
 func BenchmarkClient_GetOutputsTemplate(b *testing.B) {
 	httpmock.Activate()
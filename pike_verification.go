@@ -0,0 +1,98 @@
+package paymail
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+)
+
+// Parameters of the PIKE contact verification codes (TOTP, RFC 6238 with HMAC-SHA256)
+const (
+	PikeContactCodeDigits = 6                // Number of digits of the code
+	PikeContactCodePeriod = 30 * time.Second // Validity period of the code
+	PikeContactCodeSkew   = 1                // Number of periods accepted before and after the current one
+
+	pikeContactCodeModulo = 1000000 // 10^PikeContactCodeDigits
+)
+
+// GeneratePikeContactCode will generate the code confirming our identity key to the contact (read out-of-band)
+//
+// The secret is derived from the shared secret of both PKI keys (ECDH) and our public key, so each
+// party has its own code and only the contact can validate it (ValidatePikeContactCode).
+// The contact is confirmed when both parties validated the code of the other one
+func GeneratePikeContactCode(privateKey *ec.PrivateKey, contactPubKey *ec.PublicKey, at time.Time) (string, error) {
+	if privateKey == nil {
+		return "", errors.New("missing private key")
+	}
+
+	secret, err := pikeContactSecret(privateKey, contactPubKey, privateKey.PubKey())
+	if err != nil {
+		return "", err
+	}
+	return pikeContactCode(secret, pikeContactCounter(at)), nil
+}
+
+// ValidatePikeContactCode will validate the code generated by the contact (GeneratePikeContactCode with its key)
+//
+// The codes of the adjacent periods are accepted (PikeContactCodeSkew) to allow for clock drift and reading delays
+func ValidatePikeContactCode(privateKey *ec.PrivateKey, contactPubKey *ec.PublicKey, code string, at time.Time) (bool, error) {
+	if privateKey == nil {
+		return false, errors.New("missing private key")
+	} else if len(code) != PikeContactCodeDigits {
+		return false, nil
+	}
+
+	secret, err := pikeContactSecret(privateKey, contactPubKey, contactPubKey)
+	if err != nil {
+		return false, err
+	}
+
+	counter := pikeContactCounter(at)
+	for skew := -PikeContactCodeSkew; skew <= PikeContactCodeSkew; skew++ {
+		expected := pikeContactCode(secret, counter+uint64(int64(skew)))
+		if hmac.Equal([]byte(expected), []byte(code)) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// pikeContactSecret will derive the secret of the codes generated by the owner of the generator key
+func pikeContactSecret(privateKey *ec.PrivateKey, contactPubKey, generatorPubKey *ec.PublicKey) ([]byte, error) {
+	if contactPubKey == nil {
+		return nil, errors.New("missing contact public key")
+	}
+
+	sharedSecret, err := privateKey.DeriveSharedSecret(contactPubKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive the shared secret: %w", err)
+	}
+
+	mac := hmac.New(sha256.New, sharedSecret.Compressed())
+	mac.Write(generatorPubKey.Compressed())
+	return mac.Sum(nil), nil
+}
+
+// pikeContactCounter will return the number of periods since the unix epoch
+func pikeContactCounter(at time.Time) uint64 {
+	return uint64(at.Unix()) / uint64(PikeContactCodePeriod/time.Second)
+}
+
+// pikeContactCode will return the code for the counter (HOTP dynamic truncation, RFC 4226)
+func pikeContactCode(secret []byte, counter uint64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", PikeContactCodeDigits, value%pikeContactCodeModulo)
+}
@@ -0,0 +1,79 @@
+package paymail
+
+import (
+	"testing"
+	"time"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPikeContactCode will test the methods GeneratePikeContactCode() and ValidatePikeContactCode()
+func TestPikeContactCode(t *testing.T) {
+	t.Parallel()
+
+	alice, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	bob, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	t.Run("mutual confirmation", func(t *testing.T) {
+		aliceCode, err := GeneratePikeContactCode(alice, bob.PubKey(), now)
+		require.NoError(t, err)
+		require.Len(t, aliceCode, PikeContactCodeDigits)
+		bobCode, err := GeneratePikeContactCode(bob, alice.PubKey(), now)
+		require.NoError(t, err)
+		assert.NotEqual(t, aliceCode, bobCode, "each party has its own code")
+
+		valid, err := ValidatePikeContactCode(bob, alice.PubKey(), aliceCode, now)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = ValidatePikeContactCode(alice, bob.PubKey(), bobCode, now)
+		require.NoError(t, err)
+		assert.True(t, valid)
+
+		valid, err = ValidatePikeContactCode(alice, bob.PubKey(), aliceCode, now)
+		require.NoError(t, err)
+		assert.False(t, valid, "own code is not valid for the contact")
+	})
+
+	t.Run("clock skew", func(t *testing.T) {
+		code, err := GeneratePikeContactCode(alice, bob.PubKey(), now)
+		require.NoError(t, err)
+
+		for _, offset := range []time.Duration{-PikeContactCodePeriod, 0, PikeContactCodePeriod} {
+			valid, err := ValidatePikeContactCode(bob, alice.PubKey(), code, now.Add(offset))
+			require.NoError(t, err)
+			assert.True(t, valid, "offset %s", offset)
+		}
+
+		valid, err := ValidatePikeContactCode(bob, alice.PubKey(), code, now.Add(3*PikeContactCodePeriod))
+		require.NoError(t, err)
+		assert.False(t, valid, "expired code")
+	})
+
+	t.Run("other contact", func(t *testing.T) {
+		eve, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		code, err := GeneratePikeContactCode(eve, bob.PubKey(), now)
+		require.NoError(t, err)
+		valid, err := ValidatePikeContactCode(bob, alice.PubKey(), code, now)
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+
+	t.Run("invalid input", func(t *testing.T) {
+		_, err := GeneratePikeContactCode(nil, bob.PubKey(), now)
+		require.Error(t, err)
+		_, err = GeneratePikeContactCode(alice, nil, now)
+		require.Error(t, err)
+
+		valid, err := ValidatePikeContactCode(bob, alice.PubKey(), "123", now)
+		require.NoError(t, err)
+		assert.False(t, valid)
+	})
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -33,10 +34,15 @@ type PKIPayload struct {
 //
 // Specs: http://bsvalias.org/03-public-key-infrastructure.html
 func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, err error) {
+	return c.GetPKICtx(context.Background(), pkiURL, alias, domain)
+}
+
+// GetPKICtx is the context-aware version of GetPKI()
+func (c *Client) GetPKICtx(ctx context.Context, pkiURL, alias, domain string) (response *PKIResponse, err error) {
 
 	// Require a valid url
 	if len(pkiURL) == 0 || !strings.Contains(pkiURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", pkiURL)
+		err = invalidURLError(pkiURL)
 		return
 	}
 
@@ -55,7 +61,7 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return
 	}
 
@@ -64,11 +70,7 @@ func (c *Client) GetPKI(pkiURL, alias, domain string) (response *PKIResponse, er
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newPaymailProviderError(reqURL, alias, domain, &resp)
 		return
 	}
 
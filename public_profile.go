@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -31,10 +32,15 @@ type PublicProfilePayload struct {
 //
 // Specs: https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files
 func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
+	return c.GetPublicProfileCtx(context.Background(), publicProfileURL, alias, domain)
+}
+
+// GetPublicProfileCtx is the context-aware version of GetPublicProfile()
+func (c *Client) GetPublicProfileCtx(ctx context.Context, publicProfileURL, alias, domain string) (response *PublicProfileResponse, err error) {
 
 	// Require a valid url
 	if len(publicProfileURL) == 0 || !strings.Contains(publicProfileURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", publicProfileURL)
+		err = invalidURLError(publicProfileURL)
 		return
 	}
 
@@ -53,7 +59,7 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return
 	}
 
@@ -62,11 +68,7 @@ func (c *Client) GetPublicProfile(publicProfileURL, alias, domain string) (respo
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newPaymailProviderError(reqURL, alias, domain, &resp)
 		return
 	}
 
@@ -1,9 +1,9 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 
@@ -27,10 +27,15 @@ type ResolutionPayload struct {
 //
 // Specs: http://bsvalias.org/04-01-basic-address-resolution.html
 func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
+	return c.ResolveAddressCtx(context.Background(), resolutionURL, alias, domain, senderRequest)
+}
+
+// ResolveAddressCtx is the context-aware version of ResolveAddress()
+func (c *Client) ResolveAddressCtx(ctx context.Context, resolutionURL, alias, domain string, senderRequest *SenderRequest) (response *ResolutionResponse, err error) {
 
 	// Require a valid url
 	if len(resolutionURL) == 0 || !strings.Contains(resolutionURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", resolutionURL)
+		err = invalidURLError(resolutionURL)
 		return
 	}
 
@@ -61,7 +66,7 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	// Fire the POST request
 	var resp StandardResponse
-	if resp, err = c.postRequest(reqURL, senderRequest); err != nil {
+	if resp, err = c.postRequest(ctx, reqURL, senderRequest); err != nil {
 		return
 	}
 
@@ -70,18 +75,7 @@ func (c *Client) ResolveAddress(resolutionURL, alias, domain string, senderReque
 
 	// Test the status code
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-
-		// Paymail address not found?
-		if response.StatusCode == http.StatusNotFound {
-			err = errors.New("paymail address not found")
-		} else {
-			serverError := &ServerError{}
-			if err = json.Unmarshal(resp.Body, serverError); err != nil {
-				return
-			}
-			err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
-		}
-
+		err = newPaymailProviderError(reqURL, alias, domain, &resp)
 		return
 	}
 
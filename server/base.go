@@ -1,20 +1,23 @@
 package server
 
 import (
-	"github.com/gin-gonic/gin"
 	"net/http"
 )
 
 // index basic request to /
 // nolint: revive // do not check for unused param required by interface
-func index(c *gin.Context) {
+func index(w http.ResponseWriter, _ *http.Request) {
 	responseData := map[string]interface{}{"message": "Welcome to the Paymail Server ✌(◕‿-)✌"}
 
-	c.Header("Content-Type", "application/json")
-	c.JSON(http.StatusOK, responseData)
+	writeJSON(w, http.StatusOK, responseData)
 }
 
-// health is a basic request to return a health response
-func health(c *gin.Context) {
-	c.Status(http.StatusOK)
+// health is a basic request to return a health response (unhealthy while the server is draining)
+func (c *Configuration) health(w http.ResponseWriter, _ *http.Request) {
+	if c.draining.Load() {
+		w.Header().Set("Connection", "close")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
 }
@@ -6,15 +6,17 @@ import (
 	"net/http"
 	"strings"
 
-	"github.com/gin-gonic/gin"
-
 	"github.com/bitcoin-sv/go-paymail"
 )
 
+// CallableCapability is a capability served by the paymail server
+//
+// The Handler is a net/http handler, path values are read using http.Request.PathValue()
+// (e.g. PaymailAddressParamName), see Configuration.Handler()
 type CallableCapability struct {
 	Path    string
 	Method  string
-	Handler gin.HandlerFunc
+	Handler http.HandlerFunc
 }
 
 type NestedCapabilitiesMap map[string]CallableCapabilitiesMap
@@ -91,6 +93,21 @@ func (c *Configuration) SetPikeContactCapabilities() {
 					Method:  http.MethodPost,
 					Handler: c.pikeNewContact,
 				},
+				paymail.BRFCPikeAccept: CallableCapability{
+					Path:    fmt.Sprintf("/contact/accept/%s", PaymailAddressTemplate),
+					Method:  http.MethodPost,
+					Handler: c.pikeAcceptContact,
+				},
+				paymail.BRFCPikeReject: CallableCapability{
+					Path:    fmt.Sprintf("/contact/reject/%s", PaymailAddressTemplate),
+					Method:  http.MethodPost,
+					Handler: c.pikeRejectContact,
+				},
+				paymail.BRFCPikeStatus: CallableCapability{
+					Path:    fmt.Sprintf("/contact/status/%s", PaymailAddressTemplate),
+					Method:  http.MethodPost,
+					Handler: c.pikeContactStatus,
+				},
 			},
 		},
 	)
@@ -129,28 +146,28 @@ func _addNestedCapabilities(base NestedCapabilitiesMap, newCaps NestedCapabiliti
 // and list all active capabilities of the Paymail server
 //
 // Specs: http://bsvalias.org/02-02-capability-discovery.html
-func (c *Configuration) showCapabilities(context *gin.Context) {
+func (c *Configuration) showCapabilities(w http.ResponseWriter, req *http.Request) {
 	// Check the host (allowed, and used for capabilities response)
 	// todo: bake this into middleware? This is protecting the "req" host name (like CORs)
 	host := ""
-	if context.Request.URL.IsAbs() || len(context.Request.URL.Host) == 0 {
-		host = context.Request.Host
+	if req.URL.IsAbs() || len(req.URL.Host) == 0 {
+		host = req.Host
 	} else {
-		host = context.Request.URL.Host
+		host = req.URL.Host
 	}
 
 	if !c.IsAllowedDomain(host) {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	}
 
 	capabilities, err := c.EnrichCapabilities(host)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	context.JSON(http.StatusOK, capabilities)
+	writeJSON(w, http.StatusOK, capabilities)
 }
 
 // EnrichCapabilities will update the capabilities with the appropriate service url
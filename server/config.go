@@ -3,28 +3,42 @@ package server
 import (
 	"slices"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/bitcoin-sv/go-paymail/errors"
 	"github.com/rs/zerolog"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/spv"
 )
 
 // Configuration paymail server configuration object
+//
+// The issued references are not checked by default: received transactions are only matched to
+// the references (paymail, outputs and replays) if a store is set with WithReferenceStore
 type Configuration struct {
 	APIVersion                       string          `json:"api_version"`
 	BasicRoutes                      *basicRoutes    `json:"basic_routes"`
+	BEEFLimits                       beef.Limits     `json:"beef_limits"`
 	BSVAliasVersion                  string          `json:"bsv_alias_version"`
+	DrainDelay                       time.Duration   `json:"drain_delay"`
 	PaymailDomains                   []*Domain       `json:"paymail_domains"`
 	PaymailDomainsValidationDisabled bool            `json:"paymail_domains_validation_disabled"`
 	Port                             int             `json:"port"`
 	Prefix                           string          `json:"prefix"`
+	RateLimits                       RateLimits      `json:"rate_limits"`
+	ReferenceTTL                     time.Duration   `json:"reference_ttl"`
+	ReplayWindow                     time.Duration   `json:"replay_window"`
 	SenderValidationEnabled          bool            `json:"sender_validation_enabled"`
+	SPVFailureDetailsEnabled         bool            `json:"spv_failure_details_enabled"`
+	SPVPolicy                        spv.Policy      `json:"spv_policy"`
 	GenericCapabilitiesEnabled       bool            `json:"generic_capabilities_enabled"`
 	P2PCapabilitiesEnabled           bool            `json:"p2p_capabilities_enabled"`
 	BeefCapabilitiesEnabled          bool            `json:"beef_capabilities_enabled"`
 	PikeContactCapabilitiesEnabled   bool            `json:"pike_contact_capabilities_enabled"`
+	PikeContactSignatureRequired     bool            `json:"pike_contact_signature_required"`
 	PikePaymentCapabilitiesEnabled   bool            `json:"pike_payment_capabilities_enabled"`
 	ServiceName                      string          `json:"service_name"`
 	Timeout                          time.Duration   `json:"timeout"`
@@ -37,6 +51,13 @@ type Configuration struct {
 	nestedCapabilities   NestedCapabilitiesMap
 	callableCapabilities CallableCapabilitiesMap
 	staticCapabilities   StaticCapabilitiesMap
+	paymailClient        paymail.ClientInterface // Client for outbound lookups (sender PKI)
+	ownsPaymailClient    bool                    // Client was created by NewConfig (closed when the server stops)
+	draining             atomic.Bool             // Set while the server is shutting down (health is unhealthy)
+	referenceStore       ReferenceStore          // Issued references (receiving transactions is idempotent if set)
+	replayStore          ReplayStore             // Accepted signed requests (replays are rejected if set)
+	rateLimitStore       RateLimitStore          // Budgets of the rate limited requests (capabilities are rate limited if set)
+	chainTip             spv.ChainTip            // Chain tip for the lock time evaluation of the received transactions
 }
 
 // Domain is the Paymail Domain information
@@ -159,6 +180,18 @@ func NewConfig(serviceProvider *PaymailServiceLocator, opts ...ConfigOps) (*Conf
 		return nil, err
 	}
 
+	// Load the client for outbound lookups (shared, using the discovery cache)
+	if config.paymailClient == nil {
+		var err error
+		if config.paymailClient, err = paymail.NewClient(
+			paymail.WithHTTPTimeout(config.Timeout),
+			paymail.WithDiscoveryCache(paymail.NewMemoryDiscoveryCache(0)),
+		); err != nil {
+			return nil, err
+		}
+		config.ownsPaymailClient = true
+	}
+
 	// Set the service provider
 	config.actions = serviceProvider.GetPaymailService()
 
@@ -7,6 +7,8 @@ import (
 	"github.com/rs/zerolog"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/spv"
 )
 
 // ConfigOps allow functional options to be supplied
@@ -20,10 +22,13 @@ func defaultConfigOptions() *Configuration {
 	return &Configuration{
 		APIVersion:                       DefaultAPIVersion,
 		BasicRoutes:                      &basicRoutes{},
+		BEEFLimits:                       beef.DefaultLimits,
 		BSVAliasVersion:                  paymail.DefaultBsvAliasVersion,
 		PaymailDomainsValidationDisabled: false,
 		Port:                             DefaultServerPort,
 		Prefix:                           DefaultPrefix,
+		ReferenceTTL:                     DefaultReferenceTTL,
+		ReplayWindow:                     DefaultReplayWindow,
 		SenderValidationEnabled:          DefaultSenderValidation,
 		GenericCapabilitiesEnabled:       true,
 		P2PCapabilitiesEnabled:           false,
@@ -68,6 +73,13 @@ func WithPikeContactCapabilities() ConfigOps {
 	}
 }
 
+// WithPikeContactSignatureRequired will reject the PIKE contact requests which are not signed by the requester
+func WithPikeContactSignatureRequired() ConfigOps {
+	return func(c *Configuration) {
+		c.PikeContactSignatureRequired = true
+	}
+}
+
 // WithPikePaymentCapabilities will load the PIKE capabilities
 func WithPikePaymentCapabilities() ConfigOps {
 	return func(c *Configuration) {
@@ -110,6 +122,26 @@ func WithTimeout(timeout time.Duration) ConfigOps {
 	}
 }
 
+// WithDrainDelay will set how long the server reports unhealthy before it stops accepting connections
+//
+// Gives the load balancers time to remove the server (on Shutdown) before new requests are refused
+func WithDrainDelay(delay time.Duration) ConfigOps {
+	return func(c *Configuration) {
+		if delay > 0 {
+			c.DrainDelay = delay
+		}
+	}
+}
+
+// WithBEEFLimits will set the limits for decoding the received BEEF (beef.DefaultLimits by default)
+//
+// The P2P transaction request body is limited to twice the MaxBytes (hex) and room for the metadata
+func WithBEEFLimits(limits beef.Limits) ConfigOps {
+	return func(c *Configuration) {
+		c.BEEFLimits = limits
+	}
+}
+
 // WithServiceName will set a custom service name
 func WithServiceName(serviceName string) ConfigOps {
 	return func(c *Configuration) {
@@ -126,6 +158,29 @@ func WithSenderValidation() ConfigOps {
 	}
 }
 
+// WithSPVFailureDetails will return the precise SPV failure (code and message) to the senders instead of a generic error
+func WithSPVFailureDetails() ConfigOps {
+	return func(c *Configuration) {
+		c.SPVFailureDetailsEnabled = true
+	}
+}
+
+// WithSPVPolicy will set the fee rate, dust and size policy for the received transactions which are not mined
+func WithSPVPolicy(policy spv.Policy) ConfigOps {
+	return func(c *Configuration) {
+		c.SPVPolicy = policy
+	}
+}
+
+// WithChainTip will set the chain tip used to evaluate the lock times of the received transactions
+//
+// The transactions which are not final are handled according to the SPV policy (see WithSPVPolicy)
+func WithChainTip(chainTip spv.ChainTip) ConfigOps {
+	return func(c *Configuration) {
+		c.chainTip = chainTip
+	}
+}
+
 // WithDomain will add the domain if not found
 func WithDomain(domain string) ConfigOps {
 	return func(c *Configuration) {
@@ -152,6 +207,69 @@ func WithDomainValidationDisabled() ConfigOps {
 	}
 }
 
+// WithPaymailClient will set a custom paymail client for outbound lookups (sender PKI)
+//
+// By default, a client using an in-memory discovery cache is created (and closed when the server stops),
+// the custom client is never closed by the server
+func WithPaymailClient(client paymail.ClientInterface) ConfigOps {
+	return func(c *Configuration) {
+		if client != nil {
+			c.paymailClient = client
+		}
+	}
+}
+
+// WithReferenceStore will store the issued references to make receiving transactions idempotent
+//
+// Unknown or expired references are rejected, exact replays (same paymail, reference and txid) return
+// the stored response and a different txid for an already used reference is rejected.
+// New transactions must pay the outputs (scripts and satoshis) issued for the reference.
+// The reference must be received by the paymail it was issued for.
+// Without a reference store, none of these checks are done (references are passed to the service provider as is).
+// If ttl is zero, the DefaultReferenceTTL is used
+func WithReferenceStore(store ReferenceStore, ttl time.Duration) ConfigOps {
+	return func(c *Configuration) {
+		c.referenceStore = store
+		if ttl > 0 {
+			c.ReferenceTTL = ttl
+		}
+	}
+}
+
+// WithReplayGuard will store the accepted signed requests to reject their replays
+//
+// The signed address resolutions, PIKE contact requests and answers are accepted once within the window
+// (their timestamps must be within the window). The requests failing in the service provider can be sent again.
+//
+// With the reference store (WithReferenceStore), the transactions with signed P2P metadata are recorded once
+// per txid for the ReferenceTTL (exact resubmissions return the stored response, other references are rejected).
+// Without it, the transactions are not guarded: a resubmission is recorded again, so the service provider
+// must handle the duplicates.
+// If window is zero, the DefaultReplayWindow is used
+func WithReplayGuard(store ReplayStore, window time.Duration) ConfigOps {
+	return func(c *Configuration) {
+		c.replayStore = store
+		if window > 0 {
+			c.ReplayWindow = window
+		}
+	}
+}
+
+// WithRateLimits will limit the requests of the capabilities for each client IP and each target paymail address
+//
+// The client IP is the remote address, unless limits.TrustProxyHeaders is set (X-Real-IP or X-Forwarded-For,
+// only behind a proxy setting them), the requests exceeding the budget are rejected with 429 and a Retry-After header.
+// If store is nil, the in-memory store is used (NewMemoryRateLimitStore)
+func WithRateLimits(limits RateLimits, store RateLimitStore) ConfigOps {
+	return func(c *Configuration) {
+		c.RateLimits = limits
+		c.rateLimitStore = store
+		if c.rateLimitStore == nil {
+			c.rateLimitStore = NewMemoryRateLimitStore()
+		}
+	}
+}
+
 // WithLogger will set a custom logger
 func WithLogger(logger *zerolog.Logger) ConfigOps {
 	return func(c *Configuration) {
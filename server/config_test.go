@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/spv"
 )
 
 // testConfig loads a basic test configuration
@@ -367,6 +368,37 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, 10*time.Second, c.Timeout)
 	})
 
+	t.Run("default paymail client", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		require.NotNil(t, c.paymailClient)
+		assert.True(t, c.ownsPaymailClient)
+	})
+
+	t.Run("custom paymail client", func(t *testing.T) {
+		client, err := paymail.NewClient()
+		require.NoError(t, err)
+
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		var c *Configuration
+		c, err = NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithPaymailClient(client),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, client, c.paymailClient)
+		assert.False(t, c.ownsPaymailClient)
+	})
+
 	t.Run("custom service name", func(t *testing.T) {
 		sl := &PaymailServiceLocator{}
 		sl.RegisterPaymailService(new(mockServiceProvider))
@@ -393,6 +425,22 @@ func TestNewConfig(t *testing.T) {
 		assert.Equal(t, true, c.SenderValidationEnabled)
 	})
 
+	t.Run("spv policy and failure details", func(t *testing.T) {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		policy := spv.Policy{DustLimit: 1, MaxTxSize: 1000000, MinFeeRateSatPerKB: 1}
+		c, err := NewConfig(
+			sl,
+			WithDomain("test.com"),
+			WithSPVPolicy(policy),
+			WithSPVFailureDetails(),
+		)
+		require.NoError(t, err)
+		require.NotNil(t, c)
+		assert.Equal(t, policy, c.SPVPolicy)
+		assert.True(t, c.SPVFailureDetailsEnabled)
+	})
+
 	t.Run("with p2p capabilities", func(t *testing.T) {
 		sl := &PaymailServiceLocator{}
 		sl.RegisterPaymailService(new(mockServiceProvider))
@@ -10,6 +10,8 @@ import (
 const (
 	DefaultAPIVersion       = "v1"             // Version of API
 	DefaultPrefix           = "https://"       // Paymail specs require SSL
+	DefaultReferenceTTL     = 24 * time.Hour   // How long an issued reference can be used
+	DefaultReplayWindow     = 2 * time.Minute  // Allowed skew of the signed request timestamps (sender validation specs)
 	DefaultSenderValidation = false            // If true, it requires extra sender validation
 	DefaultServerPort       = 3000             // Port for the server
 	DefaultTimeout          = 15 * time.Second // Default timeouts
@@ -36,6 +38,7 @@ type RequestMetadata struct {
 	Alias              string                  `json:"alias,omitempty"`               // Alias of the paymail
 	Domain             string                  `json:"domain,omitempty"`              // Domain of the request
 	IPAddress          string                  `json:"ip_address,omitempty"`          // IP address of the requesting user
+	NonFinalTxIDs      []string                `json:"non_final_tx_ids,omitempty"`    // Received transactions to queue until they are final (spv.NonFinalQueue)
 	Note               string                  `json:"note,omitempty"`                // Generic note field used for extra information
 	PaymentDestination *paymail.PaymentRequest `json:"payment_destination,omitempty"` // Information from the P2P Payment Destination request
 	RequestURI         string                  `json:"request_uri,omitempty"`         // Full requesting URL path
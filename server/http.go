@@ -0,0 +1,120 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// Handler will return the paymail routes as a net/http handler (can be mounted into any router)
+//
+// Path values (paymail address, pubkey) are read using http.Request.PathValue(),
+// routers which do not set the path values can use the gin adapter (Handlers) instead
+func (c *Configuration) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	c.registerBasicHandlers(mux)
+	c.registerHandlers(mux)
+
+	return c.withRequestLogging(c.withRecovery(mux))
+}
+
+// registerBasicHandlers will register the basic routes to the mux
+func (c *Configuration) registerBasicHandlers(mux *http.ServeMux) {
+	// Skip if not set
+	if c.BasicRoutes == nil {
+		return
+	}
+
+	// Set the main index page (navigating to slash)
+	if c.BasicRoutes.AddIndexRoute {
+		mux.HandleFunc(http.MethodGet+" /{$}", index)
+	}
+
+	// Set the health request (used for load balancers, GET also matches HEAD)
+	if c.BasicRoutes.AddHealthRoute {
+		mux.HandleFunc(http.MethodGet+" /health", c.health)
+		mux.HandleFunc(http.MethodOptions+" /health", c.health)
+	}
+}
+
+// registerHandlers will register all the available paymail routes to the mux
+func (c *Configuration) registerHandlers(mux *http.ServeMux) {
+	mux.HandleFunc(http.MethodGet+" /.well-known/"+c.ServiceName, c.showCapabilities) // service discovery
+
+	for key, cap := range c.callableCapabilities {
+		mux.HandleFunc(cap.Method+" "+c.templateToPattern(cap.Path), c.withRateLimit(key, cap.Handler))
+	}
+
+	for _, nestedCap := range c.nestedCapabilities {
+		for key, cap := range nestedCap {
+			mux.HandleFunc(cap.Method+" "+c.templateToPattern(cap.Path), c.withRateLimit(key, cap.Handler))
+		}
+	}
+}
+
+// templateToPattern will convert the capability path template to a http.ServeMux pattern
+func (c *Configuration) templateToPattern(template string) string {
+	template = strings.ReplaceAll(template, PaymailAddressTemplate, "{"+PaymailAddressParamName+"}")
+	template = strings.ReplaceAll(template, PubKeyTemplate, "{"+PubKeyParamName+"}")
+	return fmt.Sprintf("/%s/%s/%s", c.APIVersion, c.ServiceName, strings.TrimPrefix(template, "/"))
+}
+
+// writeJSON will write the payload as a JSON response
+func writeJSON(w http.ResponseWriter, statusCode int, payload interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	_ = json.NewEncoder(w).Encode(payload)
+}
+
+// statusRecorder records the status code written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+// WriteHeader will record the status code
+func (r *statusRecorder) WriteHeader(statusCode int) {
+	r.statusCode = statusCode
+	r.ResponseWriter.WriteHeader(statusCode)
+}
+
+// Unwrap will return the original writer (used by http.ResponseController)
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// withRequestLogging will log each request using the configured logger
+func (c *Configuration) withRequestLogging(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(recorder, req)
+
+		c.Logger.Info().
+			Str("method", req.Method).
+			Str("path", req.URL.Path).
+			Int("status", recorder.statusCode).
+			Dur("latency", time.Since(start)).
+			Msg("paymail request")
+	})
+}
+
+// withRecovery will recover from a panic in a handler and return an internal server error
+func (c *Configuration) withRecovery(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec) // let net/http abort the response
+				}
+				errors.WriteErrorResponse(w, fmt.Errorf("panic while handling %s: %v", req.URL.Path, rec), c.Logger)
+			}
+		}()
+		next.ServeHTTP(w, req)
+	})
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// testHandlers will return the net/http handler and the gin adapter for the configuration
+func testHandlers(t *testing.T, opts ...ConfigOps) map[string]http.Handler {
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(new(mockServiceProvider))
+
+	config, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com")}, opts...)...)
+	require.NoError(t, err)
+
+	return map[string]http.Handler{
+		"net/http": config.Handler(),
+		"gin":      Handlers(config),
+	}
+}
+
+// serveTestRequest will serve the request and return the response recorder
+func serveTestRequest(handler http.Handler, method, path string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.Host = "test.com"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// errorCode will return the code of the error response
+func errorCode(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	var response errors.ResponseError
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&response))
+	return response.Code
+}
+
+// TestConfiguration_Handler will test the method Handler() (and the gin adapter)
+func TestConfiguration_Handler(t *testing.T) {
+	t.Parallel()
+
+	t.Run("capabilities", func(t *testing.T) {
+		for name, handler := range testHandlers(t) {
+			recorder := serveTestRequest(handler, http.MethodGet, "/.well-known/"+paymail.DefaultServiceName)
+			require.Equal(t, http.StatusOK, recorder.Code, name)
+
+			var payload paymail.CapabilitiesPayload
+			require.NoError(t, json.NewDecoder(recorder.Body).Decode(&payload), name)
+			assert.Equal(t, paymail.DefaultBsvAliasVersion, payload.BsvAlias, name)
+			assert.Equal(t, "https://test.com/v1/bsvalias/id/{alias}@{domain.tld}", payload.GetString(paymail.BRFCPki, ""), name)
+		}
+	})
+
+	t.Run("paymail path value", func(t *testing.T) {
+		for name, handler := range testHandlers(t) {
+			recorder := serveTestRequest(handler, http.MethodGet, "/v1/bsvalias/id/mrz@test.com")
+			assert.Equal(t, http.StatusBadRequest, recorder.Code, name)
+			assert.Equal(t, errors.ErrCouldNotFindPaymail.Code, errorCode(t, recorder), name)
+
+			recorder = serveTestRequest(handler, http.MethodGet, "/v1/bsvalias/id/mrz@unknown.com")
+			assert.Equal(t, errors.ErrDomainUnknown.Code, errorCode(t, recorder), name)
+		}
+	})
+
+	t.Run("pubkey path value", func(t *testing.T) {
+		for name, handler := range testHandlers(t) {
+			recorder := serveTestRequest(handler, http.MethodGet, "/v1/bsvalias/verify-pubkey/mrz@test.com/invalid")
+			assert.Equal(t, errors.ErrInvalidPubKey.Code, errorCode(t, recorder), name)
+		}
+	})
+
+	t.Run("basic routes", func(t *testing.T) {
+		for name, handler := range testHandlers(t, WithBasicRoutes()) {
+			recorder := serveTestRequest(handler, http.MethodGet, "/health")
+			assert.Equal(t, http.StatusOK, recorder.Code, name)
+
+			recorder = serveTestRequest(handler, http.MethodGet, "/")
+			assert.Equal(t, http.StatusOK, recorder.Code, name)
+			assert.Contains(t, recorder.Body.String(), "Welcome", name)
+		}
+	})
+
+	t.Run("custom capability", func(t *testing.T) {
+		custom := WithCapabilities(map[string]any{
+			"custom_cap": CallableCapability{
+				Path:   fmt.Sprintf("/custom/%s", PaymailAddressTemplate),
+				Method: http.MethodGet,
+				Handler: func(w http.ResponseWriter, req *http.Request) {
+					writeJSON(w, http.StatusOK, map[string]string{"paymail": req.PathValue(PaymailAddressParamName)})
+				},
+			},
+			"panic_cap": CallableCapability{
+				Path:   fmt.Sprintf("/panic/%s", PaymailAddressTemplate),
+				Method: http.MethodGet,
+				Handler: func(_ http.ResponseWriter, _ *http.Request) {
+					panic("test panic")
+				},
+			},
+		})
+
+		for name, handler := range testHandlers(t, custom) {
+			recorder := serveTestRequest(handler, http.MethodGet, "/v1/bsvalias/custom/mrz@test.com")
+			require.Equal(t, http.StatusOK, recorder.Code, name)
+			assert.JSONEq(t, `{"paymail":"mrz@test.com"}`, recorder.Body.String(), name)
+
+			recorder = serveTestRequest(handler, http.MethodGet, "/v1/bsvalias/panic/mrz@test.com")
+			assert.Equal(t, http.StatusInternalServerError, recorder.Code, name)
+		}
+	})
+
+	t.Run("mounted into another router", func(t *testing.T) {
+		handler := testHandlers(t)["net/http"]
+
+		mux := http.NewServeMux()
+		mux.Handle("/paymail/", http.StripPrefix("/paymail", handler))
+
+		recorder := serveTestRequest(mux, http.MethodGet, "/paymail/v1/bsvalias/id/mrz@test.com")
+		assert.Equal(t, errors.ErrCouldNotFindPaymail.Code, errorCode(t, recorder))
+	})
+}
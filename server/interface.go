@@ -89,6 +89,29 @@ type PikeContactServiceProvider interface {
 		requesterPaymail string,
 		contact *paymail.PikeContactRequestPayload,
 	) error
+
+	// AcceptContact is called when the contact accepted the contact request sent by the paymail
+	// (the paymail is local and the answer is signed by the PKI key of the contact)
+	AcceptContact(
+		ctx context.Context,
+		paymailAddress string,
+		contact *paymail.PikeContactResponsePayload,
+	) error
+
+	// RejectContact is called when the contact rejected the contact request sent by the paymail
+	// (the paymail is local and the answer is signed by the PKI key of the contact)
+	RejectContact(
+		ctx context.Context,
+		paymailAddress string,
+		contact *paymail.PikeContactResponsePayload,
+	) error
+
+	// GetContactStatus returns the status of the contact at the paymail (errors.ErrContactNotFound if there is none)
+	GetContactStatus(
+		ctx context.Context,
+		paymailAddress string,
+		contact *paymail.PikeContactResponsePayload,
+	) (*paymail.PikeContactStatusPayload, error)
 }
 
 type PikePaymentServiceProvider interface {
@@ -53,6 +53,18 @@ func (m *mockServiceProvider) AddContact(ctx context.Context, requesterPaymail s
 	return nil
 }
 
+func (m *mockServiceProvider) AcceptContact(_ context.Context, _ string, _ *paymail.PikeContactResponsePayload) error {
+	return nil
+}
+
+func (m *mockServiceProvider) RejectContact(_ context.Context, _ string, _ *paymail.PikeContactResponsePayload) error {
+	return nil
+}
+
+func (m *mockServiceProvider) GetContactStatus(_ context.Context, _ string, contact *paymail.PikeContactResponsePayload) (*paymail.PikeContactStatusPayload, error) {
+	return &paymail.PikeContactStatusPayload{Paymail: contact.Paymail, Status: paymail.PikeContactStatusPending}, nil
+}
+
 func (m *mockServiceProvider) CreatePikeOutputResponse(ctx context.Context, alias, domain, senderPubKey string, satoshis uint64, metaData *RequestMetadata) (*paymail.PikePaymentOutputsResponse, error) {
 	return nil, nil
 }
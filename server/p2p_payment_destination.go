@@ -1,8 +1,8 @@
 package server
 
 import (
+	"encoding/json"
 	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
 	"net/http"
 
 	"github.com/bitcoin-sv/go-paymail"
@@ -22,15 +22,15 @@ type p2pDestinationRequestBody struct {
 // p2pDestination will return an output script(s) for a destination (used with SendP2PTransaction)
 //
 // Specs: https://docs.moneybutton.com/docs/paymail-07-p2p-payment-destination.html
-func (c *Configuration) p2pDestination(context *gin.Context) {
+func (c *Configuration) p2pDestination(w http.ResponseWriter, req *http.Request) {
 	var b p2pDestinationRequestBody
-	err := context.Bind(&b)
+	err := json.NewDecoder(req.Body).Decode(&b)
 	if err != nil {
-		errors.ErrorResponse(context, errors.ErrCannotBindRequest, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCannotBindRequest, c.Logger)
 		return
 	}
 
-	alias, domain, md, ok := c.GetPaymailAndCreateMetadata(context, b.Satoshis)
+	alias, domain, md, ok := c.GetPaymailAndCreateMetadata(w, req, b.Satoshis)
 	if !ok {
 		// ErrorResponse already set up in GetPaymailAndCreateMetadata
 		return
@@ -38,11 +38,22 @@ func (c *Configuration) p2pDestination(context *gin.Context) {
 
 	var response *paymail.PaymentDestinationPayload
 	if response, err = c.actions.CreateP2PDestinationResponse(
-		context.Request.Context(), alias, domain, b.Satoshis, md,
+		req.Context(), alias, domain, b.Satoshis, md,
 	); err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	context.JSON(http.StatusOK, response)
+	if response != nil {
+		record := &ReferenceRecord{Alias: alias, Domain: domain, Reference: response.Reference, Satoshis: b.Satoshis}
+		for _, output := range response.Outputs {
+			record.Outputs = append(record.Outputs, &ReferenceOutput{Satoshis: output.Satoshis, Script: output.Script})
+		}
+		if err = c.saveReference(req.Context(), record); err != nil {
+			errors.WriteErrorResponse(w, err, c.Logger)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
@@ -1,11 +1,12 @@
 package server
 
 import (
-	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
+	stderrors "errors"
 	"net/http"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
 	"github.com/bitcoin-sv/go-paymail/spv"
 )
 
@@ -33,14 +34,14 @@ Incoming Data Object Example:
 // p2pReceiveTx will receive a P2P transaction (from previous request: P2P Payment Destination)
 //
 // Specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html
-func (c *Configuration) p2pReceiveTx(context *gin.Context) {
+func (c *Configuration) p2pReceiveTx(w http.ResponseWriter, req *http.Request) {
 	p2pFormat := basicP2pPayload
 
-	incomingPaymail := context.Param(PaymailAddressParamName)
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
 
-	requestPayload, _, md, err := processP2pReceiveTxRequest(c, context.Request, incomingPaymail, p2pFormat)
+	requestPayload, _, md, err := processP2pReceiveTxRequest(c, req, incomingPaymail, p2pFormat)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
@@ -49,14 +50,14 @@ func (c *Configuration) p2pReceiveTx(context *gin.Context) {
 	}
 
 	var response *paymail.P2PTransactionPayload
-	if response, err = c.actions.RecordTransaction(
-		context.Request.Context(), requestPayload.P2PTransaction, md,
+	if response, err = c.recordTransaction(
+		req.Context(), requestPayload, md, nil,
 	); err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	context.JSON(http.StatusOK, response)
+	writeJSON(w, http.StatusOK, response)
 }
 
 /*
@@ -73,13 +74,13 @@ Incoming Data Object Example:
 }
 */
 // p2pReceiveBeefTx will receive a P2P transaction in BEEF format
-func (c *Configuration) p2pReceiveBeefTx(context *gin.Context) {
+func (c *Configuration) p2pReceiveBeefTx(w http.ResponseWriter, req *http.Request) {
 	p2pFormat := beefP2pPayload
-	incomingPaymail := context.Param(PaymailAddressParamName)
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
 
-	requestPayload, dBeef, md, err := processP2pReceiveTxRequest(c, context.Request, incomingPaymail, p2pFormat)
+	requestPayload, dBeef, md, err := processP2pReceiveTxRequest(c, req, incomingPaymail, p2pFormat)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
@@ -91,19 +92,40 @@ func (c *Configuration) p2pReceiveBeefTx(context *gin.Context) {
 		panic("empty beef after parsing!")
 	}
 
-	err = spv.ExecuteSimplifiedPaymentVerification(context.Request.Context(), dBeef, c.actions)
-	if err != nil {
-		errors.ErrorResponse(context, errors.ErrSPVFailed, c.Logger)
-		return
+	verify := func() error {
+		return c.verifyBeef(req, dBeef, md)
 	}
 
 	var response *paymail.P2PTransactionPayload
-	if response, err = c.actions.RecordTransaction(
-		context.Request.Context(), requestPayload.P2PTransaction, md,
+	if response, err = c.recordTransaction(
+		req.Context(), requestPayload, md, verify,
 	); err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	context.JSON(http.StatusOK, response)
+	writeJSON(w, http.StatusOK, response)
+}
+
+// verifyBeef will execute the SPV and log the report on failure, the transactions to queue are set in the metadata
+//
+// The senders get errors.ErrSPVFailed unless the failure details are enabled (WithSPVFailureDetails)
+func (c *Configuration) verifyBeef(req *http.Request, dBeef *beef.DecodedBEEF, md *RequestMetadata) error {
+	report, err := spv.Verify(req.Context(), dBeef,
+		spv.WithMerkleRootVerifier(c.actions), spv.WithPolicy(c.SPVPolicy), spv.WithChainTip(c.chainTip),
+	)
+	if err == nil {
+		if report.Queue {
+			md.NonFinalTxIDs = report.NonFinalTxIDs()
+		}
+		return nil
+	}
+
+	c.Logger.Warn().Err(err).Interface("spv_report", report).Msg("simplified payment verification has failed")
+
+	var spvErr errors.ExtendedError
+	if c.SPVFailureDetailsEnabled && stderrors.As(err, &spvErr) {
+		return spvErr
+	}
+	return errors.ErrSPVFailed
 }
@@ -2,13 +2,19 @@ package server
 
 import (
 	"encoding/json"
+	stderrors "errors"
 	"net/http"
+	"strings"
 
 	"github.com/bitcoin-sv/go-paymail/errors"
 
 	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
 )
 
+// p2pRequestOverhead is the room for the request fields other than the hex or BEEF
+const p2pRequestOverhead = 64 << 10
+
 func parseP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPaymail string, format p2pPayloadFormat) (*p2pReceiveTxReqPayload, error) {
 	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
 	if len(paymailAddress) == 0 {
@@ -23,9 +29,16 @@ func parseP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPayma
 		incomingPaymailDomain: domain,
 	}
 
+	body := req.Body
+	if c.BEEFLimits.MaxBytes > 0 {
+		body = http.MaxBytesReader(nil, req.Body, 2*c.BEEFLimits.MaxBytes+p2pRequestOverhead)
+	}
+
 	var p2pTransaction paymail.P2PTransaction
-	err := json.NewDecoder(req.Body).Decode(&p2pTransaction)
-	if err != nil {
+	err := json.NewDecoder(body).Decode(&p2pTransaction)
+	if maxBytesErr := new(http.MaxBytesError); stderrors.As(err, &maxBytesErr) {
+		return nil, errors.ErrRequestTooLarge
+	} else if err != nil {
 		return nil, errors.ErrCannotBindRequest
 	}
 	if len(p2pTransaction.Reference) == 0 {
@@ -46,10 +59,31 @@ func parseP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPayma
 		return nil, vErr
 	}
 
+	// The decoded BEEF is never taken from the request
+	p2pTransaction.DecodedBeef = nil
+	if format == beefP2pPayload {
+		if p2pTransaction.DecodedBeef, err = decodeBEEF(c, p2pTransaction.Beef); err != nil {
+			return nil, err
+		}
+	}
+
 	requestData.P2PTransaction = &p2pTransaction
 	return &requestData, nil
 }
 
+// decodeBEEF will decode the BEEF within the configured limits
+func decodeBEEF(c *Configuration, beefHex string) (*beef.DecodedBEEF, error) {
+	decoded, err := beef.NewDecoder(strings.NewReader(beefHex), c.BEEFLimits).Decode()
+	if stderrors.Is(err, beef.ErrLimitExceeded) {
+		c.Logger.Warn().Msgf("beef exceeds the limits: %s", err.Error())
+		return nil, errors.ErrBEEFLimitExceeded
+	} else if err != nil {
+		c.Logger.Error().Msgf("error while parsing beef: %s", err.Error())
+		return nil, errors.ErrProcessingBEEF
+	}
+	return decoded, nil
+}
+
 func validateMetadata(c *Configuration, metadata *paymail.P2PMetaData) error {
 	// Check signature if: 1) sender validation enabled or 2) a signature was given (optional)
 	if c.SenderValidationEnabled || len(metadata.Signature) > 0 {
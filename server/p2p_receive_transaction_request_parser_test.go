@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// testBEEFHex will return a BEEF with a mined parent (the BUMP is not a valid merkle path)
+func testBEEFHex(t *testing.T) string {
+	parent := testTx(t, 2000)
+	tx := testTx(t, 1000)
+	tx.AddInputFromTx(parent, 0, nil)
+
+	bump := &beef.BUMP{BlockHeight: 1, Path: [][]beef.BUMPLeaf{{{Hash: parent.TxID().String(), TxId: true}}}}
+	decoded, err := beef.NewBuilder(tx).AddAncestor(parent, bump).Build()
+	require.NoError(t, err)
+
+	beefHex, err := decoded.Hex()
+	require.NoError(t, err)
+	return beefHex
+}
+
+// TestParseP2pReceiveTxRequest_BEEFLimits will test rejecting malformed and oversized BEEF requests
+func TestParseP2pReceiveTxRequest_BEEFLimits(t *testing.T) {
+	t.Parallel()
+
+	const receivePath = "/v1/bsvalias/beef/mrz@test.com"
+	beefHex := testBEEFHex(t)
+
+	handler := func(t *testing.T, limits beef.Limits) http.Handler {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(&referenceServiceProvider{})
+
+		config, err := NewConfig(sl, WithDomain("test.com"), WithBeefCapabilities(), WithBEEFLimits(limits))
+		require.NoError(t, err)
+		return config.Handler()
+	}
+	transaction := func(beefHex string) *paymail.P2PTransaction {
+		return &paymail.P2PTransaction{Beef: beefHex, MetaData: &paymail.P2PMetaData{}, Reference: "test-reference"}
+	}
+
+	testCases := []struct {
+		name         string
+		beef         string
+		limits       beef.Limits
+		expectedCode int
+		expectedErr  errors.SPVError
+	}{
+		{
+			name:         "malformed beef",
+			beef:         "0100beef00",
+			limits:       beef.DefaultLimits,
+			expectedCode: http.StatusBadRequest,
+			expectedErr:  errors.ErrProcessingBEEF,
+		},
+		{
+			name:         "too many transactions",
+			beef:         beefHex,
+			limits:       beef.Limits{MaxTxs: 1},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedErr:  errors.ErrBEEFLimitExceeded,
+		},
+		{
+			name:         "beef too large",
+			beef:         beefHex,
+			limits:       beef.Limits{MaxBytes: int64(len(beefHex)/2 - 1)},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedErr:  errors.ErrBEEFLimitExceeded,
+		},
+		{
+			name:         "request body too large",
+			beef:         beefHex + strings.Repeat("00", p2pRequestOverhead),
+			limits:       beef.Limits{MaxBytes: int64(len(beefHex) / 2)},
+			expectedCode: http.StatusRequestEntityTooLarge,
+			expectedErr:  errors.ErrRequestTooLarge,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := postTestJSON(t, handler(t, tc.limits), receivePath, transaction(tc.beef))
+			assert.Equal(t, tc.expectedCode, recorder.Code)
+			assert.Equal(t, tc.expectedErr.Code, errorCode(t, recorder))
+		})
+	}
+
+	t.Run("beef within the limits is verified", func(t *testing.T) {
+		limits := beef.Limits{MaxBumps: 1, MaxBytes: int64(len(beefHex) / 2), MaxTreeHeight: 1, MaxTxs: 2}
+		recorder := postTestJSON(t, handler(t, limits), receivePath, transaction(beefHex))
+
+		// the parent is not signed, the request is decoded and fails on SPV
+		assert.Equal(t, errors.ErrSPVFailed.Code, errorCode(t, recorder))
+	})
+
+	t.Run("decoded beef is not taken from the request", func(t *testing.T) {
+		p2pTransaction := transaction(beefHex)
+		p2pTransaction.DecodedBeef = &beef.DecodedBEEF{Transactions: []*beef.TxData{{Transaction: sdk.NewTransaction()}}}
+
+		recorder := postTestJSON(t, handler(t, beef.DefaultLimits), receivePath, p2pTransaction)
+		assert.Equal(t, errors.ErrSPVFailed.Code, errorCode(t, recorder))
+	})
+}
@@ -18,6 +18,8 @@ import (
 type p2pReceiveTxReqPayload struct {
 	*paymail.P2PTransaction
 	incomingPaymailAlias, incomingPaymailDomain string
+	tx                                          *sdk.Transaction
+	txID                                        string
 }
 
 func processP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPaymail string, format p2pPayloadFormat) (
@@ -40,8 +42,9 @@ func processP2pReceiveTxRequest(c *Configuration, req *http.Request, incomingPay
 		return returnError(err)
 	}
 
+	payload.tx, payload.txID = tx, tx.TxID().String()
 	if c.SenderValidationEnabled || len(payload.MetaData.Signature) > 0 {
-		err = verifySignature(payload.MetaData, tx.TxID().String())
+		err = verifySignature(payload.MetaData, payload.txID)
 		if err != nil {
 			return returnError(err)
 		}
@@ -69,12 +72,7 @@ func getProcessedTxData(payload *p2pReceiveTxReqPayload, format p2pPayloadFormat
 		}
 
 	case beefP2pPayload:
-		beefData, err = beef.DecodeBEEF(payload.Beef)
-		if err != nil {
-			log.Error().Msgf("error while parsing beef: %s", err.Error())
-			return nil, nil, errors.ErrProcessingBEEF
-		}
-
+		beefData = payload.DecodedBeef // decoded within the limits by parseP2pReceiveTxRequest
 		processedTx = beefData.GetLatestTx()
 
 	default:
@@ -98,6 +96,10 @@ func verifyIncomingPaymail(ctx context.Context, c *Configuration, md *RequestMet
 	return nil
 }
 
+// verifySignature will verify the signature of the txid with the public key of the metadata
+//
+// The signature is base64 encoded (specs: https://docs.moneybutton.com/docs/paymail-06-p2p-transactions.html),
+// as sent by the client (SendP2PTransaction, Pay), the encoded string itself is not a signature
 func verifySignature(metadata *paymail.P2PMetaData, txID string) error {
 	// Get the address from pubKey
 	var rawAddress *script.Address
@@ -107,8 +109,14 @@ func verifySignature(metadata *paymail.P2PMetaData, txID string) error {
 		return errors.ErrInvalidPubKey
 	}
 
+	// Decode the signature (base64 encoded, as per the specification)
+	var signature []byte
+	if signature, err = paymail.DecodeSignature(metadata.Signature); err != nil {
+		return errors.ErrInvalidSignature
+	}
+
 	// Validate the signature of the tx id
-	if err = bsm.VerifyMessage(rawAddress.AddressString, []byte(metadata.Signature), []byte(txID)); err != nil {
+	if err = bsm.VerifyMessage(rawAddress.AddressString, signature, []byte(txID)); err != nil {
 		return errors.ErrInvalidSignature
 	}
 
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/hex"
+	"net/http"
+	"testing"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test_verifySignature will test the method verifySignature()
+func Test_verifySignature(t *testing.T) {
+	t.Parallel()
+
+	const txID = "f3ddfabf7a7a84cfa20016e61df24dff32953d4023a3002cb5a98d6da4ef9bf1"
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	signature, err := bsm.SignMessage(key, []byte(txID))
+	require.NoError(t, err)
+
+	t.Run("valid base64 signature", func(t *testing.T) {
+		err := verifySignature(&paymail.P2PMetaData{
+			PublicKey: key.PubKey().ToDERHex(),
+			Signature: paymail.EncodeSignature(signature),
+		}, txID)
+		require.NoError(t, err)
+	})
+
+	t.Run("signature of another txid", func(t *testing.T) {
+		err := verifySignature(&paymail.P2PMetaData{
+			PublicKey: key.PubKey().ToDERHex(),
+			Signature: paymail.EncodeSignature(signature),
+		}, "00"+txID[2:])
+		require.ErrorIs(t, err, errors.ErrInvalidSignature)
+	})
+
+	t.Run("signature is not base64", func(t *testing.T) {
+		err := verifySignature(&paymail.P2PMetaData{
+			PublicKey: key.PubKey().ToDERHex(),
+			Signature: "not-base64!",
+		}, txID)
+		require.ErrorIs(t, err, errors.ErrInvalidSignature)
+	})
+
+	t.Run("invalid public key", func(t *testing.T) {
+		err := verifySignature(&paymail.P2PMetaData{
+			PublicKey: "invalid",
+			Signature: paymail.EncodeSignature(signature),
+		}, txID)
+		require.ErrorIs(t, err, errors.ErrInvalidPubKey)
+	})
+}
+
+// TestConfiguration_ReceiveSignedTransaction will test the signature of the metadata of the received transaction
+func TestConfiguration_ReceiveSignedTransaction(t *testing.T) {
+	t.Parallel()
+
+	const receivePath = "/v1/bsvalias/receive-transaction/mrz@test.com"
+
+	provider := &referenceServiceProvider{}
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(provider)
+	config, err := NewConfig(sl, WithDomain("test.com"), WithP2PCapabilities(), WithSenderValidation())
+	require.NoError(t, err)
+	handler := config.Handler()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	tx := testTx(t, 1000)
+	signature, err := bsm.SignMessage(key, []byte(tx.TxID().String()))
+	require.NoError(t, err)
+
+	transaction := func(signature string) *paymail.P2PTransaction {
+		return &paymail.P2PTransaction{
+			Hex:       tx.String(),
+			MetaData:  &paymail.P2PMetaData{PublicKey: key.PubKey().ToDERHex(), Signature: signature},
+			Reference: "reference",
+		}
+	}
+
+	t.Run("base64 signature is accepted", func(t *testing.T) {
+		recorder := postTestJSON(t, handler, receivePath, transaction(paymail.EncodeSignature(signature)))
+		require.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("hex signature is rejected", func(t *testing.T) {
+		recorder := postTestJSON(t, handler, receivePath, transaction(hex.EncodeToString(signature)))
+		require.Equal(t, errors.ErrInvalidSignature.StatusCode, recorder.Code)
+		assert.Equal(t, errors.ErrInvalidSignature.Code, errorCode(t, recorder))
+	})
+}
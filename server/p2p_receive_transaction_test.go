@@ -0,0 +1,145 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+	"github.com/bitcoin-sv/go-paymail/spv"
+)
+
+// TestP2pReceiveBeefTx_SPVFailureDetails will test the SPV failure returned to the sender
+func TestP2pReceiveBeefTx_SPVFailureDetails(t *testing.T) {
+	t.Parallel()
+
+	const receivePath = "/v1/bsvalias/beef/mrz@test.com"
+	transaction := &paymail.P2PTransaction{Beef: testBEEFHex(t), MetaData: &paymail.P2PMetaData{}, Reference: "test-reference"}
+
+	handler := func(t *testing.T, logs *bytes.Buffer, opts ...ConfigOps) http.Handler {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(&referenceServiceProvider{})
+
+		logger := zerolog.New(logs)
+		config, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com"), WithBeefCapabilities(), WithLogger(&logger)}, opts...)...)
+		require.NoError(t, err)
+		return config.Handler()
+	}
+
+	t.Run("generic failure by default", func(t *testing.T) {
+		var logs bytes.Buffer
+		recorder := postTestJSON(t, handler(t, &logs), receivePath, transaction)
+
+		assert.Equal(t, errors.ErrSPVFailed.StatusCode, recorder.Code)
+		assert.Equal(t, errors.ErrSPVFailed.Code, errorCode(t, recorder))
+		assert.Contains(t, logs.String(), `"spv_report":{`)
+		assert.Contains(t, logs.String(), `"scriptValid":false`)
+	})
+
+	t.Run("precise failure", func(t *testing.T) {
+		var logs bytes.Buffer
+		recorder := postTestJSON(t, handler(t, &logs, WithSPVFailureDetails()), receivePath, transaction)
+
+		// the test parent has no inputs (the first failure)
+		assert.Equal(t, errors.ErrNoInputs.StatusCode, recorder.Code)
+		assert.Equal(t, errors.ErrNoInputs.Code, errorCode(t, recorder))
+		assert.Contains(t, logs.String(), `"spv_report":{`)
+	})
+}
+
+// TestConfiguration_VerifyBeef_NonFinal will test the lock time evaluation of the received transactions
+func TestConfiguration_VerifyBeef_NonFinal(t *testing.T) {
+	t.Parallel()
+
+	const lockTime = 850000
+	decoded := testLockedBEEF(t, lockTime)
+	req := httptest.NewRequest(http.MethodPost, "/v1/bsvalias/beef/mrz@test.com", nil)
+
+	config := func(t *testing.T, opts ...ConfigOps) *Configuration {
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(new(mockServiceProvider))
+		c, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com")}, opts...)...)
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("rejected by default", func(t *testing.T) {
+		md := &RequestMetadata{}
+		err := config(t, WithChainTip(&testChainTip{height: lockTime - 1})).verifyBeef(req, decoded, md)
+		require.Equal(t, errors.ErrSPVFailed, err)
+
+		err = config(t, WithSPVFailureDetails()).verifyBeef(req, decoded, md)
+		require.Equal(t, errors.ErrLockTimeAndSequence, err)
+	})
+
+	t.Run("final at the chain tip", func(t *testing.T) {
+		md := &RequestMetadata{}
+		err := config(t, WithChainTip(&testChainTip{height: lockTime})).verifyBeef(req, decoded, md)
+		require.NoError(t, err)
+		assert.Empty(t, md.NonFinalTxIDs)
+	})
+
+	t.Run("queued until final", func(t *testing.T) {
+		md := &RequestMetadata{}
+		err := config(t,
+			WithChainTip(&testChainTip{height: lockTime - 1}),
+			WithSPVPolicy(spv.Policy{NonFinal: spv.NonFinalQueue}),
+		).verifyBeef(req, decoded, md)
+		require.NoError(t, err)
+		assert.Equal(t, []string{decoded.GetLatestTx().TxID().String()}, md.NonFinalTxIDs)
+	})
+}
+
+// testLockedBEEF will return a BEEF with a signed transaction locked until the height, spending a mined parent
+func testLockedBEEF(t *testing.T, lockTime uint32) *beef.DecodedBEEF {
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+
+	parent := sdk.NewTransaction()
+	parent.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{1}, SequenceNumber: 0xffffffff})
+	parent.AddOutput(&sdk.TransactionOutput{Satoshis: 10000, LockingScript: lockingScript})
+
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+	tx := sdk.NewTransaction()
+	tx.LockTime = lockTime
+	tx.AddInputFromTx(parent, 0, unlocker)
+	tx.Inputs[0].SequenceNumber = 0
+	tx.AddOutput(&sdk.TransactionOutput{Satoshis: 9000, LockingScript: lockingScript})
+	require.NoError(t, tx.Sign())
+
+	bump := &beef.BUMP{BlockHeight: 800000, Path: [][]beef.BUMPLeaf{{
+		{Hash: parent.TxID().String(), TxId: true},
+		{Hash: chainhash.Hash{2}.String(), Offset: 1},
+	}}}
+	decoded, err := beef.NewBuilder(tx).AddAncestor(parent, bump).Build()
+	require.NoError(t, err)
+	return decoded
+}
+
+// testChainTip is a spv.ChainTip at the height
+type testChainTip struct {
+	height uint32
+}
+
+// CurrentTip will return the tip at the height
+func (c *testChainTip) CurrentTip(_ context.Context) (*spv.Tip, error) {
+	return &spv.Tip{Height: c.height}, nil
+}
@@ -1,86 +1,289 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
-	"github.com/bitcoin-sv/go-paymail/errors"
+	"fmt"
 	"net/http"
 
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+
 	"github.com/bitcoin-sv/go-paymail"
-	"github.com/gin-gonic/gin"
+	"github.com/bitcoin-sv/go-paymail/errors"
 )
 
-func (c *Configuration) pikeNewContact(rc *gin.Context) {
-	receiverPaymail := rc.Param(PaymailAddressParamName)
+func (c *Configuration) pikeNewContact(w http.ResponseWriter, req *http.Request) {
+	receiverPaymail := req.PathValue(PaymailAddressParamName)
 
 	var requesterContact paymail.PikeContactRequestPayload
-	err := json.NewDecoder(rc.Request.Body).Decode(&requesterContact)
+	err := json.NewDecoder(req.Body).Decode(&requesterContact)
+	if err != nil {
+		errors.WriteErrorResponse(w, errors.ErrCannotBindRequest, c.Logger)
+		return
+	}
+
+	replayKey, err := c.verifyContactRequest(req.Context(), receiverPaymail, &requesterContact)
+	if err != nil {
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return
+	}
+
+	if err = c.pikeContactActions.AddContact(req.Context(), receiverPaymail, &requesterContact); err != nil {
+		c.releaseReplay(context.WithoutCancel(req.Context()), replayKey)
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// verifyContactRequest will verify the signature of the contact request with the PKI of the requester
+//
+// The signed requests are always verified (and accepted once if the replay guard is enabled),
+// the requests without a signature are accepted unless PikeContactSignatureRequired is set.
+// Returns the replay key of the accepted signed request (released if the request fails, empty if there is none)
+func (c *Configuration) verifyContactRequest(ctx context.Context, receiverPaymail string, contact *paymail.PikeContactRequestPayload) (string, error) {
+	if len(contact.Signature) == 0 {
+		if c.PikeContactSignatureRequired {
+			return "", errors.ErrMissingFieldSignature
+		}
+		return "", nil
+	}
+
+	if err := paymail.ValidatePaymail(contact.Paymail); err != nil {
+		return "", errors.ErrInvalidSenderHandle
+	}
+	dt, err := c.validateRequestTime(contact.Timestamp)
+	if err != nil {
+		return "", err
+	} else if len(contact.Nonce) == 0 {
+		return "", errors.ErrMissingFieldNonce
+	}
+
+	pki, err := c.getPKI(ctx, contact.Paymail)
 	if err != nil {
-		errors.ErrorResponse(rc, errors.ErrCannotBindRequest, c.Logger)
+		return "", err
+	}
+
+	pubKey, err := ec.PublicKeyFromString(pki.PubKey)
+	if err != nil {
+		return "", errors.ErrInvalidPubKey
+	}
+
+	if err = contact.Verify(pubKey, receiverPaymail); err != nil {
+		return "", errors.ErrInvalidSignature
+	}
+
+	key := pikeContactReplayKey(contact)
+	if err = c.guardReplay(ctx, key, dt.Add(c.ReplayWindow)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+// pikeAcceptContact will handle the contact accepting the contact request of the paymail
+func (c *Configuration) pikeAcceptContact(w http.ResponseWriter, req *http.Request) {
+	c.pikeAnswerContact(w, req, paymail.PikeContactActionAccept, c.pikeContactActions.AcceptContact)
+}
+
+// pikeRejectContact will handle the contact rejecting the contact request of the paymail
+func (c *Configuration) pikeRejectContact(w http.ResponseWriter, req *http.Request) {
+	c.pikeAnswerContact(w, req, paymail.PikeContactActionReject, c.pikeContactActions.RejectContact)
+}
+
+// pikeAnswerContact will pass the verified answer of the contact to the service provider
+func (c *Configuration) pikeAnswerContact(w http.ResponseWriter, req *http.Request, action paymail.PikeContactAction,
+	answer func(ctx context.Context, paymailAddress string, contact *paymail.PikeContactResponsePayload) error,
+) {
+	paymailAddress, contact, ok := c.bindContactResponse(w, req)
+	if !ok {
 		return
 	}
 
-	if err = c.pikeContactActions.AddContact(rc.Request.Context(), receiverPaymail, &requesterContact); err != nil {
-		errors.ErrorResponse(rc, err, c.Logger)
+	replayKey, err := c.verifyContactResponse(req.Context(), paymailAddress, action, contact)
+	if err != nil {
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	rc.Status(http.StatusCreated)
+	if err = answer(req.Context(), paymailAddress, contact); err != nil {
+		c.releaseReplay(context.WithoutCancel(req.Context()), replayKey)
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
 }
 
-func (c *Configuration) pikeGetOutputTemplates(rc *gin.Context) {
+// pikeContactStatus will return the status of the contact at the paymail (to the verified contact)
+func (c *Configuration) pikeContactStatus(w http.ResponseWriter, req *http.Request) {
+	paymailAddress, contact, ok := c.bindContactResponse(w, req)
+	if !ok {
+		return
+	}
+
+	replayKey, err := c.verifyContactResponse(req.Context(), paymailAddress, paymail.PikeContactActionStatus, contact)
+	if err != nil {
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return
+	}
+
+	status, err := c.pikeContactActions.GetContactStatus(req.Context(), paymailAddress, contact)
+	if err == nil && status == nil {
+		err = errors.ErrContactNotFound
+	}
+	if err != nil {
+		c.releaseReplay(context.WithoutCancel(req.Context()), replayKey)
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return
+	}
+
+	writeJSON(w, http.StatusOK, status)
+}
+
+// bindContactResponse will return the local paymail and the contact payload (not verified yet),
+// the error response is written if it is not valid
+func (c *Configuration) bindContactResponse(w http.ResponseWriter, req *http.Request) (string, *paymail.PikeContactResponsePayload, bool) {
+	alias, domain, paymailAddress := paymail.SanitizePaymail(req.PathValue(PaymailAddressParamName))
+	if len(paymailAddress) == 0 {
+		errors.WriteErrorResponse(w, errors.ErrInvalidPaymail, c.Logger)
+		return "", nil, false
+	} else if !c.IsAllowedDomain(domain) {
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
+		return "", nil, false
+	}
+
+	var contact paymail.PikeContactResponsePayload
+	if err := json.NewDecoder(req.Body).Decode(&contact); err != nil {
+		errors.WriteErrorResponse(w, errors.ErrCannotBindRequest, c.Logger)
+		return "", nil, false
+	}
+
+	if err := paymail.ValidatePaymail(contact.Paymail); err != nil {
+		errors.WriteErrorResponse(w, errors.ErrInvalidPaymail, c.Logger)
+		return "", nil, false
+	}
+
+	md := CreateMetadata(req, alias, domain, "")
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
+	if err != nil {
+		errors.WriteErrorResponse(w, err, c.Logger)
+		return "", nil, false
+	} else if foundPaymail == nil {
+		errors.WriteErrorResponse(w, errors.ErrCouldNotFindPaymail, c.Logger)
+		return "", nil, false
+	}
+	return paymailAddress, &contact, true
+}
+
+// verifyContactResponse will verify the signature of the contact payload with the PKI of the contact
+//
+// The payloads must be signed for the action (and are accepted once if the replay guard is enabled).
+// Returns the replay key of the accepted payload (released if the request fails)
+func (c *Configuration) verifyContactResponse(ctx context.Context, paymailAddress string, action paymail.PikeContactAction,
+	contact *paymail.PikeContactResponsePayload,
+) (string, error) {
+	if len(contact.Signature) == 0 {
+		return "", errors.ErrMissingFieldSignature
+	}
+
+	dt, err := c.validateRequestTime(contact.Timestamp)
+	if err != nil {
+		return "", err
+	} else if len(contact.Nonce) == 0 {
+		return "", errors.ErrMissingFieldNonce
+	}
+
+	pki, err := c.getPKI(ctx, contact.Paymail)
+	if err != nil {
+		return "", err
+	}
+
+	pubKey, err := ec.PublicKeyFromString(pki.PubKey)
+	if err != nil {
+		return "", errors.ErrInvalidPubKey
+	}
+
+	if err = contact.Verify(pubKey, action, paymailAddress); err != nil {
+		return "", errors.ErrInvalidSignature
+	}
+
+	key := pikeContactResponseReplayKey(contact)
+	if err = c.guardReplay(ctx, key, dt.Add(c.ReplayWindow)); err != nil {
+		return "", err
+	}
+	return key, nil
+}
+
+func (c *Configuration) pikeGetOutputTemplates(w http.ResponseWriter, req *http.Request) {
 	var paymentDestinationRequest paymail.PikePaymentOutputsPayload
-	err := json.NewDecoder(rc.Request.Body).Decode(&paymentDestinationRequest)
+	err := json.NewDecoder(req.Body).Decode(&paymentDestinationRequest)
 	defer func() {
-		_ = rc.Request.Body.Close()
+		_ = req.Body.Close()
 	}()
 	if err != nil {
-		errors.ErrorResponse(rc, errors.ErrCannotBindRequest, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCannotBindRequest, c.Logger)
 		return
 	}
 
-	alias, domain, md, ok := c.GetPaymailAndCreateMetadata(rc, paymentDestinationRequest.Amount)
+	alias, domain, md, ok := c.GetPaymailAndCreateMetadata(w, req, paymentDestinationRequest.Amount)
 	if !ok {
 		// ErrorResponse already set up in GetPaymailAndCreateMetadata
 		return
 	}
 
-	pki, err := getPKI(paymentDestinationRequest.SenderPaymail)
+	pki, err := c.getPKI(req.Context(), paymentDestinationRequest.SenderPaymail)
 	if err != nil {
-		errors.ErrorResponse(rc, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
 	var response *paymail.PikePaymentOutputsResponse
 	if response, err = c.pikePaymentActions.CreatePikeOutputResponse(
-		rc.Request.Context(), alias, domain, pki.PubKey, paymentDestinationRequest.Amount, md,
+		req.Context(), alias, domain, pki.PubKey, paymentDestinationRequest.Amount, md,
 	); err != nil {
-		errors.ErrorResponse(rc, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
-	rc.JSON(http.StatusOK, response)
+	if response != nil {
+		record := &ReferenceRecord{Alias: alias, Domain: domain, Reference: response.Reference, Satoshis: paymentDestinationRequest.Amount}
+		for _, output := range response.Outputs {
+			record.Outputs = append(record.Outputs, &ReferenceOutput{Satoshis: output.Satoshis, Script: output.Script})
+		}
+		if err = c.saveReference(req.Context(), record); err != nil {
+			errors.WriteErrorResponse(w, err, c.Logger)
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, response)
 }
 
-func getPKI(paymailAddress string) (*paymail.PKIResponse, error) {
+// getPKI will fetch the PKI of the paymail address (SRV record, capabilities and PKI)
+func (c *Configuration) getPKI(ctx context.Context, paymailAddress string) (*paymail.PKIResponse, error) {
 	alias, domain, paymailAddress := paymail.SanitizePaymail(paymailAddress)
 	if len(paymailAddress) == 0 {
 		return nil, errors.ErrInvalidPaymail
 	}
 
-	client, err := paymail.NewClient()
+	srv, err := c.paymailClient.GetSRVRecordCtx(ctx, paymail.DefaultServiceName, paymail.DefaultProtocol, domain)
 	if err != nil {
 		return nil, err
 	}
 
-	var capabilities *paymail.CapabilitiesResponse
-	if capabilities, err = client.GetCapabilities(domain, paymail.DefaultPort); err != nil {
+	capabilities, err := c.paymailClient.GetCapabilitiesCtx(ctx, srv.Target, int(srv.Port))
+	if err != nil {
 		return nil, err
 	}
 
 	pkiURL := capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate)
+	if len(pkiURL) == 0 {
+		return nil, fmt.Errorf("%w: %s", paymail.ErrCapabilityMissing, paymail.BRFCPki)
+	}
 
 	var pki *paymail.PKIResponse
-	if pki, err = client.GetPKI(pkiURL, alias, domain); err != nil {
+	if pki, err = c.paymailClient.GetPKICtx(ctx, pkiURL, alias, domain); err != nil {
 		return nil, err
 	}
 	return pki, nil
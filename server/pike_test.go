@@ -0,0 +1,378 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// TestConfiguration_PikeContactLifecycle will test the PIKE contact accept, reject and status capabilities
+func TestConfiguration_PikeContactLifecycle(t *testing.T) {
+	t.Parallel()
+
+	const (
+		invitePath = "/v1/bsvalias/contact/invite/mrz@test.com"
+		acceptPath = "/v1/bsvalias/contact/accept/mrz@test.com"
+		rejectPath = "/v1/bsvalias/contact/reject/mrz@test.com"
+		statusPath = "/v1/bsvalias/contact/status/mrz@test.com"
+	)
+
+	t.Run("capabilities", func(t *testing.T) {
+		handler, _ := testPikeContactHandler(t)
+		recorder := serveTestRequest(handler, http.MethodGet, "/.well-known/"+paymail.DefaultServiceName)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		var payload paymail.CapabilitiesPayload
+		require.NoError(t, json.NewDecoder(recorder.Body).Decode(&payload))
+		pike, ok := payload.Capabilities[paymail.BRFCPike].(map[string]any)
+		require.True(t, ok)
+		assert.Equal(t, "https://test.com/v1/bsvalias/contact/accept/"+PaymailAddressTemplate, pike[paymail.BRFCPikeAccept])
+		assert.Contains(t, pike, paymail.BRFCPikeReject)
+		assert.Contains(t, pike, paymail.BRFCPikeStatus)
+	})
+
+	t.Run("invite, accept and reject", func(t *testing.T) {
+		handler, provider := testPikeContactHandler(t)
+
+		recorder := postTestJSON(t, handler, invitePath, &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: testContactPaymail})
+		require.Equal(t, http.StatusCreated, recorder.Code)
+		assert.Equal(t, paymail.PikeContactStatusPending, contactStatus(t, handler, statusPath, provider.contactKey))
+
+		recorder = postTestJSON(t, handler, acceptPath, signedContactResponse(t, provider.contactKey, paymail.PikeContactActionAccept))
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, paymail.PikeContactStatusAccepted, contactStatus(t, handler, statusPath, provider.contactKey))
+
+		recorder = postTestJSON(t, handler, rejectPath, signedContactResponse(t, provider.contactKey, paymail.PikeContactActionReject))
+		require.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, errors.ErrContactStatusInvalid.Code, errorCode(t, recorder))
+		assert.Equal(t, "mrz@test.com", provider.lastPaymail)
+	})
+
+	t.Run("unknown contact", func(t *testing.T) {
+		handler, provider := testPikeContactHandler(t)
+
+		recorder := postTestJSON(t, handler, statusPath, signedContactResponse(t, provider.contactKey, paymail.PikeContactActionStatus))
+		require.Equal(t, http.StatusNotFound, recorder.Code)
+		assert.Equal(t, errors.ErrContactNotFound.Code, errorCode(t, recorder))
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		handler, _ := testPikeContactHandler(t)
+
+		recorder := postTestJSON(t, handler, acceptPath, &paymail.PikeContactResponsePayload{Paymail: "invalid"})
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, errors.ErrInvalidPaymail.Code, errorCode(t, recorder))
+
+		req := httptest.NewRequest(http.MethodPost, rejectPath, bytes.NewReader([]byte("{")))
+		req.Host = "test.com"
+		recorder = httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		require.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, errors.ErrCannotBindRequest.Code, errorCode(t, recorder))
+	})
+}
+
+// TestConfiguration_PikeSignedContactResponse will test the verification of the signed PIKE contact answers and status requests
+func TestConfiguration_PikeSignedContactResponse(t *testing.T) {
+	t.Parallel()
+
+	const acceptPath = "/v1/bsvalias/contact/accept/mrz@test.com"
+	otherKey, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name     string
+		path     string
+		contact  func(t *testing.T, key *ec.PrivateKey) *paymail.PikeContactResponsePayload
+		expected errors.SPVError
+	}{
+		{
+			name: "unknown local paymail",
+			path: "/v1/bsvalias/contact/accept/satchmo@test.com",
+			contact: func(t *testing.T, key *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				return signedContactResponse(t, key, paymail.PikeContactActionAccept)
+			},
+			expected: errors.ErrCouldNotFindPaymail,
+		},
+		{
+			name: "unknown domain",
+			path: "/v1/bsvalias/contact/accept/mrz@other.com",
+			contact: func(t *testing.T, key *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				return signedContactResponse(t, key, paymail.PikeContactActionAccept)
+			},
+			expected: errors.ErrDomainUnknown,
+		},
+		{
+			name: "unsigned",
+			contact: func(_ *testing.T, _ *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				return &paymail.PikeContactResponsePayload{Paymail: testContactPaymail}
+			},
+			expected: errors.ErrMissingFieldSignature,
+		},
+		{
+			name: "expired timestamp",
+			contact: func(t *testing.T, key *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				contact := &paymail.PikeContactResponsePayload{Paymail: testContactPaymail, Timestamp: "2020-04-09T16:08:06Z"}
+				require.NoError(t, contact.Sign(key, paymail.PikeContactActionAccept, "mrz@test.com"))
+				return contact
+			},
+			expected: errors.ErrTimestampExpired,
+		},
+		{
+			name: "signed by another key",
+			contact: func(t *testing.T, _ *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				return signedContactResponse(t, otherKey, paymail.PikeContactActionAccept)
+			},
+			expected: errors.ErrInvalidSignature,
+		},
+		{
+			name: "signed for another action",
+			contact: func(t *testing.T, key *ec.PrivateKey) *paymail.PikeContactResponsePayload {
+				return signedContactResponse(t, key, paymail.PikeContactActionReject)
+			},
+			expected: errors.ErrInvalidSignature,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, provider := testPikeContactHandler(t)
+			require.NoError(t, provider.setStatus("mrz@test.com", testContactPaymail, "", paymail.PikeContactStatusPending))
+
+			path := tc.path
+			if len(path) == 0 {
+				path = acceptPath
+			}
+			recorder := postTestJSON(t, handler, path, tc.contact(t, provider.contactKey))
+			require.Equal(t, tc.expected.StatusCode, recorder.Code)
+			assert.Equal(t, tc.expected.Code, errorCode(t, recorder))
+			assert.Equal(t, paymail.PikeContactStatusPending, provider.statuses["mrz@test.com "+testContactPaymail], "the contact is not answered")
+		})
+	}
+
+	t.Run("replayed answer is rejected", func(t *testing.T) {
+		handler, provider := testPikeContactHandler(t, WithReplayGuard(NewMemoryReplayStore(), 0))
+		const statusPath = "/v1/bsvalias/contact/status/mrz@test.com"
+		require.NoError(t, provider.setStatus("mrz@test.com", testContactPaymail, "", paymail.PikeContactStatusPending))
+		contact := signedContactResponse(t, provider.contactKey, paymail.PikeContactActionStatus)
+
+		recorder := postTestJSON(t, handler, statusPath, contact)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = postTestJSON(t, handler, statusPath, contact)
+		require.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, errors.ErrRequestReplayed.Code, errorCode(t, recorder))
+	})
+}
+
+// TestConfiguration_PikeSignedContactRequest will test the verification of the signed PIKE contact requests
+//
+// The verification of valid signatures (resolving the PKI of the requester) is tested with the tester.PaymailServer
+func TestConfiguration_PikeSignedContactRequest(t *testing.T) {
+	t.Parallel()
+
+	const invitePath = "/v1/bsvalias/contact/invite/mrz@test.com"
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	signed := func(t *testing.T, modify func(r *paymail.PikeContactRequestPayload)) *paymail.PikeContactRequestPayload {
+		request := &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: "bob@example.com"}
+		require.NoError(t, request.Sign(key, "mrz@test.com"))
+		modify(request)
+		return request
+	}
+
+	tcs := []struct {
+		name     string
+		opts     []ConfigOps
+		request  *paymail.PikeContactRequestPayload
+		expected errors.SPVError
+	}{
+		{
+			name:     "signature required",
+			opts:     []ConfigOps{WithPikeContactSignatureRequired()},
+			request:  &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: "bob@example.com"},
+			expected: errors.ErrMissingFieldSignature,
+		},
+		{
+			name:     "expired timestamp",
+			request:  signed(t, func(r *paymail.PikeContactRequestPayload) { r.Timestamp = "2020-04-09T16:08:06Z" }),
+			expected: errors.ErrTimestampExpired,
+		},
+		{
+			name:     "invalid timestamp",
+			request:  signed(t, func(r *paymail.PikeContactRequestPayload) { r.Timestamp = "invalid" }),
+			expected: errors.ErrInvalidTimestamp,
+		},
+		{
+			name:     "missing nonce",
+			request:  signed(t, func(r *paymail.PikeContactRequestPayload) { r.Nonce = "" }),
+			expected: errors.ErrMissingFieldNonce,
+		},
+		{
+			name:     "invalid requester",
+			request:  signed(t, func(r *paymail.PikeContactRequestPayload) { r.Paymail = "invalid" }),
+			expected: errors.ErrInvalidSenderHandle,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			handler, provider := testPikeContactHandler(t, tc.opts...)
+
+			recorder := postTestJSON(t, handler, invitePath, tc.request)
+			require.Equal(t, tc.expected.StatusCode, recorder.Code)
+			assert.Equal(t, tc.expected.Code, errorCode(t, recorder))
+			assert.Empty(t, provider.statuses, "the contact is not added")
+		})
+	}
+
+	t.Run("unsigned request is accepted by default", func(t *testing.T) {
+		handler, _ := testPikeContactHandler(t)
+
+		recorder := postTestJSON(t, handler, invitePath, &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: "bob@example.com"})
+		require.Equal(t, http.StatusCreated, recorder.Code)
+	})
+}
+
+// testContactPaymail is the paymail of the contact (resolved by the pkiPaymailClient)
+const testContactPaymail = "bob@example.com"
+
+// signedContactResponse will return the contact payload signed for the action at mrz@test.com
+func signedContactResponse(t *testing.T, key *ec.PrivateKey, action paymail.PikeContactAction) *paymail.PikeContactResponsePayload {
+	contact := &paymail.PikeContactResponsePayload{Paymail: testContactPaymail}
+	require.NoError(t, contact.Sign(key, action, "mrz@test.com"))
+	return contact
+}
+
+// contactStatus will request the status of the contact (signed with the key of the contact)
+func contactStatus(t *testing.T, handler http.Handler, path string, key *ec.PrivateKey) paymail.PikeContactStatus {
+	recorder := postTestJSON(t, handler, path, signedContactResponse(t, key, paymail.PikeContactActionStatus))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var status paymail.PikeContactStatusPayload
+	require.NoError(t, json.NewDecoder(recorder.Body).Decode(&status))
+	assert.Equal(t, testContactPaymail, status.Paymail)
+	return status.Status
+}
+
+// testPikeContactHandler will return the handler with the PIKE contact capabilities and the contact provider
+//
+// The local paymail is mrz@test.com, the PKI of the contact (testContactPaymail) is the key of the provider
+func testPikeContactHandler(t *testing.T, opts ...ConfigOps) (http.Handler, *contactServiceProvider) {
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	provider := &contactServiceProvider{contactKey: key, statuses: make(map[string]paymail.PikeContactStatus)}
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(&localPaymailProvider{alias: "mrz"})
+	sl.RegisterPikeContactService(provider)
+
+	client := &pkiPaymailClient{keys: map[string]*ec.PublicKey{testContactPaymail: key.PubKey()}}
+	config, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com"), WithPikeContactCapabilities(), WithPaymailClient(client)}, opts...)...)
+	require.NoError(t, err)
+	return config.Handler(), provider
+}
+
+// localPaymailProvider is a service provider with a single local paymail alias
+type localPaymailProvider struct {
+	mockServiceProvider
+	alias string
+	err   error // Error returned by GetPaymailByAlias (if set)
+}
+
+// GetPaymailByAlias will return the paymail of the alias (nil for other aliases)
+func (p *localPaymailProvider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *RequestMetadata) (*paymail.AddressInformation, error) {
+	if p.err != nil {
+		return nil, p.err
+	} else if alias != p.alias {
+		return nil, nil
+	}
+	return &paymail.AddressInformation{Alias: alias, Domain: domain}, nil
+}
+
+// pkiPaymailClient is a paymail client resolving the PKI of the paymail addresses from their keys
+type pkiPaymailClient struct {
+	paymail.ClientInterface
+	keys map[string]*ec.PublicKey
+}
+
+// GetSRVRecordCtx will return the domain as the target
+func (c *pkiPaymailClient) GetSRVRecordCtx(_ context.Context, _, _, domain string) (*net.SRV, error) {
+	return &net.SRV{Target: domain, Port: paymail.DefaultPort}, nil
+}
+
+// GetCapabilitiesCtx will return the PKI capability
+func (c *pkiPaymailClient) GetCapabilitiesCtx(_ context.Context, target string, _ int) (*paymail.CapabilitiesResponse, error) {
+	return &paymail.CapabilitiesResponse{CapabilitiesPayload: paymail.CapabilitiesPayload{
+		Capabilities: map[string]interface{}{paymail.BRFCPki: "https://" + target + "/id/{alias}@{domain.tld}"},
+	}}, nil
+}
+
+// GetPKICtx will return the PKI of the paymail address
+func (c *pkiPaymailClient) GetPKICtx(_ context.Context, _, alias, domain string) (*paymail.PKIResponse, error) {
+	key, ok := c.keys[alias+"@"+domain]
+	if !ok {
+		return nil, errors.ErrCouldNotFindPaymail
+	}
+	return &paymail.PKIResponse{PKIPayload: paymail.PKIPayload{Handle: alias + "@" + domain, PubKey: key.ToDERHex()}}, nil
+}
+
+// contactServiceProvider is a PikeContactServiceProvider keeping the statuses in memory
+type contactServiceProvider struct {
+	addErr      error          // Error returned by AddContact (if set)
+	contactKey  *ec.PrivateKey // PKI key of the contact (testContactPaymail)
+	lastPaymail string
+	mu          sync.Mutex
+	statuses    map[string]paymail.PikeContactStatus
+}
+
+func (p *contactServiceProvider) AddContact(_ context.Context, paymailAddress string, contact *paymail.PikeContactRequestPayload) error {
+	if p.addErr != nil {
+		return p.addErr
+	}
+	return p.setStatus(paymailAddress, contact.Paymail, "", paymail.PikeContactStatusPending)
+}
+
+func (p *contactServiceProvider) AcceptContact(_ context.Context, paymailAddress string, contact *paymail.PikeContactResponsePayload) error {
+	return p.setStatus(paymailAddress, contact.Paymail, paymail.PikeContactStatusPending, paymail.PikeContactStatusAccepted)
+}
+
+func (p *contactServiceProvider) RejectContact(_ context.Context, paymailAddress string, contact *paymail.PikeContactResponsePayload) error {
+	return p.setStatus(paymailAddress, contact.Paymail, paymail.PikeContactStatusPending, paymail.PikeContactStatusRejected)
+}
+
+func (p *contactServiceProvider) GetContactStatus(_ context.Context, paymailAddress string, contact *paymail.PikeContactResponsePayload) (*paymail.PikeContactStatusPayload, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	status, ok := p.statuses[paymailAddress+" "+contact.Paymail]
+	if !ok {
+		return nil, nil // the server responds with errors.ErrContactNotFound
+	}
+	return &paymail.PikeContactStatusPayload{Paymail: contact.Paymail, Status: status}, nil
+}
+
+// setStatus will change the status of the contact (from the expected status)
+func (p *contactServiceProvider) setStatus(paymailAddress, contactPaymail string, from, to paymail.PikeContactStatus) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.lastPaymail = paymailAddress
+
+	key := paymailAddress + " " + contactPaymail
+	if p.statuses[key] != from {
+		return errors.ErrContactStatusInvalid
+	}
+	p.statuses[key] = to
+	return nil
+}
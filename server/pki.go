@@ -2,7 +2,6 @@ package server
 
 import (
 	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
 	"net/http"
 
 	"github.com/bitcoin-sv/go-paymail"
@@ -11,26 +10,26 @@ import (
 // showPKI will return the public key information for the corresponding paymail address
 //
 // Specs: http://bsvalias.org/03-public-key-infrastructure.html
-func (c *Configuration) showPKI(context *gin.Context) {
-	incomingPaymail := context.Param(PaymailAddressParamName)
+func (c *Configuration) showPKI(w http.ResponseWriter, req *http.Request) {
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
 
 	alias, domain, address := paymail.SanitizePaymail(incomingPaymail)
 	if len(address) == 0 {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	} else if !c.IsAllowedDomain(domain) {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	}
 
-	md := CreateMetadata(context.Request, alias, domain, "")
+	md := CreateMetadata(req, alias, domain, "")
 
-	foundPaymail, err := c.actions.GetPaymailByAlias(context.Request.Context(), alias, domain, md)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	} else if foundPaymail == nil {
-		errors.ErrorResponse(context, errors.ErrCouldNotFindPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCouldNotFindPaymail, c.Logger)
 		return
 	}
 
@@ -40,5 +39,5 @@ func (c *Configuration) showPKI(context *gin.Context) {
 		PubKey:   foundPaymail.PubKey,
 	}
 
-	context.JSON(http.StatusOK, pkiPayload)
+	writeJSON(w, http.StatusOK, pkiPayload)
 }
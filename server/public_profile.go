@@ -2,7 +2,6 @@ package server
 
 import (
 	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
 	"net/http"
 
 	"github.com/bitcoin-sv/go-paymail"
@@ -11,29 +10,29 @@ import (
 // publicProfile will return the public profile for the corresponding paymail address
 //
 // Specs: https://github.com/bitcoin-sv-specs/brfc-paymail/pull/7/files
-func (c *Configuration) publicProfile(context *gin.Context) {
-	incomingPaymail := context.Param(PaymailAddressParamName)
+func (c *Configuration) publicProfile(w http.ResponseWriter, req *http.Request) {
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
 
 	// Parse, sanitize and basic validation
 	alias, domain, address := paymail.SanitizePaymail(incomingPaymail)
 	if len(address) == 0 {
-		errors.ErrorResponse(context, errors.ErrInvalidPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrInvalidPaymail, c.Logger)
 		return
 	} else if !c.IsAllowedDomain(domain) {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	}
 
 	// Create the metadata struct
-	md := CreateMetadata(context.Request, alias, domain, "")
+	md := CreateMetadata(req, alias, domain, "")
 
 	// Get from the data layer
-	foundPaymail, err := c.actions.GetPaymailByAlias(context.Request.Context(), alias, domain, md)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	} else if foundPaymail == nil {
-		errors.ErrorResponse(context, errors.ErrCouldNotFindPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCouldNotFindPaymail, c.Logger)
 		return
 	}
 
@@ -43,5 +42,5 @@ func (c *Configuration) publicProfile(context *gin.Context) {
 	}
 
 	// Set the response
-	context.JSON(http.StatusOK, payload)
+	writeJSON(w, http.StatusOK, payload)
 }
@@ -0,0 +1,189 @@
+package server
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// RateLimit is a budget of requests (token bucket), the zero RateLimit is unlimited
+type RateLimit struct {
+	Period   time.Duration `json:"period"`   // Period to refill the whole budget
+	Requests int           `json:"requests"` // Number of requests in the period (and the maximum burst)
+}
+
+// RateLimitBudget is the budget of a capability for each client IP and each target paymail address
+//
+// The client IP is the remote address of the connection. The X-Real-IP and X-Forwarded-For headers
+// are only used if RateLimits.TrustProxyHeaders is set: any client can set them, so trusting them
+// without a proxy overwriting them lets the clients bypass the IP budget.
+type RateLimitBudget struct {
+	Alias RateLimit `json:"alias"` // Budget for each target paymail address (all the clients)
+	IP    RateLimit `json:"ip"`    // Budget for each client IP (remote address, or proxy headers if trusted)
+}
+
+// RateLimits is the rate limiting of the capability endpoints
+type RateLimits struct {
+	Capabilities      map[string]RateLimitBudget `json:"capabilities"`        // Budgets of the capabilities (BRFC ID or key of the nested capability, e.g. paymail.BRFCPikeInvite)
+	Default           RateLimitBudget            `json:"default"`             // Budget of the capabilities without their own budget
+	TrustProxyHeaders bool                       `json:"trust_proxy_headers"` // Client IP from X-Real-IP / X-Forwarded-For (only behind a proxy setting them)
+}
+
+// budget will return the budget of the capability
+func (l RateLimits) budget(capability string) RateLimitBudget {
+	if budget, ok := l.Capabilities[capability]; ok {
+		return budget
+	}
+	return l.Default
+}
+
+// unlimited will return true if the rate limit is disabled
+func (l RateLimit) unlimited() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// RateLimitStore keeps the budgets of the rate limited keys
+//
+// Take must be atomic: concurrent requests cannot exceed the budget of the key
+type RateLimitStore interface {
+	// Take will take a request from the budget of the key
+	//
+	// Returns the time to wait for the next request if the budget is exhausted (zero if the request is allowed)
+	Take(ctx context.Context, key string, limit RateLimit) (time.Duration, error)
+}
+
+// tokenBucket is the budget of a key in the memoryRateLimitStore
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// memoryRateLimitStore is an in-memory token bucket implementation of the RateLimitStore
+type memoryRateLimitStore struct {
+	buckets   map[string]*tokenBucket
+	lastPrune time.Time
+	sync.Mutex
+}
+
+// NewMemoryRateLimitStore will return an in-memory RateLimitStore (token buckets)
+//
+// The budgets are local to the instance, use a shared store if the server runs multiple instances
+func NewMemoryRateLimitStore() RateLimitStore {
+	return &memoryRateLimitStore{
+		buckets:   make(map[string]*tokenBucket),
+		lastPrune: time.Now(),
+	}
+}
+
+// Take will take a request from the token bucket of the key
+func (m *memoryRateLimitStore) Take(_ context.Context, key string, limit RateLimit) (time.Duration, error) {
+	if limit.unlimited() {
+		return 0, nil
+	}
+
+	m.Lock()
+	defer m.Unlock()
+
+	now := time.Now()
+	m.prune(now)
+
+	capacity := float64(limit.Requests)
+	interval := limit.Period / time.Duration(limit.Requests) // time to refill one request
+	bucket, ok := m.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		m.buckets[key] = bucket
+	} else {
+		bucket.tokens = math.Min(capacity, bucket.tokens+float64(now.Sub(bucket.updated))/float64(interval))
+		bucket.updated = now
+	}
+
+	if bucket.tokens < 1 {
+		return time.Duration((1 - bucket.tokens) * float64(interval)), nil
+	}
+	bucket.tokens--
+	return 0, nil
+}
+
+// prune will remove the buckets which were not used for an hour (at most once a minute, lock must be held)
+func (m *memoryRateLimitStore) prune(now time.Time) {
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for key, bucket := range m.buckets {
+		if now.Sub(bucket.updated) > time.Hour {
+			delete(m.buckets, key)
+		}
+	}
+}
+
+// rateLimitKey is a key of the RateLimitStore and its budget
+type rateLimitKey struct {
+	key   string
+	limit RateLimit
+}
+
+// withRateLimit will limit the requests of the capability for each client IP and each target paymail address
+//
+// Requests exceeding the budget are rejected with errors.ErrTooManyRequests and a Retry-After header
+// (the requests are allowed if the store fails)
+func (c *Configuration) withRateLimit(capability string, next http.HandlerFunc) http.HandlerFunc {
+	if c.rateLimitStore == nil {
+		return next
+	}
+
+	return func(w http.ResponseWriter, req *http.Request) {
+		budget := c.RateLimits.budget(capability)
+
+		keys := []rateLimitKey{{key: "ip:" + capability + ":" + rateLimitIPAddress(req, c.RateLimits.TrustProxyHeaders), limit: budget.IP}}
+		if _, _, address := paymail.SanitizePaymail(req.PathValue(PaymailAddressParamName)); len(address) > 0 {
+			keys = append(keys, rateLimitKey{key: "alias:" + capability + ":" + address, limit: budget.Alias})
+		}
+
+		for _, k := range keys {
+			if k.limit.unlimited() {
+				continue
+			}
+			retryAfter, err := c.rateLimitStore.Take(req.Context(), k.key, k.limit)
+			if err != nil {
+				c.Logger.Error().Err(err).Str("key", k.key).Msg("failed to take from the rate limit budget")
+				continue
+			} else if retryAfter > 0 {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+				errors.WriteErrorResponse(w, errors.ErrTooManyRequests, c.Logger)
+				return
+			}
+		}
+
+		next(w, req)
+	}
+}
+
+// rateLimitIPAddress will return the client IP address of the request (without the port)
+//
+// The proxy headers are only used if they are trusted: X-Real-IP, or the last X-Forwarded-For
+// address (appended by the proxy, the previous ones are set by the client)
+func rateLimitIPAddress(req *http.Request, trustProxyHeaders bool) string {
+	ipAddress := req.RemoteAddr
+	if trustProxyHeaders {
+		if realIP := strings.TrimSpace(req.Header.Get("X-Real-IP")); len(realIP) > 0 {
+			ipAddress = realIP
+		} else if forwarded := req.Header.Get("X-Forwarded-For"); len(forwarded) > 0 {
+			ipAddress = strings.TrimSpace(forwarded[strings.LastIndex(forwarded, ",")+1:])
+		}
+	}
+
+	if host, _, err := net.SplitHostPort(ipAddress); err == nil {
+		return host
+	}
+	return ipAddress
+}
@@ -0,0 +1,198 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// TestMemoryRateLimitStore will test the method Take() of the in-memory RateLimitStore
+func TestMemoryRateLimitStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("burst and refill", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+		limit := RateLimit{Period: 100 * time.Millisecond, Requests: 2}
+
+		for range 2 {
+			retryAfter, err := store.Take(ctx, "key", limit)
+			require.NoError(t, err)
+			assert.Zero(t, retryAfter)
+		}
+
+		retryAfter, err := store.Take(ctx, "key", limit)
+		require.NoError(t, err)
+		assert.Positive(t, retryAfter)
+		assert.LessOrEqual(t, retryAfter, 50*time.Millisecond)
+
+		retryAfter, err = store.Take(ctx, "other", limit)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter, "each key has its own budget")
+
+		time.Sleep(60 * time.Millisecond)
+		retryAfter, err = store.Take(ctx, "key", limit)
+		require.NoError(t, err)
+		assert.Zero(t, retryAfter, "one request is refilled")
+	})
+
+	t.Run("unlimited", func(t *testing.T) {
+		store := NewMemoryRateLimitStore()
+
+		for range 10 {
+			retryAfter, err := store.Take(ctx, "key", RateLimit{})
+			require.NoError(t, err)
+			assert.Zero(t, retryAfter)
+		}
+	})
+}
+
+// TestConfiguration_RateLimits will test the rate limiting of the capabilities
+func TestConfiguration_RateLimits(t *testing.T) {
+	t.Parallel()
+
+	const (
+		pkiPath    = "/v1/bsvalias/id/mrz@test.com"
+		verifyPath = "/v1/bsvalias/verify-pubkey/mrz@test.com/02ead23149a1e33df17325ec7a7ba9e0b20c674c57c630f527d69b866aa9b65b10"
+	)
+	budget := RateLimit{Period: time.Minute, Requests: 2}
+
+	request := func(handler http.Handler, path, ipAddress string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = "test.com"
+		req.RemoteAddr = ipAddress + ":1234"
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder
+	}
+
+	t.Run("budget for each client IP", func(t *testing.T) {
+		limits := RateLimits{Default: RateLimitBudget{IP: budget}}
+		for _, name := range []string{"net/http", "gin"} {
+			handler := testHandlers(t, WithRateLimits(limits, nil))[name] // each config has its own store
+			for range 2 {
+				recorder := request(handler, pkiPath, "10.0.0.1")
+				require.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+			}
+
+			recorder := request(handler, pkiPath, "10.0.0.1")
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code, name)
+			assert.Equal(t, "30", recorder.Header().Get("Retry-After"), name)
+			assert.Equal(t, errors.ErrTooManyRequests.Code, errorCode(t, recorder), name)
+
+			recorder = request(handler, pkiPath, "10.0.0.2")
+			assert.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+
+			recorder = request(handler, verifyPath, "10.0.0.1")
+			assert.NotEqual(t, http.StatusTooManyRequests, recorder.Code, "each capability has its own budget (%s)", name)
+		}
+	})
+
+	t.Run("budget for each target paymail", func(t *testing.T) {
+		limits := RateLimits{Default: RateLimitBudget{Alias: budget}}
+		for _, name := range []string{"net/http", "gin"} {
+			handler := testHandlers(t, WithRateLimits(limits, nil))[name] // each config has its own store
+			request(handler, pkiPath, "10.0.0.1")
+			request(handler, pkiPath, "10.0.0.2")
+
+			recorder := request(handler, pkiPath, "10.0.0.3")
+			require.Equal(t, http.StatusTooManyRequests, recorder.Code, name)
+
+			recorder = request(handler, "/v1/bsvalias/id/satchmo@test.com", "10.0.0.3")
+			assert.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+		}
+	})
+
+	t.Run("budget of the capability", func(t *testing.T) {
+		limits := RateLimits{
+			Capabilities: map[string]RateLimitBudget{paymail.BRFCPki: {}},
+			Default:      RateLimitBudget{IP: budget},
+		}
+		for _, name := range []string{"net/http", "gin"} {
+			handler := testHandlers(t, WithRateLimits(limits, nil))[name] // each config has its own store
+			for range 3 {
+				recorder := request(handler, pkiPath, "10.0.0.1")
+				require.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+			}
+
+			for range 2 {
+				request(handler, verifyPath, "10.0.0.1")
+			}
+			recorder := request(handler, verifyPath, "10.0.0.1")
+			assert.Equal(t, http.StatusTooManyRequests, recorder.Code, name)
+		}
+	})
+
+	t.Run("proxy headers are not trusted by default", func(t *testing.T) {
+		limits := RateLimits{Default: RateLimitBudget{IP: budget}}
+		for _, name := range []string{"net/http", "gin"} {
+			handler := testHandlers(t, WithRateLimits(limits, nil))[name] // each config has its own store
+			for i := range 3 {
+				req := httptest.NewRequest(http.MethodGet, pkiPath, nil)
+				req.Host = "test.com"
+				req.RemoteAddr = "10.0.0.1:1234"
+				req.Header.Set("X-Real-IP", fmt.Sprintf("192.0.2.%d", i))
+				req.Header.Set("X-Forwarded-For", fmt.Sprintf("198.51.100.%d", i))
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				if i < 2 {
+					require.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+				} else {
+					assert.Equal(t, http.StatusTooManyRequests, recorder.Code, "spoofed headers do not bypass the budget (%s)", name)
+				}
+			}
+		}
+	})
+
+	t.Run("trusted proxy headers", func(t *testing.T) {
+		limits := RateLimits{Default: RateLimitBudget{IP: budget}, TrustProxyHeaders: true}
+		for _, name := range []string{"net/http", "gin"} {
+			handler := testHandlers(t, WithRateLimits(limits, nil))[name] // each config has its own store
+			for i := range 3 {
+				req := httptest.NewRequest(http.MethodGet, pkiPath, nil)
+				req.Host = "test.com"
+				req.RemoteAddr = "10.0.0.1:1234" // the proxy
+				req.Header.Set("X-Real-IP", fmt.Sprintf("192.0.2.%d", i))
+				recorder := httptest.NewRecorder()
+				handler.ServeHTTP(recorder, req)
+				require.NotEqual(t, http.StatusTooManyRequests, recorder.Code, "each client behind the proxy has its own budget (%s)", name)
+			}
+		}
+	})
+
+	t.Run("capabilities are not rate limited by default", func(t *testing.T) {
+		for name, handler := range testHandlers(t) {
+			for range 5 {
+				recorder := request(handler, pkiPath, "10.0.0.1")
+				require.NotEqual(t, http.StatusTooManyRequests, recorder.Code, name)
+			}
+		}
+	})
+}
+
+// Test_rateLimitIPAddress will test the method rateLimitIPAddress()
+func Test_rateLimitIPAddress(t *testing.T) {
+	t.Parallel()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = "192.0.2.1:1234"
+	assert.Equal(t, "192.0.2.1", rateLimitIPAddress(req, true))
+
+	req.Header.Set("X-Forwarded-For", "198.51.100.9, 198.51.100.1")
+	assert.Equal(t, "192.0.2.1", rateLimitIPAddress(req, false), "proxy headers are not trusted")
+	assert.Equal(t, "198.51.100.1", rateLimitIPAddress(req, true), "address appended by the proxy")
+
+	req.Header.Set("X-Real-IP", "203.0.113.1")
+	assert.Equal(t, "192.0.2.1", rateLimitIPAddress(req, false))
+	assert.Equal(t, "203.0.113.1", rateLimitIPAddress(req, true))
+}
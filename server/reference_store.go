@@ -0,0 +1,292 @@
+package server
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// ReferenceRecord is a reference issued by the server (P2P Payment Destination or PIKE outputs)
+type ReferenceRecord struct {
+	Alias     string                         `json:"alias"`              // Alias of the receiving paymail
+	Domain    string                         `json:"domain"`             // Domain of the receiving paymail
+	ExpiresAt time.Time                      `json:"expires_at"`         // Unused references are rejected after this time
+	Outputs   []*ReferenceOutput             `json:"outputs"`            // Outputs issued for the reference
+	Reference string                         `json:"reference"`          // Reference returned to the sender
+	Response  *paymail.P2PTransactionPayload `json:"response,omitempty"` // Stored response (set once the transaction is recorded)
+	Satoshis  uint64                         `json:"satoshis"`           // Amount requested by the sender
+	TxID      string                         `json:"txid,omitempty"`     // Transaction using the reference (set once claimed)
+}
+
+// ReferenceKey identifies a reference issued for a receiving paymail
+//
+// The same reference issued for another paymail is a different reference
+type ReferenceKey struct {
+	Alias     string `json:"alias"`     // Alias of the receiving paymail
+	Domain    string `json:"domain"`    // Domain of the receiving paymail
+	Reference string `json:"reference"` // Reference returned to the sender
+}
+
+// Key will return the key of the reference record
+func (r *ReferenceRecord) Key() ReferenceKey {
+	return ReferenceKey{Alias: r.Alias, Domain: r.Domain, Reference: r.Reference}
+}
+
+// ReferenceOutput is an output issued for a reference
+type ReferenceOutput struct {
+	Satoshis uint64 `json:"satoshis,omitempty"` // Satoshis of the output (any amount if zero)
+	Script   string `json:"script"`             // Hex encoded locking script
+}
+
+// VerifyOutputs will check that the transaction pays the exact issued outputs (scripts and satoshis)
+//
+// Each transaction output can match only one issued output, the outputs paid to the
+// issued scripts must cover the requested amount
+func (r *ReferenceRecord) VerifyOutputs(tx *sdk.Transaction) error {
+	used := make([]bool, len(tx.Outputs))
+	var paid uint64
+
+	for _, expected := range r.Outputs {
+		matched, scriptFound := false, false
+		for index, output := range tx.Outputs {
+			if used[index] || output.LockingScript == nil || !strings.EqualFold(output.LockingScript.String(), expected.Script) {
+				continue
+			}
+			scriptFound = true
+			if expected.Satoshis == 0 || output.Satoshis == expected.Satoshis {
+				used[index], matched = true, true
+				paid += output.Satoshis
+				break
+			}
+		}
+
+		if !matched && scriptFound {
+			return errors.ErrReferenceOutputSatoshisMismatch
+		} else if !matched {
+			return errors.ErrReferenceOutputMissing
+		}
+	}
+
+	if paid < r.Satoshis {
+		return errors.ErrReferenceSatoshisTooLow
+	}
+	return nil
+}
+
+// ReferenceStore stores the issued references to make receiving transactions idempotent
+//
+// Claim must be atomic: only one transaction can claim an unused reference.
+// Records are identified by their ReferenceKey (the receiving paymail and the reference).
+type ReferenceStore interface {
+	// Save will store the issued reference (overwrites an existing record)
+	Save(ctx context.Context, record *ReferenceRecord) error
+
+	// Claim will mark the reference as used by the txid and return a copy of the record
+	//
+	// Returns errors.ErrReferenceNotFound for unknown or expired references,
+	// errors.ErrReferenceConflict if the reference was claimed by another txid and
+	// errors.ErrReferenceInProgress if the txid is claimed but not recorded yet.
+	// A record with a Response is an exact replay of a recorded transaction.
+	Claim(ctx context.Context, key ReferenceKey, txID string) (*ReferenceRecord, error)
+
+	// Complete will store the response of the recorded transaction
+	Complete(ctx context.Context, key ReferenceKey, response *paymail.P2PTransactionPayload) error
+
+	// Release will remove the claim (recording the transaction has failed)
+	Release(ctx context.Context, key ReferenceKey) error
+}
+
+// memoryReferenceStore is an in-memory implementation of the ReferenceStore
+type memoryReferenceStore struct {
+	lastPrune time.Time
+	records   map[ReferenceKey]*ReferenceRecord
+	sync.Mutex
+}
+
+// NewMemoryReferenceStore will return an in-memory ReferenceStore
+//
+// Records are removed once they expire (used references as well)
+func NewMemoryReferenceStore() ReferenceStore {
+	return &memoryReferenceStore{
+		lastPrune: time.Now(),
+		records:   make(map[ReferenceKey]*ReferenceRecord),
+	}
+}
+
+// Save will store the issued reference
+func (m *memoryReferenceStore) Save(_ context.Context, record *ReferenceRecord) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.prune()
+	saved := *record
+	m.records[record.Key()] = &saved
+	return nil
+}
+
+// Claim will mark the reference as used by the txid
+func (m *memoryReferenceStore) Claim(_ context.Context, key ReferenceKey, txID string) (*ReferenceRecord, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	record, ok := m.records[key]
+	if !ok || time.Now().After(record.ExpiresAt) {
+		return nil, errors.ErrReferenceNotFound
+	}
+
+	switch {
+	case len(record.TxID) == 0:
+		record.TxID = txID
+	case record.TxID != txID:
+		return nil, errors.ErrReferenceConflict
+	case record.Response == nil:
+		return nil, errors.ErrReferenceInProgress
+	}
+
+	claimed := *record
+	return &claimed, nil
+}
+
+// Complete will store the response of the recorded transaction
+func (m *memoryReferenceStore) Complete(_ context.Context, key ReferenceKey, response *paymail.P2PTransactionPayload) error {
+	m.Lock()
+	defer m.Unlock()
+
+	record, ok := m.records[key]
+	if !ok {
+		return errors.ErrReferenceNotFound
+	}
+	record.Response = response
+	return nil
+}
+
+// Release will remove the claim of the reference
+func (m *memoryReferenceStore) Release(_ context.Context, key ReferenceKey) error {
+	m.Lock()
+	defer m.Unlock()
+
+	if record, ok := m.records[key]; ok && record.Response == nil {
+		record.TxID = ""
+	}
+	return nil
+}
+
+// prune will remove the expired records (at most once a minute, lock must be held)
+func (m *memoryReferenceStore) prune() {
+	now := time.Now()
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for key, record := range m.records {
+		if now.After(record.ExpiresAt) {
+			delete(m.records, key)
+		}
+	}
+}
+
+// saveReference will store the issued reference (if the reference store is enabled)
+func (c *Configuration) saveReference(ctx context.Context, record *ReferenceRecord) error {
+	if c.referenceStore == nil || len(record.Reference) == 0 {
+		return nil
+	}
+	record.ExpiresAt = time.Now().Add(c.ReferenceTTL)
+	return c.referenceStore.Save(ctx, record)
+}
+
+// recordTransaction will verify and record the transaction once per reference (of the receiving paymail)
+//
+// Exact replays (same paymail, reference and txid) return the stored response without recording again,
+// the issued outputs and verify (optional) are only checked for new transactions.
+// Without the reference store, the transactions are recorded as received (resubmissions included)
+func (c *Configuration) recordTransaction(ctx context.Context, payload *p2pReceiveTxReqPayload, md *RequestMetadata,
+	verify func() error,
+) (*paymail.P2PTransactionPayload, error) {
+	if verify == nil {
+		verify = func() error { return nil }
+	}
+	if c.referenceStore == nil {
+		if err := verify(); err != nil {
+			return nil, err
+		}
+		return c.actions.RecordTransaction(ctx, payload.P2PTransaction, md)
+	}
+
+	key := ReferenceKey{Alias: payload.incomingPaymailAlias, Domain: payload.incomingPaymailDomain, Reference: payload.Reference}
+	record, err := c.referenceStore.Claim(ctx, key, payload.txID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The claim is released or completed even if the request is canceled
+	storeCtx := context.WithoutCancel(ctx)
+
+	// Custom stores could return the reference of another paymail
+	if !strings.EqualFold(record.Alias, key.Alias) || !strings.EqualFold(record.Domain, key.Domain) {
+		c.Logger.Warn().Str("reference", payload.Reference).Str("alias", key.Alias).Str("domain", key.Domain).
+			Msg("reference was issued for another paymail")
+		if record.Response == nil {
+			c.releaseReference(storeCtx, key)
+		}
+		return nil, errors.ErrReferencePaymailMismatch
+	} else if record.Response != nil {
+		c.Logger.Info().Str("reference", payload.Reference).Str("txid", payload.txID).Msg("replayed transaction")
+		return record.Response, nil
+	}
+
+	var response *paymail.P2PTransactionPayload
+	if err = record.VerifyOutputs(payload.tx); err == nil {
+		err = verify()
+	}
+	if err == nil {
+		response, err = c.recordOnce(ctx, payload, md)
+	}
+	if err != nil {
+		c.releaseReference(storeCtx, key)
+		return nil, err
+	}
+
+	stored := response
+	if stored == nil { // Replays must not be seen as in progress
+		stored = &paymail.P2PTransactionPayload{TxID: payload.txID}
+	}
+	if err = c.referenceStore.Complete(storeCtx, key, stored); err != nil {
+		c.Logger.Error().Err(err).Str("reference", payload.Reference).Msg("failed to complete the reference")
+	}
+	return response, nil
+}
+
+// releaseReference will remove the claim of the reference (recording the transaction has failed)
+func (c *Configuration) releaseReference(ctx context.Context, key ReferenceKey) {
+	if err := c.referenceStore.Release(ctx, key); err != nil {
+		c.Logger.Error().Err(err).Str("reference", key.Reference).Msg("failed to release the reference")
+	}
+}
+
+// recordOnce will record the transaction of a new reference, the transactions with signed metadata are
+// recorded once per txid if the replay guard is enabled (the key is removed if recording fails)
+//
+// The same transaction sent with another reference is rejected, exact resubmissions are answered
+// from the reference store before reaching this point
+func (c *Configuration) recordOnce(ctx context.Context, payload *p2pReceiveTxReqPayload, md *RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+	key := p2pReplayKey(payload)
+	if len(key) == 0 {
+		return c.actions.RecordTransaction(ctx, payload.P2PTransaction, md)
+	}
+
+	if err := c.guardReplay(ctx, key, time.Now().Add(c.ReferenceTTL)); err != nil {
+		return nil, err
+	}
+	response, err := c.actions.RecordTransaction(ctx, payload.P2PTransaction, md)
+	if err != nil {
+		c.releaseReplay(context.WithoutCancel(ctx), key)
+		return nil, err
+	}
+	return response, nil
+}
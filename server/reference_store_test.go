@@ -0,0 +1,416 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// testReferenceAddress is the address paid by the test transactions (and issued by the referenceServiceProvider)
+const testReferenceAddress = "1LSWjJ6Dh4tDhwhYk5uUoh5BwjhYKLa4Jb"
+
+// referenceServiceProvider is a service provider which issues references and counts the recorded transactions
+type referenceServiceProvider struct {
+	mockServiceProvider
+	recordErr error
+	recorded  atomic.Int32
+	script    string
+}
+
+// GetPaymailByAlias will return a paymail for any alias
+func (r *referenceServiceProvider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *RequestMetadata) (*paymail.AddressInformation, error) {
+	return &paymail.AddressInformation{Alias: alias, Domain: domain}, nil
+}
+
+// CreateP2PDestinationResponse will return a destination with a fixed reference
+func (r *referenceServiceProvider) CreateP2PDestinationResponse(_ context.Context, _, _ string,
+	satoshis uint64, _ *RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	return &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{{Satoshis: satoshis, Script: r.script}},
+		Reference: "test-reference",
+	}, nil
+}
+
+// RecordTransaction will count the recorded transactions
+func (r *referenceServiceProvider) RecordTransaction(_ context.Context,
+	p2pTx *paymail.P2PTransaction, _ *RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+	if r.recordErr != nil {
+		return nil, r.recordErr
+	}
+	r.recorded.Add(1)
+	return &paymail.P2PTransactionPayload{Note: p2pTx.MetaData.Note, TxID: "recorded"}, nil
+}
+
+// testReferenceHandler will return the handler using the reference store and the provider
+func testReferenceHandler(t *testing.T, store ReferenceStore, ttl time.Duration) (http.Handler, *referenceServiceProvider) {
+	provider := &referenceServiceProvider{script: testLockingScript(t)}
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(provider)
+
+	config, err := NewConfig(sl, WithDomain("test.com"), WithP2PCapabilities(), WithReferenceStore(store, ttl))
+	require.NoError(t, err)
+	return config.Handler(), provider
+}
+
+// testTx will return a transaction with an output (to the testReferenceAddress) for each amount
+func testTx(t *testing.T, satoshis ...uint64) *sdk.Transaction {
+	tx := sdk.NewTransaction()
+	for _, amount := range satoshis {
+		require.NoError(t, tx.PayToAddress(testReferenceAddress, amount))
+	}
+	return tx
+}
+
+// testTxHex will return the transaction hex with an output for each amount
+func testTxHex(t *testing.T, satoshis ...uint64) string {
+	return testTx(t, satoshis...).String()
+}
+
+// testLockingScript will return the locking script (hex) of the testReferenceAddress
+func testLockingScript(t *testing.T) string {
+	return testTx(t, 1).Outputs[0].LockingScript.String()
+}
+
+// postTestJSON will post the payload to the handler
+func postTestJSON(t *testing.T, handler http.Handler, path string, payload any) *httptest.ResponseRecorder {
+	body, err := json.Marshal(payload)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body))
+	req.Host = "test.com"
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+// TestConfiguration_IdempotentReceiveTransaction will test receiving transactions with the reference store
+func TestConfiguration_IdempotentReceiveTransaction(t *testing.T) {
+	t.Parallel()
+
+	const (
+		destinationPath = "/v1/bsvalias/p2p-payment-destination/mrz@test.com"
+		receivePath     = "/v1/bsvalias/receive-transaction/mrz@test.com"
+	)
+
+	newTransaction := func(hex, reference string) *paymail.P2PTransaction {
+		return &paymail.P2PTransaction{
+			Hex:       hex,
+			MetaData:  &paymail.P2PMetaData{Note: "test note"},
+			Reference: reference,
+		}
+	}
+
+	t.Run("replay returns the stored response", func(t *testing.T) {
+		handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+
+		recorder := postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		transaction := newTransaction(testTxHex(t, 1000), "test-reference")
+		first := postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, first.Code)
+
+		replay := postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, replay.Code)
+		assert.JSONEq(t, first.Body.String(), replay.Body.String())
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+
+	t.Run("conflicting txid is rejected", func(t *testing.T) {
+		handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+		postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+
+		recorder := postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 1000), "test-reference"))
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		recorder = postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 2000), "test-reference"))
+		assert.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, errors.ErrReferenceConflict.Code, errorCode(t, recorder))
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+
+	t.Run("unknown reference is rejected", func(t *testing.T) {
+		handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+
+		recorder := postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 1000), "unknown-reference"))
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, errors.ErrReferenceNotFound.Code, errorCode(t, recorder))
+		assert.Equal(t, int32(0), provider.recorded.Load())
+	})
+
+	t.Run("expired reference is rejected", func(t *testing.T) {
+		handler, _ := testReferenceHandler(t, NewMemoryReferenceStore(), time.Millisecond)
+		postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+		time.Sleep(5 * time.Millisecond)
+
+		recorder := postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 1000), "test-reference"))
+		assert.Equal(t, errors.ErrReferenceNotFound.Code, errorCode(t, recorder))
+	})
+
+	t.Run("reference issued for another paymail is rejected", func(t *testing.T) {
+		handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+		postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+
+		transaction := newTransaction(testTxHex(t, 1000), "test-reference")
+		recorder := postTestJSON(t, handler, "/v1/bsvalias/receive-transaction/satchmo@test.com", transaction)
+		assert.Equal(t, errors.ErrReferenceNotFound.Code, errorCode(t, recorder))
+
+		recorder = postTestJSON(t, handler, receivePath, transaction)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+
+	t.Run("failed recording releases the reference", func(t *testing.T) {
+		handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+		postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+
+		provider.recordErr = errors.ErrMissingFieldHex
+		recorder := postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 1000), "test-reference"))
+		assert.Equal(t, errors.ErrMissingFieldHex.Code, errorCode(t, recorder))
+
+		provider.recordErr = nil
+		recorder = postTestJSON(t, handler, receivePath, newTransaction(testTxHex(t, 1000, 1), "test-reference"))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+}
+
+// bareReferenceStore is a ReferenceStore keyed by the bare reference (ignoring the receiving paymail)
+type bareReferenceStore struct {
+	ReferenceStore
+}
+
+// Save will store the reference by the bare reference
+func (b *bareReferenceStore) Save(ctx context.Context, record *ReferenceRecord) error {
+	m := b.ReferenceStore.(*memoryReferenceStore)
+	m.Lock()
+	defer m.Unlock()
+
+	saved := *record
+	m.records[ReferenceKey{Reference: record.Reference}] = &saved
+	return nil
+}
+
+// Claim will claim the reference issued for any paymail
+func (b *bareReferenceStore) Claim(ctx context.Context, key ReferenceKey, txID string) (*ReferenceRecord, error) {
+	return b.ReferenceStore.Claim(ctx, ReferenceKey{Reference: key.Reference}, txID)
+}
+
+// Complete will complete the reference issued for any paymail
+func (b *bareReferenceStore) Complete(ctx context.Context, key ReferenceKey, response *paymail.P2PTransactionPayload) error {
+	return b.ReferenceStore.Complete(ctx, ReferenceKey{Reference: key.Reference}, response)
+}
+
+// Release will release the reference issued for any paymail
+func (b *bareReferenceStore) Release(ctx context.Context, key ReferenceKey) error {
+	return b.ReferenceStore.Release(ctx, ReferenceKey{Reference: key.Reference})
+}
+
+// TestConfiguration_ReceiveTransactionPaymailMismatch will test rejecting a reference issued for another paymail
+func TestConfiguration_ReceiveTransactionPaymailMismatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &bareReferenceStore{ReferenceStore: NewMemoryReferenceStore()}
+	require.NoError(t, store.Save(ctx, &ReferenceRecord{
+		Alias:     "mrz",
+		Domain:    "test.com",
+		ExpiresAt: time.Now().Add(time.Minute),
+		Outputs:   []*ReferenceOutput{{Satoshis: 1000, Script: testLockingScript(t)}},
+		Reference: "test-reference",
+		Satoshis:  1000,
+	}))
+	handler, provider := testReferenceHandler(t, store, 0)
+
+	transaction := &paymail.P2PTransaction{
+		Hex:       testTxHex(t, 1000),
+		MetaData:  &paymail.P2PMetaData{Note: "test note"},
+		Reference: "test-reference",
+	}
+	recorder := postTestJSON(t, handler, "/v1/bsvalias/receive-transaction/satchmo@test.com", transaction)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, errors.ErrReferencePaymailMismatch.Code, errorCode(t, recorder))
+	assert.Equal(t, int32(0), provider.recorded.Load())
+
+	// The mismatched claim is released
+	recorder = postTestJSON(t, handler, "/v1/bsvalias/receive-transaction/mrz@test.com", transaction)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Exact replays to another paymail are rejected as well
+	recorder = postTestJSON(t, handler, "/v1/bsvalias/receive-transaction/satchmo@test.com", transaction)
+	assert.Equal(t, errors.ErrReferencePaymailMismatch.Code, errorCode(t, recorder))
+	assert.Equal(t, int32(1), provider.recorded.Load())
+}
+
+// TestConfiguration_ReceiveTransactionOutputs will test verifying the issued outputs on receive
+func TestConfiguration_ReceiveTransactionOutputs(t *testing.T) {
+	t.Parallel()
+
+	const (
+		destinationPath = "/v1/bsvalias/p2p-payment-destination/mrz@test.com"
+		receivePath     = "/v1/bsvalias/receive-transaction/mrz@test.com"
+	)
+
+	tests := []struct {
+		name string
+		hex  func(t *testing.T) string
+		code string
+	}{
+		{"satoshis mismatch", func(t *testing.T) string { return testTxHex(t, 999) }, errors.ErrReferenceOutputSatoshisMismatch.Code},
+		{"output missing", func(t *testing.T) string {
+			tx := sdk.NewTransaction()
+			require.NoError(t, tx.PayToAddress("1BoatSLRHtKNngkdXEeobR76b53LETtpyT", 1000))
+			return tx.String()
+		}, errors.ErrReferenceOutputMissing.Code},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			handler, provider := testReferenceHandler(t, NewMemoryReferenceStore(), 0)
+			postTestJSON(t, handler, destinationPath, map[string]uint64{"satoshis": 1000})
+
+			recorder := postTestJSON(t, handler, receivePath, &paymail.P2PTransaction{
+				Hex: test.hex(t), MetaData: &paymail.P2PMetaData{}, Reference: "test-reference",
+			})
+			assert.Equal(t, http.StatusExpectationFailed, recorder.Code)
+			assert.Equal(t, test.code, errorCode(t, recorder))
+			assert.Equal(t, int32(0), provider.recorded.Load())
+
+			// The reference can still be used by a valid transaction
+			recorder = postTestJSON(t, handler, receivePath, &paymail.P2PTransaction{
+				Hex: testTxHex(t, 1000), MetaData: &paymail.P2PMetaData{}, Reference: "test-reference",
+			})
+			assert.Equal(t, http.StatusOK, recorder.Code)
+		})
+	}
+}
+
+// TestReferenceRecord_VerifyOutputs will test the method VerifyOutputs()
+func TestReferenceRecord_VerifyOutputs(t *testing.T) {
+	t.Parallel()
+
+	script := testLockingScript(t)
+
+	tests := []struct {
+		name   string
+		record *ReferenceRecord
+		tx     *sdk.Transaction
+		err    error
+	}{
+		{"exact outputs", &ReferenceRecord{Satoshis: 1500, Outputs: []*ReferenceOutput{
+			{Satoshis: 500, Script: script}, {Satoshis: 1000, Script: script},
+		}}, testTx(t, 1000, 500, 1), nil},
+		{"each output is matched once", &ReferenceRecord{Outputs: []*ReferenceOutput{
+			{Satoshis: 500, Script: script}, {Satoshis: 500, Script: script},
+		}}, testTx(t, 500), errors.ErrReferenceOutputMissing},
+		{"any satoshis (zero issued)", &ReferenceRecord{Satoshis: 700, Outputs: []*ReferenceOutput{
+			{Script: script},
+		}}, testTx(t, 700), nil},
+		{"paid less than requested", &ReferenceRecord{Satoshis: 700, Outputs: []*ReferenceOutput{
+			{Script: script},
+		}}, testTx(t, 699), errors.ErrReferenceSatoshisTooLow},
+		{"script missing", &ReferenceRecord{Outputs: []*ReferenceOutput{
+			{Satoshis: 500, Script: "006a"},
+		}}, testTx(t, 500), errors.ErrReferenceOutputMissing},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.record.VerifyOutputs(test.tx)
+			if test.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.err)
+			}
+		})
+	}
+}
+
+// TestMemoryReferenceStore will test the in-memory ReferenceStore
+func TestMemoryReferenceStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	refKey := ReferenceKey{Alias: "mrz", Domain: "test.com", Reference: "ref"}
+	newStore := func(t *testing.T) ReferenceStore {
+		store := NewMemoryReferenceStore()
+		require.NoError(t, store.Save(ctx, &ReferenceRecord{Alias: "mrz", Domain: "test.com", Reference: "ref", ExpiresAt: time.Now().Add(time.Minute)}))
+		return store
+	}
+
+	t.Run("claim, complete and replay", func(t *testing.T) {
+		store := newStore(t)
+
+		record, err := store.Claim(ctx, refKey, "txid")
+		require.NoError(t, err)
+		assert.Equal(t, "txid", record.TxID)
+		assert.Nil(t, record.Response)
+
+		_, err = store.Claim(ctx, refKey, "txid")
+		require.ErrorIs(t, err, errors.ErrReferenceInProgress)
+
+		require.NoError(t, store.Complete(ctx, refKey, &paymail.P2PTransactionPayload{TxID: "txid"}))
+		record, err = store.Claim(ctx, refKey, "txid")
+		require.NoError(t, err)
+		assert.Equal(t, "txid", record.Response.TxID)
+
+		_, err = store.Claim(ctx, refKey, "other-txid")
+		require.ErrorIs(t, err, errors.ErrReferenceConflict)
+
+		// Completed references are not released
+		require.NoError(t, store.Release(ctx, refKey))
+		_, err = store.Claim(ctx, refKey, "other-txid")
+		require.ErrorIs(t, err, errors.ErrReferenceConflict)
+	})
+
+	t.Run("release", func(t *testing.T) {
+		store := newStore(t)
+
+		_, err := store.Claim(ctx, refKey, "txid")
+		require.NoError(t, err)
+		require.NoError(t, store.Release(ctx, refKey))
+
+		record, err := store.Claim(ctx, refKey, "other-txid")
+		require.NoError(t, err)
+		assert.Equal(t, "other-txid", record.TxID)
+	})
+
+	t.Run("unknown and expired", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, &ReferenceRecord{Alias: "mrz", Domain: "test.com", Reference: "expired", ExpiresAt: time.Now().Add(-time.Second)}))
+
+		_, err := store.Claim(ctx, ReferenceKey{Alias: "mrz", Domain: "test.com", Reference: "unknown"}, "txid")
+		require.ErrorIs(t, err, errors.ErrReferenceNotFound)
+
+		_, err = store.Claim(ctx, ReferenceKey{Alias: "mrz", Domain: "test.com", Reference: "expired"}, "txid")
+		require.ErrorIs(t, err, errors.ErrReferenceNotFound)
+	})
+
+	t.Run("reference of another paymail", func(t *testing.T) {
+		store := newStore(t)
+		require.NoError(t, store.Save(ctx, &ReferenceRecord{Alias: "satchmo", Domain: "test.com", Reference: "ref", ExpiresAt: time.Now().Add(time.Minute)}))
+
+		_, err := store.Claim(ctx, ReferenceKey{Alias: "satchmo", Domain: "test.com", Reference: "ref"}, "txid")
+		require.NoError(t, err)
+
+		_, err = store.Claim(ctx, ReferenceKey{Alias: "mrz", Domain: "other.com", Reference: "ref"}, "txid")
+		require.ErrorIs(t, err, errors.ErrReferenceNotFound)
+
+		record, err := store.Claim(ctx, refKey, "other-txid")
+		require.NoError(t, err, "the same reference of another paymail is not claimed")
+		assert.Equal(t, "mrz", record.Alias)
+	})
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// ReplayStore stores the keys of the accepted signed requests to reject their replays
+//
+// Add must be atomic: only one request can add a key
+type ReplayStore interface {
+	// Add will store the key until it expires
+	//
+	// Returns errors.ErrRequestReplayed if the key is already stored and not expired
+	Add(ctx context.Context, key string, expiresAt time.Time) error
+
+	// Remove will remove the key (the request has failed and can be sent again)
+	Remove(ctx context.Context, key string) error
+}
+
+// memoryReplayStore is an in-memory implementation of the ReplayStore
+type memoryReplayStore struct {
+	keys      map[string]time.Time
+	lastPrune time.Time
+	sync.Mutex
+}
+
+// NewMemoryReplayStore will return an in-memory ReplayStore
+//
+// Keys are removed once they expire, use a shared store if the server runs multiple instances
+func NewMemoryReplayStore() ReplayStore {
+	return &memoryReplayStore{
+		keys:      make(map[string]time.Time),
+		lastPrune: time.Now(),
+	}
+}
+
+// Add will store the key until it expires
+func (m *memoryReplayStore) Add(_ context.Context, key string, expiresAt time.Time) error {
+	m.Lock()
+	defer m.Unlock()
+
+	m.prune()
+	if stored, ok := m.keys[key]; ok && time.Now().Before(stored) {
+		return errors.ErrRequestReplayed
+	}
+	m.keys[key] = expiresAt
+	return nil
+}
+
+// Remove will remove the key
+func (m *memoryReplayStore) Remove(_ context.Context, key string) error {
+	m.Lock()
+	defer m.Unlock()
+
+	delete(m.keys, key)
+	return nil
+}
+
+// prune will remove the expired keys (at most once a minute, lock must be held)
+func (m *memoryReplayStore) prune() {
+	now := time.Now()
+	if now.Sub(m.lastPrune) < time.Minute {
+		return
+	}
+	m.lastPrune = now
+	for key, expiresAt := range m.keys {
+		if now.After(expiresAt) {
+			delete(m.keys, key)
+		}
+	}
+}
+
+// validateRequestTime will check that the timestamp of the signed request is within the replay window
+//
+// Returns the parsed timestamp, the keys of the request can expire once it is out of the window
+func (c *Configuration) validateRequestTime(timestamp string) (time.Time, error) {
+	dt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return time.Time{}, errors.ErrInvalidTimestamp
+	}
+
+	now := time.Now()
+	if dt.Before(now.Add(-c.ReplayWindow)) {
+		return time.Time{}, errors.ErrTimestampExpired
+	} else if dt.After(now.Add(c.ReplayWindow)) {
+		return time.Time{}, errors.ErrTimestampInFuture
+	}
+	return dt, nil
+}
+
+// guardReplay will add the key of the signed request to the replay store (if the replay guard is enabled)
+//
+// Returns errors.ErrRequestReplayed if the request was already accepted
+func (c *Configuration) guardReplay(ctx context.Context, key string, expiresAt time.Time) error {
+	if c.replayStore == nil {
+		return nil
+	}
+	return c.replayStore.Add(ctx, key, expiresAt)
+}
+
+// releaseReplay will remove the key of the failed request from the replay store (if the replay guard is enabled),
+// the request can be sent again (nothing is removed if the key is empty)
+func (c *Configuration) releaseReplay(ctx context.Context, key string) {
+	if c.replayStore == nil || len(key) == 0 {
+		return
+	}
+	if err := c.replayStore.Remove(ctx, key); err != nil {
+		c.Logger.Error().Err(err).Str("key", key).Msg("failed to remove the replay key")
+	}
+}
+
+// resolveAddressReplayKey is the replay key of the signed message of the sender request
+// (the signature itself can be malleated)
+func resolveAddressReplayKey(senderRequest *paymail.SenderRequest) string {
+	message := fmt.Sprintf("%s%d%s%s", senderRequest.SenderHandle, senderRequest.Amount, senderRequest.Dt, senderRequest.Purpose)
+	hash := sha256.Sum256([]byte(message))
+	return "resolve-address:" + hex.EncodeToString(hash[:])
+}
+
+// p2pReplayKey is the replay key of the transaction with signed metadata (empty if not signed)
+func p2pReplayKey(payload *p2pReceiveTxReqPayload) string {
+	if payload.MetaData == nil || len(payload.MetaData.Signature) == 0 {
+		return ""
+	}
+	return "p2p-transaction:" + payload.txID
+}
+
+// pikeContactReplayKey is the replay key of the signed contact request (the nonce is signed)
+func pikeContactReplayKey(contact *paymail.PikeContactRequestPayload) string {
+	_, _, address := paymail.SanitizePaymail(contact.Paymail)
+	return "pike-contact:" + address + ":" + contact.Nonce
+}
+
+// pikeContactResponseReplayKey is the replay key of the signed contact answer or status request (the nonce is signed)
+func pikeContactResponseReplayKey(contact *paymail.PikeContactResponsePayload) string {
+	_, _, address := paymail.SanitizePaymail(contact.Paymail)
+	return "pike-contact-response:" + address + ":" + contact.Nonce
+}
@@ -0,0 +1,309 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"testing"
+	"time"
+
+	bsm "github.com/bitcoin-sv/go-sdk/compat/bsm"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// TestMemoryReplayStore will test the methods of the in-memory ReplayStore
+func TestMemoryReplayStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("replayed key is rejected until it expires", func(t *testing.T) {
+		store := NewMemoryReplayStore()
+
+		require.NoError(t, store.Add(ctx, "key", time.Now().Add(time.Minute)))
+		require.Equal(t, errors.ErrRequestReplayed, store.Add(ctx, "key", time.Now().Add(time.Minute)))
+		require.NoError(t, store.Add(ctx, "other", time.Now().Add(time.Minute)))
+	})
+
+	t.Run("expired key is added again", func(t *testing.T) {
+		store := NewMemoryReplayStore()
+
+		require.NoError(t, store.Add(ctx, "key", time.Now().Add(-time.Second)))
+		require.NoError(t, store.Add(ctx, "key", time.Now().Add(time.Minute)))
+	})
+
+	t.Run("removed key is added again", func(t *testing.T) {
+		store := NewMemoryReplayStore()
+
+		require.NoError(t, store.Add(ctx, "key", time.Now().Add(time.Minute)))
+		require.NoError(t, store.Remove(ctx, "key"))
+		require.NoError(t, store.Add(ctx, "key", time.Now().Add(time.Minute)))
+	})
+}
+
+// TestConfiguration_validateRequestTime will test the method validateRequestTime()
+func TestConfiguration_validateRequestTime(t *testing.T) {
+	t.Parallel()
+
+	c := &Configuration{ReplayWindow: time.Minute}
+	now := time.Now().UTC()
+
+	tcs := []struct {
+		name      string
+		timestamp string
+		expected  error
+	}{
+		{name: "now", timestamp: now.Format(time.RFC3339)},
+		{name: "within the window", timestamp: now.Add(-30 * time.Second).Format(time.RFC3339Nano)},
+		{name: "expired", timestamp: now.Add(-2 * time.Minute).Format(time.RFC3339), expected: errors.ErrTimestampExpired},
+		{name: "in the future", timestamp: now.Add(2 * time.Minute).Format(time.RFC3339), expected: errors.ErrTimestampInFuture},
+		{name: "invalid", timestamp: "2020-04-09", expected: errors.ErrInvalidTimestamp},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.validateRequestTime(tc.timestamp)
+			assert.Equal(t, tc.expected, err)
+		})
+	}
+}
+
+// TestConfiguration_ResolveAddressTimestamp will test the replay window of the address resolution
+//
+// The replays of the signed requests (resolving the PKI of the sender) are tested with the tester.PaymailServer
+func TestConfiguration_ResolveAddressTimestamp(t *testing.T) {
+	t.Parallel()
+
+	const addressPath = "/v1/bsvalias/address/mrz@test.com"
+
+	tcs := []struct {
+		name     string
+		opts     []ConfigOps
+		dt       time.Time
+		expected errors.SPVError
+	}{
+		{name: "expired", dt: time.Now().Add(-3 * time.Minute), expected: errors.ErrTimestampExpired},
+		{name: "in the future", dt: time.Now().Add(3 * time.Minute), expected: errors.ErrTimestampInFuture},
+		{
+			name:     "custom window",
+			opts:     []ConfigOps{WithReplayGuard(NewMemoryReplayStore(), 30*time.Second)},
+			dt:       time.Now().Add(-time.Minute),
+			expected: errors.ErrTimestampExpired,
+		},
+	}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			for name, handler := range testHandlers(t, tc.opts...) {
+				recorder := postTestJSON(t, handler, addressPath, &paymail.SenderRequest{
+					Dt:           tc.dt.UTC().Format(time.RFC3339),
+					SenderHandle: "bob@example.com",
+				})
+				require.Equal(t, tc.expected.StatusCode, recorder.Code, name)
+				assert.Equal(t, tc.expected.Code, errorCode(t, recorder), name)
+			}
+		})
+	}
+}
+
+// TestConfiguration_ReplayReleasedOnFailure will test that the signed requests can be sent again if the provider fails
+func TestConfiguration_ReplayReleasedOnFailure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("address resolution", func(t *testing.T) {
+		key, err := ec.NewPrivateKey()
+		require.NoError(t, err)
+
+		provider := &localPaymailProvider{alias: "mrz", err: errors.ErrCouldNotFindPaymail}
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(provider)
+		config, err := NewConfig(sl, WithDomain("test.com"), WithSenderValidation(),
+			WithReplayGuard(NewMemoryReplayStore(), 0),
+			WithPaymailClient(&pkiPaymailClient{keys: map[string]*ec.PublicKey{testContactPaymail: key.PubKey()}}),
+		)
+		require.NoError(t, err)
+		handler := config.Handler()
+
+		senderRequest := &paymail.SenderRequest{Dt: time.Now().UTC().Format(time.RFC3339), SenderHandle: testContactPaymail}
+		signature, err := senderRequest.Sign(hex.EncodeToString(key.Serialize()))
+		require.NoError(t, err)
+		senderRequest.Signature = base64.StdEncoding.EncodeToString(signature)
+
+		const addressPath = "/v1/bsvalias/address/mrz@test.com"
+		recorder := postTestJSON(t, handler, addressPath, senderRequest)
+		require.Equal(t, errors.ErrCouldNotFindPaymail.Code, errorCode(t, recorder))
+
+		provider.err = nil
+		recorder = postTestJSON(t, handler, addressPath, senderRequest)
+		require.Equal(t, http.StatusOK, recorder.Code, "the failed request is sent again")
+
+		recorder = postTestJSON(t, handler, addressPath, senderRequest)
+		assert.Equal(t, errors.ErrRequestReplayed.Code, errorCode(t, recorder))
+	})
+
+	t.Run("contact request", func(t *testing.T) {
+		handler, provider := testPikeContactHandler(t, WithReplayGuard(NewMemoryReplayStore(), 0))
+		request := &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: testContactPaymail}
+		require.NoError(t, request.Sign(provider.contactKey, "mrz@test.com"))
+
+		const invitePath = "/v1/bsvalias/contact/invite/mrz@test.com"
+		provider.addErr = errors.ErrContactStatusInvalid
+		recorder := postTestJSON(t, handler, invitePath, request)
+		require.Equal(t, errors.ErrContactStatusInvalid.Code, errorCode(t, recorder))
+
+		provider.addErr = nil
+		recorder = postTestJSON(t, handler, invitePath, request)
+		require.Equal(t, http.StatusCreated, recorder.Code, "the failed request is sent again")
+
+		recorder = postTestJSON(t, handler, invitePath, request)
+		assert.Equal(t, errors.ErrRequestReplayed.Code, errorCode(t, recorder))
+	})
+
+	t.Run("contact answer and status", func(t *testing.T) {
+		handler, provider := testPikeContactHandler(t, WithReplayGuard(NewMemoryReplayStore(), 0))
+		const (
+			acceptPath = "/v1/bsvalias/contact/accept/mrz@test.com"
+			statusPath = "/v1/bsvalias/contact/status/mrz@test.com"
+		)
+		accept := signedContactResponse(t, provider.contactKey, paymail.PikeContactActionAccept)
+		status := signedContactResponse(t, provider.contactKey, paymail.PikeContactActionStatus)
+
+		recorder := postTestJSON(t, handler, acceptPath, accept)
+		require.Equal(t, errors.ErrContactStatusInvalid.Code, errorCode(t, recorder), "no contact request yet")
+		recorder = postTestJSON(t, handler, statusPath, status)
+		require.Equal(t, errors.ErrContactNotFound.Code, errorCode(t, recorder))
+
+		require.NoError(t, provider.setStatus("mrz@test.com", testContactPaymail, "", paymail.PikeContactStatusPending))
+		recorder = postTestJSON(t, handler, acceptPath, accept)
+		require.Equal(t, http.StatusOK, recorder.Code, "the failed answer is sent again")
+		recorder = postTestJSON(t, handler, statusPath, status)
+		require.Equal(t, http.StatusOK, recorder.Code, "the failed status request is sent again")
+
+		recorder = postTestJSON(t, handler, acceptPath, accept)
+		assert.Equal(t, errors.ErrRequestReplayed.Code, errorCode(t, recorder))
+	})
+}
+
+// TestConfiguration_ReceiveSignedTransactionReplay will test the replay guard of the transactions with signed metadata
+func TestConfiguration_ReceiveSignedTransactionReplay(t *testing.T) {
+	t.Parallel()
+
+	const receivePath = "/v1/bsvalias/receive-transaction/mrz@test.com"
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+
+	signedTransaction := func(t *testing.T, satoshis uint64, reference string) *paymail.P2PTransaction {
+		tx := testTx(t, satoshis)
+		signature, err := bsm.SignMessage(key, []byte(tx.TxID().String()))
+		require.NoError(t, err)
+		return &paymail.P2PTransaction{
+			Hex: tx.String(),
+			MetaData: &paymail.P2PMetaData{
+				PublicKey: key.PubKey().ToDERHex(),
+				Signature: base64.StdEncoding.EncodeToString(signature),
+			},
+			Reference: reference,
+		}
+	}
+
+	handler := func(t *testing.T, opts ...ConfigOps) (http.Handler, *referenceServiceProvider) {
+		provider := &referenceServiceProvider{script: testLockingScript(t)}
+		sl := &PaymailServiceLocator{}
+		sl.RegisterPaymailService(provider)
+
+		config, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com"), WithP2PCapabilities()}, opts...)...)
+		require.NoError(t, err)
+		return config.Handler(), provider
+	}
+
+	// referenceStore will return a reference store with the references (1000 satoshis or more) issued for mrz@test.com
+	referenceStore := func(t *testing.T, references ...string) ReferenceStore {
+		store := NewMemoryReferenceStore()
+		for _, reference := range references {
+			require.NoError(t, store.Save(context.Background(), &ReferenceRecord{
+				Alias:     "mrz",
+				Domain:    "test.com",
+				ExpiresAt: time.Now().Add(time.Hour),
+				Outputs:   []*ReferenceOutput{{Script: testLockingScript(t)}},
+				Reference: reference,
+				Satoshis:  1000,
+			}))
+		}
+		return store
+	}
+
+	t.Run("transaction sent with another reference is rejected", func(t *testing.T) {
+		handler, provider := handler(t, WithReplayGuard(NewMemoryReplayStore(), 0),
+			WithReferenceStore(referenceStore(t, "reference-1", "reference-2"), 0))
+		transaction := signedTransaction(t, 1000, "reference-1")
+
+		recorder := postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, recorder.Code)
+
+		transaction.Reference = "reference-2"
+		recorder = postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusConflict, recorder.Code)
+		assert.Equal(t, errors.ErrRequestReplayed.Code, errorCode(t, recorder))
+		assert.Equal(t, int32(1), provider.recorded.Load())
+
+		recorder = postTestJSON(t, handler, receivePath, signedTransaction(t, 2000, "reference-2"))
+		require.Equal(t, http.StatusOK, recorder.Code, "the released reference is used by another transaction")
+		assert.Equal(t, int32(2), provider.recorded.Load())
+	})
+
+	t.Run("failed recording can be sent again", func(t *testing.T) {
+		handler, provider := handler(t, WithReplayGuard(NewMemoryReplayStore(), 0),
+			WithReferenceStore(referenceStore(t, "reference-1"), 0))
+		transaction := signedTransaction(t, 1000, "reference-1")
+
+		provider.recordErr = errors.ErrMissingFieldHex
+		recorder := postTestJSON(t, handler, receivePath, transaction)
+		assert.Equal(t, errors.ErrMissingFieldHex.Code, errorCode(t, recorder))
+
+		provider.recordErr = nil
+		recorder = postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+
+	t.Run("resubmission without the reference store is recorded again", func(t *testing.T) {
+		handler, provider := handler(t, WithReplayGuard(NewMemoryReplayStore(), 0))
+		transaction := signedTransaction(t, 1000, "reference-1")
+
+		for range 2 {
+			recorder := postTestJSON(t, handler, receivePath, transaction)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		}
+		assert.Equal(t, int32(2), provider.recorded.Load())
+	})
+
+	t.Run("exact replay with the reference store returns the stored response", func(t *testing.T) {
+		handler, provider := handler(t, WithReplayGuard(NewMemoryReplayStore(), 0), WithReferenceStore(NewMemoryReferenceStore(), 0))
+		recorder := postTestJSON(t, handler, "/v1/bsvalias/p2p-payment-destination/mrz@test.com", map[string]uint64{"satoshis": 1000})
+		require.Equal(t, http.StatusOK, recorder.Code)
+		transaction := signedTransaction(t, 1000, "test-reference")
+
+		first := postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, first.Code)
+
+		replay := postTestJSON(t, handler, receivePath, transaction)
+		require.Equal(t, http.StatusOK, replay.Code)
+		assert.JSONEq(t, first.Body.String(), replay.Body.String())
+		assert.Equal(t, int32(1), provider.recorded.Load())
+	})
+
+	t.Run("replays are accepted without the replay guard", func(t *testing.T) {
+		handler, provider := handler(t)
+		transaction := signedTransaction(t, 1000, "reference-1")
+
+		for range 2 {
+			recorder := postTestJSON(t, handler, receivePath, transaction)
+			require.Equal(t, http.StatusOK, recorder.Code)
+		}
+		assert.Equal(t, int32(2), provider.recorded.Load())
+	})
+}
@@ -1,12 +1,12 @@
 package server
 
 import (
-	"net"
+	"context"
+	"encoding/json"
+	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
 
 	"github.com/bitcoin-sv/go-paymail"
 
@@ -32,141 +32,153 @@ Incoming Data Object Example:
 // resolveAddress will return the payment destination (bitcoin address) for the corresponding paymail address
 //
 // Specs: http://bsvalias.org/04-01-basic-address-resolution.html
-func (c *Configuration) resolveAddress(context *gin.Context) {
-	incomingPaymail := context.Param(PaymailAddressParamName)
+func (c *Configuration) resolveAddress(w http.ResponseWriter, req *http.Request) {
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
 
 	// Parse, sanitize and basic validation
 	alias, domain, paymailAddress := paymail.SanitizePaymail(incomingPaymail)
 	if len(paymailAddress) == 0 {
-		context.JSON(http.StatusBadRequest, "invalid paymail: "+incomingPaymail)
-		errors.ErrorResponse(context, errors.ErrInvalidPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrInvalidPaymail, c.Logger)
 		return
 	} else if !c.IsAllowedDomain(domain) {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	}
 
 	var senderRequest paymail.SenderRequest
-	err := context.Bind(&senderRequest)
+	err := json.NewDecoder(req.Body).Decode(&senderRequest)
 	if err != nil {
-		errors.ErrorResponse(context, errors.ErrCannotBindRequest, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCannotBindRequest, c.Logger)
 		return
 	}
 
 	// Check for required fields
 	if len(senderRequest.SenderHandle) == 0 {
-		errors.ErrorResponse(context, errors.ErrSenderHandleEmpty, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrSenderHandleEmpty, c.Logger)
 		return
 	} else if len(senderRequest.Dt) == 0 {
-		errors.ErrorResponse(context, errors.ErrDtEmpty, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDtEmpty, c.Logger)
 		return
 	}
 
-	// Validate the timestamp
-	if err = paymail.ValidateTimestamp(senderRequest.Dt); err != nil {
-		errors.ErrorResponse(context, errors.ErrInvalidTimestamp, c.Logger)
+	// Validate the timestamp (within the replay window)
+	dt, err := c.validateRequestTime(senderRequest.Dt)
+	if err != nil {
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	}
 
 	// Basic validation on sender handle
 	if err = paymail.ValidatePaymail(senderRequest.SenderHandle); err != nil {
-		errors.ErrorResponse(context, errors.ErrInvalidSenderHandle, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrInvalidSenderHandle, c.Logger)
 		return
 	}
 
+	// The replay key of the signed request is released if the request fails (it can be sent again)
+	var replayKey string
+	fail := func(err error) {
+		c.releaseReplay(context.WithoutCancel(req.Context()), replayKey)
+		errors.WriteErrorResponse(w, err, c.Logger)
+	}
+
 	// Only validate signatures if sender validation is enabled (skip if disabled)
 	if c.SenderValidationEnabled {
 		if len(senderRequest.Signature) > 0 {
 
 			// Get the pubKey from the corresponding sender paymail address
 			var senderPubKey *ec.PublicKey
-			senderPubKey, err = getSenderPubKey(senderRequest.SenderHandle)
+			senderPubKey, err = c.getSenderPubKey(req.Context(), senderRequest.SenderHandle)
 			if err != nil {
-				errors.ErrorResponse(context, err, c.Logger)
+				errors.WriteErrorResponse(w, err, c.Logger)
 				return
 			}
 
 			// Derive address from pubKey
 			var rawAddress *script.Address
 			if rawAddress, err = script.NewAddressFromPublicKey(senderPubKey, true); err != nil {
-				errors.ErrorResponse(context, errors.ErrInvalidSenderHandle, c.Logger)
+				errors.WriteErrorResponse(w, errors.ErrInvalidSenderHandle, c.Logger)
 				return
 			}
 
 			// Verify the signature
 			if err = senderRequest.Verify(rawAddress.AddressString, senderRequest.Signature); err != nil {
-				errors.ErrorResponse(context, errors.ErrInvalidSignature, c.Logger)
+				errors.WriteErrorResponse(w, errors.ErrInvalidSignature, c.Logger)
 				return
 			}
+
+			// Reject the replays of the signed request (the accepted key is released by fail)
+			key := resolveAddressReplayKey(&senderRequest)
+			if err = c.guardReplay(req.Context(), key, dt.Add(c.ReplayWindow)); err != nil {
+				errors.WriteErrorResponse(w, err, c.Logger)
+				return
+			}
+			replayKey = key
 		} else {
-			errors.ErrorResponse(context, errors.ErrMissingFieldSignature, c.Logger)
+			errors.WriteErrorResponse(w, errors.ErrMissingFieldSignature, c.Logger)
 			return
 		}
 	}
 
 	// Create the metadata struct
-	md := CreateMetadata(context.Request, alias, domain, "")
+	md := CreateMetadata(req, alias, domain, "")
 	md.ResolveAddress = &senderRequest
 
 	// Get from the data layer
-	foundPaymail, err := c.actions.GetPaymailByAlias(context.Request.Context(), alias, domain, md)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		fail(err)
 		return
 	} else if foundPaymail == nil {
-		errors.ErrorResponse(context, errors.ErrCouldNotFindPaymail, c.Logger)
+		fail(errors.ErrCouldNotFindPaymail)
 		return
 	}
 
 	// Get the resolution information
 	var response *paymail.ResolutionPayload
 	if response, err = c.actions.CreateAddressResolutionResponse(
-		context.Request.Context(), alias, domain, c.SenderValidationEnabled, md,
+		req.Context(), alias, domain, c.SenderValidationEnabled, md,
 	); err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		fail(err)
 		return
 	}
 
 	// Set the response
-	context.JSON(http.StatusOK, response)
+	writeJSON(w, http.StatusOK, response)
 }
 
 // getSenderPubKey will fetch the pubKey from a PKI request for the sender handle
-func getSenderPubKey(senderPaymailAddress string) (*ec.PublicKey, error) {
+func (c *Configuration) getSenderPubKey(ctx context.Context, senderPaymailAddress string) (*ec.PublicKey, error) {
 
 	// Sanitize and break apart
 	alias, domain, _ := paymail.SanitizePaymail(senderPaymailAddress)
 
-	// Load the client
-	client, err := paymail.NewClient(paymail.WithHTTPTimeout(15 * time.Second))
-	if err != nil {
-		return nil, err
-	}
-
 	// Get the SRV record
-	var srv *net.SRV
-	if srv, err = client.GetSRVRecord(
-		paymail.DefaultServiceName, paymail.DefaultProtocol, domain,
-	); err != nil {
+	srv, err := c.paymailClient.GetSRVRecordCtx(
+		ctx, paymail.DefaultServiceName, paymail.DefaultProtocol, domain,
+	)
+	if err != nil {
 		return nil, err
 	}
 
 	// Get the capabilities
 	// This is required first to get the corresponding PKI endpoint url
 	var capabilities *paymail.CapabilitiesResponse
-	if capabilities, err = client.GetCapabilities(
-		srv.Target, paymail.DefaultPort,
+	if capabilities, err = c.paymailClient.GetCapabilitiesCtx(
+		ctx, srv.Target, int(srv.Port),
 	); err != nil {
 		return nil, err
 	}
 
 	// Extract the PKI URL from the capabilities response
 	pkiURL := capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate)
+	if len(pkiURL) == 0 {
+		return nil, fmt.Errorf("%w: %s", paymail.ErrCapabilityMissing, paymail.BRFCPki)
+	}
 
 	// Get the actual PKI
 	var pki *paymail.PKIResponse
-	if pki, err = client.GetPKI(
-		pkiURL, alias, domain,
+	if pki, err = c.paymailClient.GetPKICtx(
+		ctx, pkiURL, alias, domain,
 	); err != nil {
 		return nil, err
 	}
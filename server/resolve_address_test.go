@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -9,15 +10,16 @@ import (
 // Test_getSenderPubKey will test the method getSenderPubKey()
 func Test_getSenderPubKey(t *testing.T) {
 	// todo: this needs proper mocking
+	c := testConfig(t, "test.com")
 
 	t.Run("error - bad domain", func(t *testing.T) {
-		key, err := getSenderPubKey("bad@domain.com")
+		key, err := c.getSenderPubKey(context.Background(), "bad@domain.com")
 		require.Error(t, err)
 		require.Nil(t, key)
 	})
 
 	t.Run("valid - good paymail", func(t *testing.T) {
-		key, err := getSenderPubKey("mrzz@handcash.io")
+		key, err := c.getSenderPubKey(context.Background(), "mrzz@handcash.io")
 		require.NoError(t, err)
 		require.NotNil(t, key)
 	})
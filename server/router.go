@@ -2,11 +2,15 @@ package server
 
 import (
 	"fmt"
-	"github.com/gin-gonic/gin"
+	"net/http"
 	"strings"
+
+	"github.com/gin-gonic/gin"
 )
 
 // Handlers are used to isolate loading the routes (used for testing)
+//
+// This is the gin adapter, see Configuration.Handler() for the net/http handler
 func Handlers(configuration *Configuration) *gin.Engine {
 	engine := gin.New()
 	engine.Use(gin.LoggerWithWriter(configuration.Logger), gin.Recovery())
@@ -26,39 +30,39 @@ func (c *Configuration) RegisterBasicRoutes(engine *gin.Engine) {
 
 	// Set the main index page (navigating to slash)
 	if c.BasicRoutes.AddIndexRoute {
-		engine.GET("/", index)
+		engine.GET("/", ginHandler(index))
 		// router.OPTIONS("/", router.SetCrossOriginHeaders) // Disabled for security
 	}
 
 	// Set the health request (used for load balancers)
 	if c.BasicRoutes.AddHealthRoute {
-		engine.GET("/health", health)
-		engine.OPTIONS("/health", health)
-		engine.HEAD("/health", health)
+		engine.GET("/health", ginHandler(c.health))
+		engine.OPTIONS("/health", ginHandler(c.health))
+		engine.HEAD("/health", ginHandler(c.health))
 	}
 }
 
 // RegisterRoutes register all the available paymail routes to the http router
 func (c *Configuration) RegisterRoutes(engine *gin.Engine) {
-	engine.GET("/.well-known/"+c.ServiceName, c.showCapabilities) // service discovery
+	engine.GET("/.well-known/"+c.ServiceName, ginHandler(c.showCapabilities)) // service discovery
 
-	for _, cap := range c.callableCapabilities {
-		c.registerRoute(engine, cap)
+	for key, cap := range c.callableCapabilities {
+		c.registerRoute(engine, key, cap)
 	}
 
 	for _, nestedCap := range c.nestedCapabilities {
-		for _, cap := range nestedCap {
-			c.registerRoute(engine, cap)
+		for key, cap := range nestedCap {
+			c.registerRoute(engine, key, cap)
 		}
 	}
 }
 
-func (c *Configuration) registerRoute(engine *gin.Engine, cap CallableCapability) {
+func (c *Configuration) registerRoute(engine *gin.Engine, key string, cap CallableCapability) {
 	routerPath := c.templateToRouterPath(cap.Path)
 	engine.Handle(
 		cap.Method,
 		routerPath,
-		cap.Handler,
+		ginHandler(c.withRateLimit(key, cap.Handler)),
 	)
 }
 
@@ -71,3 +75,13 @@ func (c *Configuration) templateToRouterPath(template string) string {
 func _routerParam(name string) string {
 	return ":" + name
 }
+
+// ginHandler adapts the net/http handler to gin (the gin params are set as path values of the request)
+func ginHandler(handler http.HandlerFunc) gin.HandlerFunc {
+	return func(context *gin.Context) {
+		for _, param := range context.Params {
+			context.Request.SetPathValue(param.Key, param.Value)
+		}
+		handler(context.Writer, context.Request)
+	}
+}
@@ -2,17 +2,195 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/rs/zerolog"
+
+	spverrors "github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// ServerState is the lifecycle state of the Server
+type ServerState int32
+
+// Lifecycle states of the Server
+const (
+	StateNew      ServerState = iota // Created, not started yet
+	StateStarting                    // Start was called, not listening yet
+	StateRunning                     // Accepting requests (ready)
+	StateDraining                    // Shutting down, in-flight requests are completing
+	StateStopped                     // Stopped (or failed to start)
 )
 
+// String will return the name of the state
+func (s ServerState) String() string {
+	switch s {
+	case StateNew:
+		return "new"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateDraining:
+		return "draining"
+	case StateStopped:
+		return "stopped"
+	}
+	return "unknown"
+}
+
+// Server is a Paymail server with lifecycle management (graceful shutdown, readiness & liveness)
+type Server struct {
+	addr       net.Addr       // Address the server is listening on (set by Start)
+	config     *Configuration // Configuration of the server
+	httpServer *http.Server   // Underlying HTTP server
+	inFlight   atomic.Int64   // Number of requests being handled
+	mu         sync.Mutex     // Protects addr
+	state      atomic.Int32   // Current ServerState
+	stopped    chan struct{}  // Closed when the shutdown has completed
+	stopOnce   sync.Once      // Shutdown runs only once
+	stopErr    error          // Result of the shutdown
+}
+
+// NewServer will create a Paymail Server (use Start to run it and Shutdown to stop it)
+func NewServer(c *Configuration) *Server {
+	s := &Server{
+		config:     c,
+		httpServer: CreateServer(c),
+		stopped:    make(chan struct{}),
+	}
+	s.httpServer.Handler = s.trackInFlight(s.httpServer.Handler)
+	return s
+}
+
+// Start will run the server until the context is canceled (graceful shutdown) or Shutdown is called
+//
+// Returns nil after a graceful shutdown, or the error if the server failed to start or run
+func (s *Server) Start(ctx context.Context) error {
+	if !s.state.CompareAndSwap(int32(StateNew), int32(StateStarting)) {
+		return spverrors.ErrServerAlreadyStarted
+	}
+
+	listener, err := new(net.ListenConfig).Listen(ctx, "tcp", s.httpServer.Addr)
+	if err != nil {
+		s.stop(context.Background(), false)
+		return err
+	}
+
+	s.mu.Lock()
+	s.addr = listener.Addr()
+	s.mu.Unlock()
+	s.config.draining.Store(false)
+	if !s.state.CompareAndSwap(int32(StateStarting), int32(StateRunning)) { // Shutdown was called while starting
+		_ = listener.Close()
+		<-s.stopped
+		return s.stopErr
+	}
+	s.config.Logger.Info().Str("address", s.addr.String()).Msg("starting go paymail server...")
+
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- s.httpServer.Serve(listener)
+	}()
+
+	select {
+	case err = <-serveErr:
+		if errors.Is(err, http.ErrServerClosed) { // Shutdown was called
+			<-s.stopped
+			return s.stopErr
+		}
+		s.stop(context.Background(), false)
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.config.DrainDelay+s.config.Timeout)
+		defer cancel()
+		return s.Shutdown(shutdownCtx)
+	}
+}
+
+// Shutdown will gracefully stop the server
+//
+// The health route reports unhealthy for the DrainDelay, then the server stops accepting
+// connections and waits for the in-flight requests (until the context is done).
+// Finally, the outbound resources (paymail client, unless set with WithPaymailClient) are closed.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.stop(ctx, true)
+	<-s.stopped
+	return s.stopErr
+}
+
+// stop will drain the server (if running) and close the resources (only once)
+func (s *Server) stop(ctx context.Context, drain bool) {
+	s.stopOnce.Do(func() {
+		defer close(s.stopped)
+
+		if drain && s.state.CompareAndSwap(int32(StateRunning), int32(StateDraining)) {
+			s.config.draining.Store(true)
+			s.config.Logger.Info().Int64("in_flight", s.InFlight()).Msg("draining go paymail server...")
+
+			select {
+			case <-time.After(s.config.DrainDelay):
+			case <-ctx.Done():
+			}
+			s.stopErr = s.httpServer.Shutdown(ctx)
+		}
+
+		if s.config.ownsPaymailClient {
+			s.config.paymailClient.Close()
+		}
+		s.state.Store(int32(StateStopped))
+		s.config.Logger.Info().Int64("in_flight", s.InFlight()).Msg("go paymail server stopped")
+	})
+}
+
+// trackInFlight will count the requests being handled
+func (s *Server) trackInFlight(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		s.inFlight.Add(1)
+		defer s.inFlight.Add(-1)
+		next.ServeHTTP(w, req)
+	})
+}
+
+// Addr will return the address the server is listening on (nil if not started)
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.addr
+}
+
+// InFlight will return the number of requests being handled
+func (s *Server) InFlight() int64 {
+	return s.inFlight.Load()
+}
+
+// State will return the current lifecycle state
+func (s *Server) State() ServerState {
+	return ServerState(s.state.Load())
+}
+
+// Live will return true if the server is starting, running or draining (liveness)
+func (s *Server) Live() bool {
+	state := s.State()
+	return state == StateStarting || state == StateRunning || state == StateDraining
+}
+
+// Ready will return true if the server is accepting new requests (readiness)
+func (s *Server) Ready() bool {
+	return s.State() == StateRunning
+}
+
 // CreateServer will create a basic Paymail Server
 func CreateServer(c *Configuration) *http.Server {
 	return &http.Server{
 		Addr:              fmt.Sprintf(":%d", c.Port), // Address to run the server on
-		Handler:           Handlers(c),                // Load all the routes
+		Handler:           c.Handler(),                // Load all the routes
 		ReadHeaderTimeout: c.Timeout,                  // Basic default timeout for header read requests
 		ReadTimeout:       c.Timeout,                  // Basic default timeout for read requests
 		WriteTimeout:      c.Timeout,                  // Basic default timeout for write requests
@@ -20,6 +198,8 @@ func CreateServer(c *Configuration) *http.Server {
 }
 
 // StartServer will run the Paymail server
+//
+// Deprecated: use NewServer and Server.Start (supports graceful shutdown)
 func StartServer(srv *http.Server, logger *zerolog.Logger) {
 	logger.Info().Str("address", srv.Addr).Msg("starting go paymail server...")
 	logger.Fatal().Msg(srv.ListenAndServe().Error())
@@ -1,17 +1,23 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	spverrors "github.com/bitcoin-sv/go-paymail/errors"
 )
 
 // TestCreateServer will test the method CreateServer()
@@ -80,3 +86,151 @@ func TestWithServer(t *testing.T) {
 		}
 	})
 }
+
+// startTestServer will start the server (on a random port) and return the result channel of Start()
+func startTestServer(t *testing.T, ctx context.Context, s *Server) (baseURL string, result chan error) {
+	result = make(chan error, 1)
+	go func() {
+		result <- s.Start(ctx)
+	}()
+	require.Eventually(t, s.Ready, time.Second, 5*time.Millisecond)
+	return "http://" + s.Addr().String(), result
+}
+
+// testLifecycleConfig will return a configuration listening on a random port
+func testLifecycleConfig(t *testing.T, opts ...ConfigOps) *Configuration {
+	sl := &PaymailServiceLocator{}
+	sl.RegisterPaymailService(new(mockServiceProvider))
+
+	config, err := NewConfig(sl, append([]ConfigOps{WithDomain("test.com"), WithBasicRoutes()}, opts...)...)
+	require.NoError(t, err)
+	config.Port = 0
+	return config
+}
+
+// closeCountingClient is a paymail client counting the calls of Close()
+type closeCountingClient struct {
+	paymail.ClientInterface
+	closed atomic.Int32
+}
+
+// Close will count the call
+func (c *closeCountingClient) Close() {
+	c.closed.Add(1)
+}
+
+// TestServer will test the lifecycle of the Server (Start, Shutdown, readiness & liveness)
+func TestServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("start and shutdown", func(t *testing.T) {
+		s := NewServer(testLifecycleConfig(t))
+		assert.Equal(t, StateNew, s.State())
+		assert.False(t, s.Live())
+		assert.Nil(t, s.Addr())
+
+		baseURL, result := startTestServer(t, context.Background(), s)
+		assert.True(t, s.Live())
+
+		resp, err := http.Get(baseURL + "/health")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.NoError(t, <-result)
+		assert.Equal(t, StateStopped, s.State())
+		assert.False(t, s.Live())
+		assert.False(t, s.Ready())
+
+		// Shutdown can be called more than once
+		require.NoError(t, s.Shutdown(context.Background()))
+	})
+
+	t.Run("custom paymail client is not closed", func(t *testing.T) {
+		client := &closeCountingClient{}
+		s := NewServer(testLifecycleConfig(t, WithPaymailClient(client)))
+		_, result := startTestServer(t, context.Background(), s)
+
+		require.NoError(t, s.Shutdown(context.Background()))
+		require.NoError(t, <-result)
+		assert.Equal(t, int32(0), client.closed.Load())
+	})
+
+	t.Run("canceled context shuts down the server", func(t *testing.T) {
+		s := NewServer(testLifecycleConfig(t))
+		ctx, cancel := context.WithCancel(context.Background())
+		_, result := startTestServer(t, ctx, s)
+
+		cancel()
+		require.NoError(t, <-result)
+		assert.Equal(t, StateStopped, s.State())
+
+		assert.ErrorIs(t, s.Start(context.Background()), spverrors.ErrServerAlreadyStarted)
+	})
+
+	t.Run("in-flight requests are drained and health is unhealthy", func(t *testing.T) {
+		started, release := make(chan struct{}), make(chan struct{})
+		s := NewServer(testLifecycleConfig(t,
+			WithDrainDelay(200*time.Millisecond),
+			WithCapabilities(map[string]any{
+				"slow_cap": CallableCapability{
+					Path:   fmt.Sprintf("/slow/%s", PaymailAddressTemplate),
+					Method: http.MethodGet,
+					Handler: func(w http.ResponseWriter, _ *http.Request) {
+						close(started)
+						<-release
+						w.WriteHeader(http.StatusOK)
+					},
+				},
+			}),
+		))
+		baseURL, result := startTestServer(t, context.Background(), s)
+
+		slowStatus := make(chan int, 1)
+		go func() {
+			resp, err := http.Get(baseURL + "/v1/bsvalias/slow/mrz@test.com")
+			if err != nil {
+				slowStatus <- 0
+				return
+			}
+			_ = resp.Body.Close()
+			slowStatus <- resp.StatusCode
+		}()
+		<-started
+		assert.Equal(t, int64(1), s.InFlight())
+
+		shutdownErr := make(chan error, 1)
+		go func() {
+			shutdownErr <- s.Shutdown(context.Background())
+		}()
+		require.Eventually(t, func() bool { return s.State() == StateDraining }, time.Second, 5*time.Millisecond)
+		assert.False(t, s.Ready())
+		assert.True(t, s.Live())
+
+		resp, err := http.Get(baseURL + "/health")
+		require.NoError(t, err)
+		_ = resp.Body.Close()
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+		close(release)
+		assert.Equal(t, http.StatusOK, <-slowStatus)
+		require.NoError(t, <-shutdownErr)
+		require.NoError(t, <-result)
+		assert.Equal(t, int64(0), s.InFlight())
+	})
+
+	t.Run("address in use", func(t *testing.T) {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+		defer func() { _ = listener.Close() }()
+
+		config := testLifecycleConfig(t)
+		config.Port = listener.Addr().(*net.TCPAddr).Port
+
+		s := NewServer(config)
+		s.httpServer.Addr = listener.Addr().String()
+		require.Error(t, s.Start(context.Background()))
+		assert.Equal(t, StateStopped, s.State())
+	})
+}
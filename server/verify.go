@@ -2,7 +2,6 @@ package server
 
 import (
 	"github.com/bitcoin-sv/go-paymail/errors"
-	"github.com/gin-gonic/gin"
 	"net/http"
 
 	"github.com/bitcoin-sv/go-paymail"
@@ -11,36 +10,36 @@ import (
 // verifyPubKey will return a response if the pubkey matches the paymail given
 //
 // Specs: https://bsvalias.org/05-verify-public-key-owner.html
-func (c *Configuration) verifyPubKey(context *gin.Context) {
-	incomingPaymail := context.Param(PaymailAddressParamName)
-	incomingPubKey := context.Param(PubKeyParamName)
+func (c *Configuration) verifyPubKey(w http.ResponseWriter, req *http.Request) {
+	incomingPaymail := req.PathValue(PaymailAddressParamName)
+	incomingPubKey := req.PathValue(PubKeyParamName)
 
 	// Parse, sanitize and basic validation
 	alias, domain, address := paymail.SanitizePaymail(incomingPaymail)
 	if len(address) == 0 {
-		errors.ErrorResponse(context, errors.ErrInvalidPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrInvalidPaymail, c.Logger)
 		return
 	} else if !c.IsAllowedDomain(domain) {
-		errors.ErrorResponse(context, errors.ErrDomainUnknown, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrDomainUnknown, c.Logger)
 		return
 	}
 
 	// Basic validation on pubkey
 	if len(incomingPubKey) != paymail.PubKeyLength {
-		errors.ErrorResponse(context, errors.ErrInvalidPubKey, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrInvalidPubKey, c.Logger)
 		return
 	}
 
 	// Create the metadata struct
-	md := CreateMetadata(context.Request, alias, domain, "")
+	md := CreateMetadata(req, alias, domain, "")
 
 	// Get from the data layer
-	foundPaymail, err := c.actions.GetPaymailByAlias(context.Request.Context(), alias, domain, md)
+	foundPaymail, err := c.actions.GetPaymailByAlias(req.Context(), alias, domain, md)
 	if err != nil {
-		errors.ErrorResponse(context, err, c.Logger)
+		errors.WriteErrorResponse(w, err, c.Logger)
 		return
 	} else if foundPaymail == nil {
-		errors.ErrorResponse(context, errors.ErrCouldNotFindPaymail, c.Logger)
+		errors.WriteErrorResponse(w, errors.ErrCouldNotFindPaymail, c.Logger)
 		return
 	}
 
@@ -51,5 +50,5 @@ func (c *Configuration) verifyPubKey(context *gin.Context) {
 		Match:    foundPaymail.PubKey == incomingPubKey,
 	}
 
-	context.JSON(http.StatusOK, verPayload)
+	writeJSON(w, http.StatusOK, verPayload)
 }
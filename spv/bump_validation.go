@@ -8,47 +8,46 @@ import (
 	sdk "github.com/bitcoin-sv/go-sdk/transaction"
 )
 
-func ensureAncestorsArePresentInBump(tx *sdk.Transaction, dBeef *beef.DecodedBEEF) error {
-	ancestors, err := findMinedAncestors(tx, dBeef.Transactions)
-	if err != nil {
-		return err
-	}
+// findMinedAncestors will find the mined ancestors of the transaction and check that they are present in their BUMPs
+func findMinedAncestors(tx *sdk.Transaction, index txIndex, bumps beef.BUMPs) ([]*AncestorReport, error) {
+	ancestors := make([]*AncestorReport, 0)
+	visited := make(map[string]struct{})
 
-	for _, tx := range ancestors {
-		if !existsInBumps(tx, dBeef.BUMPs) {
-			return errors.ErrBUMPAncestorNotPresent
+	for _, input := range tx.Inputs {
+		if err := findMinedAncestorsForInput(input, index, bumps, visited, &ancestors); err != nil {
+			return ancestors, err
 		}
 	}
 
-	return nil
-}
-
-func findMinedAncestors(tx *sdk.Transaction, ancestors []*beef.TxData) (map[string]*beef.TxData, error) {
-	am := make(map[string]*beef.TxData)
-
-	for _, input := range tx.Inputs {
-
-		if err := findMinedAncestorsForInput(input, ancestors, am); err != nil {
-			return nil, err
+	for _, ancestor := range ancestors {
+		if !ancestor.Proven {
+			return ancestors, errors.ErrBUMPAncestorNotPresent
 		}
 	}
 
-	return am, nil
+	return ancestors, nil
 }
 
-func findMinedAncestorsForInput(input *sdk.TransactionInput, ancestors []*beef.TxData, ma map[string]*beef.TxData) error {
-	parent := findParentForInput(input, ancestors)
+func findMinedAncestorsForInput(input *sdk.TransactionInput, index txIndex, bumps beef.BUMPs, visited map[string]struct{}, ancestors *[]*AncestorReport) error {
+	parent := index.findParentForInput(input)
 	if parent == nil {
 		return errors.ErrBUMPCouldNotFindMinedParent
+	} else if parent.IsTxIDOnly() {
+		return errors.ErrTxIDOnlyParent
+	}
+
+	if _, ok := visited[parent.GetTxID()]; ok {
+		return nil
 	}
+	visited[parent.GetTxID()] = struct{}{}
 
 	if !parent.Unmined() {
-		ma[parent.GetTxID()] = parent
+		*ancestors = append(*ancestors, proveAncestor(parent, bumps))
 		return nil
 	}
 
 	for _, in := range parent.Transaction.Inputs {
-		err := findMinedAncestorsForInput(in, ancestors, ma) // we don't have to worry about infinite recursion - the graph will always be acyclic due to the nature of the transactions
+		err := findMinedAncestorsForInput(in, index, bumps, visited, ancestors) // we don't have to worry about infinite recursion - the graph will always be acyclic due to the nature of the transactions
 		if err != nil {
 			return err
 		}
@@ -57,19 +56,20 @@ func findMinedAncestorsForInput(input *sdk.TransactionInput, ancestors []*beef.T
 	return nil
 }
 
-func existsInBumps(tx *beef.TxData, bumps beef.BUMPs) bool {
-	bumpIdx := int(*tx.BumpIndex)
-	txID := tx.GetTxID()
-
-	if len(bumps) > bumpIdx {
-		leafs := bumps[bumpIdx].Path[0]
+// proveAncestor will check that the mined ancestor is present in its BUMP
+func proveAncestor(tx *beef.TxData, bumps beef.BUMPs) *AncestorReport {
+	ancestor := &AncestorReport{BumpIndex: uint64(*tx.BumpIndex), TxID: tx.GetTxID()}
+	if ancestor.BumpIndex >= uint64(len(bumps)) || len(bumps[ancestor.BumpIndex].Path) == 0 {
+		return ancestor
+	}
 
-		for _, lf := range leafs {
-			if txID == lf.Hash {
-				return true
-			}
+	bump := bumps[ancestor.BumpIndex]
+	ancestor.BlockHeight = bump.BlockHeight
+	for _, lf := range bump.Path[0] {
+		if ancestor.TxID == lf.Hash {
+			ancestor.Proven = true
+			break
 		}
 	}
-
-	return false
+	return ancestor
 }
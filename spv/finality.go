@@ -0,0 +1,90 @@
+package spv
+
+import (
+	"context"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// LockTimeThreshold is the nLockTime from which the lock time is a unix timestamp (below it is a block height)
+const LockTimeThreshold = 500000000
+
+// finalSequence is the sequence of a final input
+const finalSequence = 0xffffffff
+
+// NonFinalPolicy is what to do with the transactions which are not final at the chain tip
+type NonFinalPolicy uint
+
+const (
+	// NonFinalReject will fail the SPV with errors.ErrLockTimeAndSequence (default)
+	NonFinalReject NonFinalPolicy = iota
+
+	// NonFinalAccept will accept the transactions (e.g. channels updated before they are final)
+	NonFinalAccept
+
+	// NonFinalQueue will accept the transactions to be queued until they are final (see Report.Queue)
+	NonFinalQueue
+)
+
+// Tip is the tip of the longest chain
+type Tip struct {
+	Height         uint32 `json:"height"`
+	MedianTimePast uint32 `json:"medianTimePast"` // Median time of the last 11 blocks (BIP113)
+}
+
+// ChainTip provides the tip of the longest chain to evaluate the lock times
+type ChainTip interface {
+	CurrentTip(ctx context.Context) (*Tip, error)
+}
+
+// WithChainTip will set the chain tip provider, without it any transaction with a lock time
+// and an input which is not final is evaluated as not final
+func WithChainTip(chainTip ChainTip) VerifyOps {
+	return func(o *verifyOptions) {
+		o.chainTip = chainTip
+	}
+}
+
+// finalityChecker evaluates the finality of the transactions, the tip is requested once (when needed)
+type finalityChecker struct {
+	chainTip ChainTip
+	ctx      context.Context
+	tip      *Tip
+}
+
+// isFinal will check if the transaction can be mined in the next block
+//
+// The transaction is final if all the inputs are final (max sequence) or the lock time
+// has passed: the height is below the next block height or the timestamp is below the median time past
+func (f *finalityChecker) isFinal(tx *sdk.Transaction) (bool, error) {
+	if tx.LockTime == 0 || allInputsFinal(tx) {
+		return true, nil
+	} else if f.chainTip == nil {
+		return false, nil
+	}
+
+	if f.tip == nil {
+		tip, err := f.chainTip.CurrentTip(f.ctx)
+		if err != nil || tip == nil {
+			return false, errors.ErrChainTipUnavailable
+		}
+		f.tip = tip
+	}
+
+	if tx.LockTime < LockTimeThreshold {
+		return uint64(tx.LockTime) <= uint64(f.tip.Height), nil
+	}
+	return tx.LockTime < f.tip.MedianTimePast, nil
+}
+
+// allInputsFinal will return true if every input has the max sequence
+func allInputsFinal(tx *sdk.Transaction) bool {
+	for _, input := range tx.Inputs {
+		if input.SequenceNumber != finalSequence {
+			return false
+		}
+	}
+	return true
+}
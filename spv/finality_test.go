@@ -0,0 +1,223 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+func TestFinalityChecker_IsFinal(t *testing.T) {
+	t.Parallel()
+
+	const tipHeight, tipTime = 850000, 1700000000
+
+	tcs := []struct {
+		name          string
+		lockTime      uint32
+		sequence      uint32
+		chainTip      ChainTip
+		expectedFinal bool
+		expectedError error
+	}{
+		{
+			name:          "no lock time",
+			sequence:      0,
+			chainTip:      &mockChainTip{},
+			expectedFinal: true,
+		},
+		{
+			name:          "final inputs",
+			lockTime:      tipHeight + 100,
+			sequence:      finalSequence,
+			expectedFinal: true,
+		},
+		{
+			name:     "lock time without chain tip",
+			lockTime: 1,
+		},
+		{
+			name:          "height lock time at the tip",
+			lockTime:      tipHeight,
+			chainTip:      &mockChainTip{tip: &Tip{Height: tipHeight, MedianTimePast: tipTime}},
+			expectedFinal: true,
+		},
+		{
+			name:     "height lock time above the tip",
+			lockTime: tipHeight + 1,
+			chainTip: &mockChainTip{tip: &Tip{Height: tipHeight, MedianTimePast: tipTime}},
+		},
+		{
+			name:          "time lock time before the median time past",
+			lockTime:      tipTime - 1,
+			chainTip:      &mockChainTip{tip: &Tip{Height: tipHeight, MedianTimePast: tipTime}},
+			expectedFinal: true,
+		},
+		{
+			name:     "time lock time at the median time past",
+			lockTime: tipTime,
+			chainTip: &mockChainTip{tip: &Tip{Height: tipHeight, MedianTimePast: tipTime}},
+		},
+		{
+			name:     "time lock time is not a height",
+			lockTime: LockTimeThreshold,
+			chainTip: &mockChainTip{tip: &Tip{Height: LockTimeThreshold + 1, MedianTimePast: LockTimeThreshold}},
+		},
+		{
+			name:          "chain tip failure",
+			lockTime:      1,
+			chainTip:      &mockChainTip{err: stderrors.New("node is down")},
+			expectedError: errors.ErrChainTipUnavailable,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			tx := sdk.NewTransaction()
+			tx.LockTime = tc.lockTime
+			tx.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{}, SequenceNumber: tc.sequence})
+			checker := &finalityChecker{chainTip: tc.chainTip, ctx: context.Background()}
+
+			// when
+			final, err := checker.isFinal(tx)
+
+			// then
+			require.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedFinal, final)
+		})
+	}
+
+	t.Run("the tip is requested once", func(t *testing.T) {
+		chainTip := &mockChainTip{tip: &Tip{Height: tipHeight}}
+		checker := &finalityChecker{chainTip: chainTip, ctx: context.Background()}
+		for i := 0; i < 3; i++ {
+			tx := sdk.NewTransaction()
+			tx.LockTime = tipHeight
+			tx.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{}})
+			_, err := checker.isFinal(tx)
+			require.NoError(t, err)
+		}
+		assert.Equal(t, 1, chainTip.calls)
+	})
+}
+
+func TestVerify_NonFinalTransactions(t *testing.T) {
+	t.Parallel()
+
+	const lockTime = 850000
+	decoded := lockedBEEF(t, lockTime)
+	tip := func(height uint32) VerifyOps {
+		return WithChainTip(&mockChainTip{tip: &Tip{Height: height}})
+	}
+
+	tcs := []struct {
+		name          string
+		opts          []VerifyOps
+		expectedError error
+		expectedQueue bool
+	}{
+		{
+			name:          "rejected without chain tip",
+			expectedError: errors.ErrLockTimeAndSequence,
+		},
+		{
+			name:          "rejected before the lock time",
+			opts:          []VerifyOps{tip(lockTime - 1)},
+			expectedError: errors.ErrLockTimeAndSequence,
+		},
+		{
+			name: "final at the tip",
+			opts: []VerifyOps{tip(lockTime)},
+		},
+		{
+			name: "accepted before the lock time",
+			opts: []VerifyOps{tip(lockTime - 1), WithPolicy(Policy{NonFinal: NonFinalAccept})},
+		},
+		{
+			name:          "queued before the lock time",
+			opts:          []VerifyOps{tip(lockTime - 1), WithPolicy(Policy{NonFinal: NonFinalQueue})},
+			expectedQueue: true,
+		},
+		{
+			name:          "queued without chain tip",
+			opts:          []VerifyOps{WithPolicy(Policy{NonFinal: NonFinalQueue})},
+			expectedQueue: true,
+		},
+		{
+			name:          "chain tip failure",
+			opts:          []VerifyOps{WithChainTip(&mockChainTip{err: stderrors.New("node is down")}), WithPolicy(Policy{NonFinal: NonFinalAccept})},
+			expectedError: errors.ErrChainTipUnavailable,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			report, err := Verify(context.Background(), decoded, append(tc.opts, WithMerkleRootVerifier(new(mockServiceProvider)))...)
+
+			// then
+			require.Equal(t, tc.expectedError, err)
+			assert.Equal(t, tc.expectedQueue, report.Queue)
+			if tc.expectedQueue {
+				assert.Equal(t, []string{decoded.GetLatestTx().TxID().String()}, report.NonFinalTxIDs())
+			}
+		})
+	}
+}
+
+// lockedBEEF will return a BEEF with a signed transaction locked until the height, spending a mined parent
+func lockedBEEF(t *testing.T, lockTime uint32) *beef.DecodedBEEF {
+	t.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(t, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(t, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(t, err)
+
+	parent := sdk.NewTransaction()
+	parent.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{1}, SequenceNumber: finalSequence})
+	parent.AddOutput(&sdk.TransactionOutput{Satoshis: 10000, LockingScript: lockingScript})
+
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(t, err)
+	tx := sdk.NewTransaction()
+	tx.LockTime = lockTime
+	tx.AddInputFromTx(parent, 0, unlocker)
+	tx.Inputs[0].SequenceNumber = 0
+	tx.AddOutput(&sdk.TransactionOutput{Satoshis: 9000, LockingScript: lockingScript})
+	require.NoError(t, tx.Sign())
+
+	bump := &beef.BUMP{BlockHeight: 800000, Path: [][]beef.BUMPLeaf{{
+		{Hash: parent.TxID().String(), TxId: true},
+		{Hash: chainhash.Hash{2}.String(), Offset: 1},
+	}}}
+	decoded, err := beef.NewBuilder(tx).AddAncestor(parent, bump).Build()
+	require.NoError(t, err)
+	return decoded
+}
+
+// mockChainTip is a ChainTip returning the configured tip
+type mockChainTip struct {
+	calls int
+	err   error
+	tip   *Tip
+}
+
+// CurrentTip will return the configured tip
+func (m *mockChainTip) CurrentTip(_ context.Context) (*Tip, error) {
+	m.calls++
+	return m.tip, m.err
+}
@@ -0,0 +1,120 @@
+// Package headers is a local block header store, it verifies the merkle roots of the BEEF BUMPs
+// against the longest chain of the imported headers (no network access required)
+package headers
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+)
+
+// HeaderSize is the size of a serialized block header
+const HeaderSize = 80
+
+var (
+	// MainnetPowLimit is the highest proof of work target of the mainnet (and testnet) blocks
+	MainnetPowLimit = compactToBig(0x1d00ffff)
+
+	// RegtestPowLimit is the highest proof of work target of the regtest blocks
+	RegtestPowLimit = compactToBig(0x207fffff)
+
+	// ErrInvalidHeader is returned when a block header cannot be decoded
+	ErrInvalidHeader = errors.New("invalid block header")
+
+	// ErrProofOfWork is returned when a block header does not satisfy its proof of work
+	ErrProofOfWork = errors.New("block header does not satisfy the proof of work")
+)
+
+// Header is a block header
+type Header struct {
+	Bits       uint32         `json:"bits"`
+	MerkleRoot chainhash.Hash `json:"merkleRoot"`
+	Nonce      uint32         `json:"nonce"`
+	PrevBlock  chainhash.Hash `json:"prevBlock"`
+	Timestamp  uint32         `json:"timestamp"`
+	Version    uint32         `json:"version"`
+}
+
+// NewHeaderFromBytes will decode the 80 bytes block header
+func NewHeaderFromBytes(b []byte) (*Header, error) {
+	if len(b) != HeaderSize {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidHeader, HeaderSize, len(b))
+	}
+
+	h := &Header{
+		Version:   binary.LittleEndian.Uint32(b[0:4]),
+		Timestamp: binary.LittleEndian.Uint32(b[68:72]),
+		Bits:      binary.LittleEndian.Uint32(b[72:76]),
+		Nonce:     binary.LittleEndian.Uint32(b[76:80]),
+	}
+	copy(h.PrevBlock[:], b[4:36])
+	copy(h.MerkleRoot[:], b[36:68])
+	return h, nil
+}
+
+// Bytes will serialize the block header (80 bytes)
+func (h *Header) Bytes() []byte {
+	b := make([]byte, 0, HeaderSize)
+	b = binary.LittleEndian.AppendUint32(b, h.Version)
+	b = append(b, h.PrevBlock[:]...)
+	b = append(b, h.MerkleRoot[:]...)
+	b = binary.LittleEndian.AppendUint32(b, h.Timestamp)
+	b = binary.LittleEndian.AppendUint32(b, h.Bits)
+	return binary.LittleEndian.AppendUint32(b, h.Nonce)
+}
+
+// Hash will return the block hash
+func (h *Header) Hash() chainhash.Hash {
+	return chainhash.DoubleHashH(h.Bytes())
+}
+
+// CheckProofOfWork will check the target of the bits (not above the limit) and the block hash against it
+func (h *Header) CheckProofOfWork(powLimit *big.Int) error {
+	target := compactToBig(h.Bits)
+	if target.Sign() <= 0 || target.Cmp(powLimit) > 0 {
+		return fmt.Errorf("%w: target of bits %08x is out of range", ErrProofOfWork, h.Bits)
+	}
+
+	hash := h.Hash()
+	if hashToBig(&hash).Cmp(target) > 0 {
+		return fmt.Errorf("%w: hash %s is above the target", ErrProofOfWork, hash)
+	}
+	return nil
+}
+
+// Work will return the expected number of hashes to mine the block (2^256 / (target + 1))
+func (h *Header) Work() *big.Int {
+	target := compactToBig(h.Bits)
+	if target.Sign() <= 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Div(new(big.Int).Lsh(big.NewInt(1), 256), target.Add(target, big.NewInt(1)))
+}
+
+// compactToBig will decode the compact target representation (negative targets are returned as negative)
+func compactToBig(compact uint32) *big.Int {
+	mantissa := int64(compact & 0x007fffff)
+	exponent := uint(compact >> 24)
+
+	var n *big.Int
+	if exponent <= 3 {
+		n = big.NewInt(mantissa >> (8 * (3 - exponent)))
+	} else {
+		n = new(big.Int).Lsh(big.NewInt(mantissa), 8*(exponent-3))
+	}
+	if compact&0x00800000 != 0 {
+		n.Neg(n)
+	}
+	return n
+}
+
+// hashToBig will interpret the hash (stored in little endian) as a number
+func hashToBig(hash *chainhash.Hash) *big.Int {
+	b := hash.CloneBytes()
+	slices.Reverse(b)
+	return new(big.Int).SetBytes(b)
+}
@@ -0,0 +1,94 @@
+package headers
+
+import (
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// mainnet blocks 0 to 2
+	genesisHeaderHex = "0100000000000000000000000000000000000000000000000000000000000000000000003ba3edfd7a7b12b27ac72c3e67768f617fc81bc3888a51323a9fb8aa4b1e5e4a29ab5f49ffff001d1dac2b7c"
+	block1HeaderHex  = "010000006fe28c0ab6f1b372c1a6a246ae63f74f931e8365e15a089c68d6190000000000982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e61bc6649ffff001d01e36299"
+	block2HeaderHex  = "010000004860eb18bf1b1620e37e9490fc8a427514416fd75159ab86688e9a8300000000d5fdcc541e25de1c7a5addedf24858b8bb665c9f36ef744ee42c316022c90f9bb0bc6649ffff001d08d2bd61"
+	genesisHash      = "000000000019d6689c085ae165831e934ff763ae46a2a6c172b3f1b60a8ce26f"
+	genesisRoot      = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+)
+
+func TestNewHeaderFromBytes(t *testing.T) {
+	t.Parallel()
+
+	b, err := hex.DecodeString(genesisHeaderHex)
+	require.NoError(t, err)
+
+	header, err := NewHeaderFromBytes(b)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), header.Version)
+	assert.Equal(t, genesisRoot, header.MerkleRoot.String())
+	assert.Equal(t, uint32(1231006505), header.Timestamp)
+	assert.Equal(t, uint32(0x1d00ffff), header.Bits)
+	assert.Equal(t, uint32(2083236893), header.Nonce)
+	assert.Equal(t, genesisHash, header.Hash().String())
+	assert.Equal(t, b, header.Bytes())
+
+	_, err = NewHeaderFromBytes(b[1:])
+	require.ErrorIs(t, err, ErrInvalidHeader)
+}
+
+func TestHeader_CheckProofOfWork(t *testing.T) {
+	t.Parallel()
+
+	header := testHeader(t, genesisHeaderHex)
+	require.NoError(t, header.CheckProofOfWork(MainnetPowLimit))
+	assert.Equal(t, big.NewInt(0x100010001), header.Work())
+
+	t.Run("hash above the target", func(t *testing.T) {
+		invalid := *header
+		invalid.Nonce++
+		require.ErrorIs(t, invalid.CheckProofOfWork(MainnetPowLimit), ErrProofOfWork)
+	})
+
+	t.Run("target above the limit", func(t *testing.T) {
+		invalid := *header
+		invalid.Bits = 0x1d01ffff
+		require.ErrorIs(t, invalid.CheckProofOfWork(MainnetPowLimit), ErrProofOfWork)
+	})
+
+	t.Run("negative or zero target", func(t *testing.T) {
+		for _, bits := range []uint32{0x1d80ffff, 0x1d000000, 0} {
+			invalid := *header
+			invalid.Bits = bits
+			require.ErrorIs(t, invalid.CheckProofOfWork(MainnetPowLimit), ErrProofOfWork)
+			assert.Equal(t, 0, invalid.Work().Sign())
+		}
+	})
+}
+
+func TestCompactToBig(t *testing.T) {
+	t.Parallel()
+
+	tcs := map[uint32]string{
+		0x1d00ffff: "ffff0000000000000000000000000000000000000000000000000000",
+		0x207fffff: "7fffff0000000000000000000000000000000000000000000000000000000000",
+		0x03123456: "123456",
+		0x02123456: "1234",
+		0x01123456: "12",
+		0x04923456: "-12345600",
+	}
+	for compact, expected := range tcs {
+		assert.Equal(t, expected, compactToBig(compact).Text(16))
+	}
+}
+
+// testHeader will decode the header hex
+func testHeader(t *testing.T, headerHex string) *Header {
+	t.Helper()
+	b, err := hex.DecodeString(headerHex)
+	require.NoError(t, err)
+	header, err := NewHeaderFromBytes(b)
+	require.NoError(t, err)
+	return header
+}
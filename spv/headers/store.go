@@ -0,0 +1,280 @@
+package headers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"os"
+	"slices"
+	"sync"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+
+	spverrors "github.com/bitcoin-sv/go-paymail/errors"
+	"github.com/bitcoin-sv/go-paymail/spv"
+)
+
+// medianTimeBlocks is the number of blocks of the median time past (BIP113)
+const medianTimeBlocks = 11
+
+var (
+	// ErrEmptyStore is returned when the store has no headers
+	ErrEmptyStore = errors.New("block header store is empty")
+
+	// ErrUnknownParent is returned when the previous block of a header is not in the store
+	ErrUnknownParent = errors.New("block header parent is unknown")
+)
+
+// Reorg describes a change of the longest chain to another branch
+type Reorg struct {
+	Depth      uint32         `json:"depth"`      // Number of blocks removed from the longest chain
+	ForkHeight uint32         `json:"forkHeight"` // Height of the last block common to both branches
+	NewTip     chainhash.Hash `json:"newTip"`
+	OldTip     chainhash.Hash `json:"oldTip"`
+}
+
+// Store is an in-memory block header store tracking the longest chain (the most chain work)
+//
+// The first header added to the store is trusted (checkpoint), every other header
+// must extend a stored header and satisfy its proof of work. The difficulty
+// adjustment rules are not checked, the store must be fed from a trusted source
+type Store struct {
+	byHash      map[chainhash.Hash]*entry
+	chain       []*entry // The longest chain (index is the height - startHeight)
+	mu          sync.RWMutex
+	onReorg     func(Reorg)
+	powLimit    *big.Int
+	startHeight uint32
+}
+
+// entry is a stored block header
+type entry struct {
+	chainWork *big.Int
+	hash      chainhash.Hash
+	header    *Header
+	height    uint32
+	parent    *entry
+}
+
+// StoreOps allow functional options to be supplied to NewStore
+type StoreOps func(s *Store)
+
+// WithPowLimit will set the highest proof of work target (default: MainnetPowLimit)
+func WithPowLimit(powLimit *big.Int) StoreOps {
+	return func(s *Store) {
+		if powLimit != nil {
+			s.powLimit = powLimit
+		}
+	}
+}
+
+// WithReorgHandler will set the function called after the longest chain switched to another branch
+func WithReorgHandler(handler func(Reorg)) StoreOps {
+	return func(s *Store) {
+		s.onReorg = handler
+	}
+}
+
+// WithStartHeight will set the height of the first (checkpoint) header (default: 0, the genesis block)
+func WithStartHeight(height uint32) StoreOps {
+	return func(s *Store) {
+		s.startHeight = height
+	}
+}
+
+// NewStore will create an empty header store
+func NewStore(opts ...StoreOps) *Store {
+	s := &Store{
+		byHash:   make(map[chainhash.Hash]*entry),
+		powLimit: MainnetPowLimit,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// AddHeader will add the block header, headers already in the store are ignored
+func (s *Store) AddHeader(header *Header) error {
+	if err := header.CheckProofOfWork(s.powLimit); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	reorg, err := s.addHeader(header)
+	s.mu.Unlock()
+
+	if err == nil && reorg != nil && s.onReorg != nil {
+		s.onReorg(*reorg)
+	}
+	return err
+}
+
+// addHeader will add the header and return the reorg (if any), the lock must be held
+func (s *Store) addHeader(header *Header) (*Reorg, error) {
+	e := &entry{hash: header.Hash(), header: header}
+	if _, ok := s.byHash[e.hash]; ok {
+		return nil, nil
+	}
+
+	if len(s.chain) == 0 {
+		e.chainWork, e.height = header.Work(), s.startHeight
+		s.byHash[e.hash] = e
+		s.chain = append(s.chain, e)
+		return nil, nil
+	}
+
+	parent, ok := s.byHash[header.PrevBlock]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownParent, header.PrevBlock)
+	} else if parent.height == math.MaxUint32 {
+		return nil, fmt.Errorf("%w: height out of range", ErrInvalidHeader)
+	}
+	e.parent, e.height = parent, parent.height+1
+	e.chainWork = new(big.Int).Add(parent.chainWork, header.Work())
+	s.byHash[e.hash] = e
+
+	tip := s.chain[len(s.chain)-1]
+	if e.chainWork.Cmp(tip.chainWork) <= 0 {
+		return nil, nil
+	}
+	if parent == tip {
+		s.chain = append(s.chain, e)
+		return nil, nil
+	}
+
+	// The branch has more work, switch the longest chain from the fork point
+	branch := []*entry{e}
+	fork := parent
+	for !s.inChain(fork) {
+		branch = append(branch, fork)
+		fork = fork.parent
+	}
+	s.chain = s.chain[:fork.height-s.startHeight+1]
+	for i := len(branch) - 1; i >= 0; i-- {
+		s.chain = append(s.chain, branch[i])
+	}
+
+	return &Reorg{
+		Depth:      tip.height - fork.height,
+		ForkHeight: fork.height,
+		NewTip:     e.hash,
+		OldTip:     tip.hash,
+	}, nil
+}
+
+// inChain will return true if the entry is in the longest chain, the lock must be held
+func (s *Store) inChain(e *entry) bool {
+	index := uint64(e.height) - uint64(s.startHeight)
+	return index < uint64(len(s.chain)) && s.chain[index] == e
+}
+
+// Import will read and add the 80 bytes headers until the end of the reader, returns the number of headers read
+func (s *Store) Import(r io.Reader) (int, error) {
+	buf := make([]byte, HeaderSize)
+	for count := 0; ; count++ {
+		if _, err := io.ReadFull(r, buf); errors.Is(err, io.EOF) {
+			return count, nil
+		} else if errors.Is(err, io.ErrUnexpectedEOF) {
+			return count, fmt.Errorf("%w: truncated header at index %d", ErrInvalidHeader, count)
+		} else if err != nil {
+			return count, err
+		}
+
+		header, err := NewHeaderFromBytes(buf)
+		if err != nil {
+			return count, err
+		}
+		if err = s.AddHeader(header); err != nil {
+			return count, fmt.Errorf("cannot add header at index %d: %w", count, err)
+		}
+	}
+}
+
+// ImportFile will import the file of 80 bytes headers (see Import)
+func (s *Store) ImportFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	return s.Import(f)
+}
+
+// Tip will return the height and the header of the longest chain tip (nil if the store is empty)
+func (s *Store) Tip() (uint32, *Header) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.chain) == 0 {
+		return 0, nil
+	}
+	tip := s.chain[len(s.chain)-1]
+	return tip.height, tip.header
+}
+
+// CurrentTip will return the height and the median time past of the longest chain tip (spv.ChainTip)
+func (s *Store) CurrentTip(_ context.Context) (*spv.Tip, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if len(s.chain) == 0 {
+		return nil, ErrEmptyStore
+	}
+
+	tip := s.chain[len(s.chain)-1]
+	timestamps := make([]uint32, 0, medianTimeBlocks)
+	for e := tip; e != nil && len(timestamps) < medianTimeBlocks; e = e.parent {
+		timestamps = append(timestamps, e.header.Timestamp)
+	}
+	slices.Sort(timestamps)
+	return &spv.Tip{Height: tip.height, MedianTimePast: timestamps[len(timestamps)/2]}, nil
+}
+
+// HeaderByHeight will return the header of the longest chain at the height
+func (s *Store) HeaderByHeight(height uint32) (*Header, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	e := s.entryByHeight(uint64(height))
+	if e == nil {
+		return nil, false
+	}
+	return e.header, true
+}
+
+// entryByHeight will return the entry of the longest chain at the height, the lock must be held
+func (s *Store) entryByHeight(height uint64) *entry {
+	if height < uint64(s.startHeight) || height-uint64(s.startHeight) >= uint64(len(s.chain)) {
+		return nil
+	}
+	return s.chain[height-uint64(s.startHeight)]
+}
+
+// VerifyMerkleRoots will check that each merkle root is the root of the longest chain block at the height
+func (s *Store) VerifyMerkleRoots(_ context.Context, merkleRoots []*spv.MerkleRootConfirmationRequestItem) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, item := range merkleRoots {
+		root, err := chainhash.NewHashFromHex(item.MerkleRoot)
+		if err != nil {
+			return spverrors.ErrMerkleRootNotConfirmed
+		}
+		if e := s.entryByHeight(item.BlockHeight); e == nil || !e.header.MerkleRoot.IsEqual(root) {
+			return spverrors.ErrMerkleRootNotConfirmed
+		}
+	}
+	return nil
+}
+
+// IsValidRootForHeight will check the merkle root of the longest chain block at the height (go-sdk ChainTracker)
+func (s *Store) IsValidRootForHeight(root *chainhash.Hash, height uint32) (bool, error) {
+	header, ok := s.HeaderByHeight(height)
+	return ok && header.MerkleRoot.IsEqual(root), nil
+}
@@ -0,0 +1,235 @@
+package headers
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+	"github.com/bitcoin-sv/go-paymail/spv"
+)
+
+func TestStore_Import(t *testing.T) {
+	t.Parallel()
+
+	headersFile := filepath.Join(t.TempDir(), "headers.bin")
+	var b []byte
+	for _, headerHex := range []string{genesisHeaderHex, block1HeaderHex, block2HeaderHex} {
+		b = append(b, testHeader(t, headerHex).Bytes()...)
+	}
+	require.NoError(t, os.WriteFile(headersFile, b, 0o600))
+
+	t.Run("mainnet headers", func(t *testing.T) {
+		store := NewStore()
+		count, err := store.ImportFile(headersFile)
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+
+		height, tip := store.Tip()
+		assert.Equal(t, uint32(2), height)
+		assert.Equal(t, "000000006a625f06636b8bb6ac7b960a8d03705d1ace08b1a19da3fdcc99ddbd", tip.Hash().String())
+
+		// importing again is a no-op
+		count, err = store.Import(bytes.NewReader(b))
+		require.NoError(t, err)
+		assert.Equal(t, 3, count)
+		height, _ = store.Tip()
+		assert.Equal(t, uint32(2), height)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := NewStore().ImportFile(filepath.Join(t.TempDir(), "missing.bin"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("truncated header", func(t *testing.T) {
+		count, err := NewStore().Import(bytes.NewReader(b[:2*HeaderSize+10]))
+		require.ErrorIs(t, err, ErrInvalidHeader)
+		assert.Equal(t, 2, count)
+	})
+
+	t.Run("unknown parent", func(t *testing.T) {
+		count, err := NewStore().Import(bytes.NewReader(append(b[:HeaderSize:HeaderSize], b[2*HeaderSize:]...)))
+		require.ErrorIs(t, err, ErrUnknownParent)
+		assert.Equal(t, 1, count)
+	})
+
+	t.Run("invalid proof of work", func(t *testing.T) {
+		invalid := bytes.Clone(b)
+		invalid[2*HeaderSize-1]++ // nonce of block 1
+
+		count, err := NewStore().Import(bytes.NewReader(invalid))
+		require.ErrorIs(t, err, ErrProofOfWork)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func TestStore_VerifyMerkleRoots(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(WithPowLimit(RegtestPowLimit), WithStartHeight(800000))
+	chain := mineChain(t, store, mineHeader(t, nil, 0), 5)
+	root := func(height uint32) string {
+		return chain[height-800000].MerkleRoot.String()
+	}
+
+	tcs := []struct {
+		name          string
+		items         []*spv.MerkleRootConfirmationRequestItem
+		expectedError error
+	}{
+		{
+			name: "roots of the longest chain",
+			items: []*spv.MerkleRootConfirmationRequestItem{
+				{MerkleRoot: root(800000), BlockHeight: 800000},
+				{MerkleRoot: root(800003), BlockHeight: 800003},
+				{MerkleRoot: root(800005), BlockHeight: 800005},
+			},
+		},
+		{
+			name:  "no roots",
+			items: []*spv.MerkleRootConfirmationRequestItem{},
+		},
+		{
+			name: "root at another height",
+			items: []*spv.MerkleRootConfirmationRequestItem{
+				{MerkleRoot: root(800000), BlockHeight: 800000},
+				{MerkleRoot: root(800003), BlockHeight: 800002},
+			},
+			expectedError: errors.ErrMerkleRootNotConfirmed,
+		},
+		{
+			name:          "height above the tip",
+			items:         []*spv.MerkleRootConfirmationRequestItem{{MerkleRoot: root(800005), BlockHeight: 800006}},
+			expectedError: errors.ErrMerkleRootNotConfirmed,
+		},
+		{
+			name:          "height below the checkpoint",
+			items:         []*spv.MerkleRootConfirmationRequestItem{{MerkleRoot: root(800000), BlockHeight: 799999}},
+			expectedError: errors.ErrMerkleRootNotConfirmed,
+		},
+		{
+			name:          "invalid root",
+			items:         []*spv.MerkleRootConfirmationRequestItem{{MerkleRoot: "invalid", BlockHeight: 800000}},
+			expectedError: errors.ErrMerkleRootNotConfirmed,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			err := store.VerifyMerkleRoots(context.Background(), tc.items)
+
+			// then
+			require.Equal(t, tc.expectedError, err)
+		})
+	}
+
+	t.Run("go-sdk chain tracker", func(t *testing.T) {
+		items := []*spv.MerkleRootConfirmationRequestItem{{MerkleRoot: root(800004), BlockHeight: 800004}}
+		require.NoError(t, spv.NewChainTrackerVerifier(store).VerifyMerkleRoots(context.Background(), items))
+
+		items[0].BlockHeight = 800001
+		require.Equal(t, errors.ErrMerkleRootNotConfirmed, spv.NewChainTrackerVerifier(store).VerifyMerkleRoots(context.Background(), items))
+	})
+}
+
+func TestStore_Reorg(t *testing.T) {
+	t.Parallel()
+
+	var reorgs []Reorg
+	store := NewStore(WithPowLimit(RegtestPowLimit), WithReorgHandler(func(r Reorg) {
+		reorgs = append(reorgs, r)
+	}))
+	chainA := mineChain(t, store, mineHeader(t, nil, 0), 3)
+
+	// a branch with the same work does not replace the longest chain
+	chainB := mineChain(t, store, chainA[1], 2)
+	height, tip := store.Tip()
+	assert.Equal(t, uint32(3), height)
+	assert.Equal(t, chainA[3], tip)
+	assert.Empty(t, reorgs)
+
+	// a branch with more work replaces the longest chain
+	chainB = append(chainB, mineChain(t, store, chainB[len(chainB)-1], 1)[1:]...)
+	height, tip = store.Tip()
+	assert.Equal(t, uint32(4), height)
+	assert.Equal(t, chainB[3], tip)
+	require.Equal(t, []Reorg{{Depth: 2, ForkHeight: 1, NewTip: chainB[3].Hash(), OldTip: chainA[3].Hash()}}, reorgs)
+
+	for height, header := range chainB {
+		stored, ok := store.HeaderByHeight(uint32(height) + 1)
+		require.True(t, ok)
+		assert.Equal(t, header, stored)
+	}
+
+	valid, err := store.IsValidRootForHeight(&chainB[1].MerkleRoot, 2)
+	require.NoError(t, err)
+	assert.True(t, valid)
+	valid, err = store.IsValidRootForHeight(&chainA[2].MerkleRoot, 2)
+	require.NoError(t, err)
+	assert.False(t, valid, "the block of the replaced branch is not in the longest chain")
+
+	// the replaced branch becomes the longest chain again
+	mineChain(t, store, chainA[3], 2)
+	height, _ = store.Tip()
+	assert.Equal(t, uint32(5), height)
+	require.Len(t, reorgs, 2)
+	assert.Equal(t, Reorg{Depth: 3, ForkHeight: 1, NewTip: reorgs[1].NewTip, OldTip: chainB[3].Hash()}, reorgs[1])
+}
+
+func TestStore_CurrentTip(t *testing.T) {
+	t.Parallel()
+
+	store := NewStore(WithPowLimit(RegtestPowLimit), WithStartHeight(100))
+	_, err := store.CurrentTip(context.Background())
+	require.ErrorIs(t, err, ErrEmptyStore)
+
+	chain := mineChain(t, store, mineHeader(t, nil, 0), 14)
+	tip, err := store.CurrentTip(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, uint32(114), tip.Height)
+	assert.Equal(t, chain[9].Timestamp, tip.MedianTimePast, "median of the last 11 blocks (the timestamps are increasing)")
+
+	// the first blocks
+	store = NewStore(WithPowLimit(RegtestPowLimit))
+	chain = mineChain(t, store, mineHeader(t, nil, 0), 2)
+	tip, err = store.CurrentTip(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, chain[1].Timestamp, tip.MedianTimePast)
+}
+
+// mineChain will add the first header and mine n headers on top of it, returns the added headers
+func mineChain(t *testing.T, store *Store, first *Header, n int) []*Header {
+	t.Helper()
+	chain := []*Header{first}
+	require.NoError(t, store.AddHeader(first))
+	for i := 0; i < n; i++ {
+		header := mineHeader(t, chain[len(chain)-1], uint32(i))
+		require.NoError(t, store.AddHeader(header))
+		chain = append(chain, header)
+	}
+	return chain
+}
+
+// mineHeader will mine a regtest header on top of the parent (nil for a first header)
+func mineHeader(t *testing.T, parent *Header, salt uint32) *Header {
+	t.Helper()
+	header := &Header{Bits: 0x207fffff, Version: 1, Timestamp: 1700000000 + salt}
+	if parent != nil {
+		header.PrevBlock = parent.Hash()
+		header.Timestamp = parent.Timestamp + 600 + salt
+	}
+	header.MerkleRoot = chainhash.DoubleHashH(header.Bytes())
+
+	for header.CheckProofOfWork(RegtestPowLimit) != nil {
+		header.Nonce++
+	}
+	return header
+}
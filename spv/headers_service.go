@@ -0,0 +1,252 @@
+package spv
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// Defaults of the headers service verifier
+const (
+	DefaultHeadersServiceBatchSize = 500
+	DefaultHeadersServiceCacheSize = 10000
+	DefaultHeadersServiceCacheTTL  = 10 * time.Minute
+	DefaultHeadersServiceTimeout   = 30 * time.Second
+
+	headersServiceVerifyPath = "/api/v1/chain/merkleroot/verify"
+)
+
+// Confirmation states returned by the headers service (other roots are UNABLE_TO_VERIFY)
+const (
+	confirmationConfirmed = "CONFIRMED"
+	confirmationInvalid   = "INVALID"
+)
+
+// HeadersServiceVerifier verifies the merkle roots with a Block Headers Service
+//
+// The confirmed roots are cached (by height), a response contradicting the cache
+// (another root confirmed at a cached height) is handled as a reorg and
+// drops the cached roots from that height
+type HeadersServiceVerifier struct {
+	batchSize  int
+	cache      map[uint64]cachedMerkleRoot
+	cacheMu    sync.Mutex
+	cacheSize  int
+	cacheTTL   time.Duration
+	httpClient *resty.Client
+	token      string
+	url        string
+}
+
+// cachedMerkleRoot is a merkle root confirmed by the headers service
+type cachedMerkleRoot struct {
+	expires    time.Time
+	merkleRoot string
+}
+
+// merkleRootsConfirmations is the response of the headers service
+type merkleRootsConfirmations struct {
+	ConfirmationState string                   `json:"confirmationState"`
+	Confirmations     []merkleRootConfirmation `json:"confirmations"`
+}
+
+// merkleRootConfirmation is the headers service state of a single merkle root
+type merkleRootConfirmation struct {
+	BlockHash    string `json:"blockHash"`
+	BlockHeight  uint64 `json:"blockHeight"`
+	Confirmation string `json:"confirmation"`
+	MerkleRoot   string `json:"merkleRoot"`
+}
+
+// HeadersServiceOps allow functional options to be supplied to NewHeadersServiceVerifier
+type HeadersServiceOps func(v *HeadersServiceVerifier)
+
+// WithHeadersServiceBatchSize will set the maximum number of merkle roots sent in a single request
+func WithHeadersServiceBatchSize(size int) HeadersServiceOps {
+	return func(v *HeadersServiceVerifier) {
+		if size > 0 {
+			v.batchSize = size
+		}
+	}
+}
+
+// WithHeadersServiceCache will set the maximum number of cached roots and how long they are cached (zero ttl disables the cache)
+func WithHeadersServiceCache(size int, ttl time.Duration) HeadersServiceOps {
+	return func(v *HeadersServiceVerifier) {
+		v.cacheSize, v.cacheTTL = size, ttl
+	}
+}
+
+// WithHeadersServiceHTTPClient will overwrite the default HTTP client
+func WithHeadersServiceHTTPClient(client *resty.Client) HeadersServiceOps {
+	return func(v *HeadersServiceVerifier) {
+		if client != nil {
+			v.httpClient = client
+		}
+	}
+}
+
+// NewHeadersServiceVerifier will return a MerkleRootVerifier using the Block Headers Service
+// at the url (e.g. http://localhost:8080), the token is sent as a Bearer token (if set)
+func NewHeadersServiceVerifier(url, token string, opts ...HeadersServiceOps) *HeadersServiceVerifier {
+	v := &HeadersServiceVerifier{
+		batchSize: DefaultHeadersServiceBatchSize,
+		cache:     make(map[uint64]cachedMerkleRoot),
+		cacheSize: DefaultHeadersServiceCacheSize,
+		cacheTTL:  DefaultHeadersServiceCacheTTL,
+		token:     token,
+		url:       strings.TrimSuffix(url, "/") + headersServiceVerifyPath,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+
+	if v.httpClient == nil {
+		v.httpClient = resty.New().SetTimeout(DefaultHeadersServiceTimeout)
+	}
+	return v
+}
+
+// VerifyMerkleRoots will verify the merkle roots (not cached) in batches
+//
+// Returns errors.ErrMerkleRootInvalid if a root is not in the longest chain,
+// errors.ErrMerkleRootUnableToVerify if the service cannot verify a root (yet)
+// and errors.ErrHeadersServiceFailed if the service cannot be used
+func (v *HeadersServiceVerifier) VerifyMerkleRoots(ctx context.Context, merkleRoots []*MerkleRootConfirmationRequestItem) error {
+	pending := v.notCached(merkleRoots)
+
+	unableToVerify := false
+	for start := 0; start < len(pending); start += v.batchSize {
+		batch := pending[start:min(start+v.batchSize, len(pending))]
+		confirmations, err := v.verifyBatch(ctx, batch)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range batch {
+			switch confirmations[confirmationKey(item.BlockHeight, item.MerkleRoot)] {
+			case confirmationConfirmed:
+				v.cacheConfirmed(item)
+			case confirmationInvalid:
+				v.invalidateRoot(item)
+				return errors.ErrMerkleRootInvalid
+			default: // UNABLE_TO_VERIFY or missing in the response
+				unableToVerify = true
+			}
+		}
+	}
+
+	if unableToVerify {
+		return errors.ErrMerkleRootUnableToVerify
+	}
+	return nil
+}
+
+// Invalidate will drop the cached roots from the height (e.g. when the service notifies about a reorg)
+func (v *HeadersServiceVerifier) Invalidate(fromHeight uint64) {
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+	v.invalidateFrom(fromHeight)
+}
+
+// verifyBatch will send the batch and return the confirmation state by height and root
+func (v *HeadersServiceVerifier) verifyBatch(ctx context.Context, batch []*MerkleRootConfirmationRequestItem) (map[string]string, error) {
+	var response merkleRootsConfirmations
+	req := v.httpClient.R().SetContext(ctx).SetBody(batch).SetResult(&response)
+	if v.token != "" {
+		req.SetAuthToken(v.token)
+	}
+
+	resp, err := req.Post(v.url)
+	if err != nil || resp.StatusCode() != http.StatusOK {
+		return nil, errors.ErrHeadersServiceFailed
+	}
+
+	confirmations := make(map[string]string, len(response.Confirmations))
+	for _, confirmation := range response.Confirmations {
+		confirmations[confirmationKey(confirmation.BlockHeight, confirmation.MerkleRoot)] = confirmation.Confirmation
+	}
+	return confirmations, nil
+}
+
+// notCached will return the merkle roots (without duplicates) which are not confirmed in the cache
+func (v *HeadersServiceVerifier) notCached(merkleRoots []*MerkleRootConfirmationRequestItem) []*MerkleRootConfirmationRequestItem {
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+
+	now := time.Now()
+	seen := make(map[string]struct{}, len(merkleRoots))
+	pending := make([]*MerkleRootConfirmationRequestItem, 0, len(merkleRoots))
+	for _, item := range merkleRoots {
+		key := confirmationKey(item.BlockHeight, item.MerkleRoot)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		if cached, ok := v.cache[item.BlockHeight]; ok && now.Before(cached.expires) &&
+			strings.EqualFold(cached.merkleRoot, item.MerkleRoot) {
+			continue
+		}
+		pending = append(pending, item)
+	}
+	return pending
+}
+
+// cacheConfirmed will cache the confirmed root, another root cached at its height means a reorg
+func (v *HeadersServiceVerifier) cacheConfirmed(item *MerkleRootConfirmationRequestItem) {
+	if v.cacheTTL <= 0 || v.cacheSize <= 0 {
+		return
+	}
+
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+
+	if cached, ok := v.cache[item.BlockHeight]; ok && !strings.EqualFold(cached.merkleRoot, item.MerkleRoot) {
+		v.invalidateFrom(item.BlockHeight)
+	}
+
+	now := time.Now()
+	if len(v.cache) >= v.cacheSize {
+		for height, cached := range v.cache {
+			if !now.Before(cached.expires) {
+				delete(v.cache, height)
+			}
+		}
+		if len(v.cache) >= v.cacheSize {
+			clear(v.cache)
+		}
+	}
+	v.cache[item.BlockHeight] = cachedMerkleRoot{expires: now.Add(v.cacheTTL), merkleRoot: item.MerkleRoot}
+}
+
+// invalidateRoot will drop the cached roots from the height of the invalid root if it was cached
+func (v *HeadersServiceVerifier) invalidateRoot(item *MerkleRootConfirmationRequestItem) {
+	v.cacheMu.Lock()
+	defer v.cacheMu.Unlock()
+
+	if cached, ok := v.cache[item.BlockHeight]; ok && strings.EqualFold(cached.merkleRoot, item.MerkleRoot) {
+		v.invalidateFrom(item.BlockHeight)
+	}
+}
+
+// invalidateFrom will drop the cached roots from the height, the lock must be held
+func (v *HeadersServiceVerifier) invalidateFrom(fromHeight uint64) {
+	for height := range v.cache {
+		if height >= fromHeight {
+			delete(v.cache, height)
+		}
+	}
+}
+
+// confirmationKey is the key of a merkle root at a height
+func confirmationKey(height uint64, merkleRoot string) string {
+	return strconv.FormatUint(height, 10) + ":" + strings.ToLower(merkleRoot)
+}
@@ -0,0 +1,264 @@
+package spv
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+const (
+	testRoot1 = "2a7ca2b8bc0c9ba58b2beacbf5e52e61c2c8e6c0e2fd1c5bc41f8d4bbd6c3a42"
+	testRoot2 = "4a5e1e4baab89f3a32518a88c31bc87f618f76673e2cc77ab2127b7afdeda33b"
+	testRoot3 = "982051fd1e4ba744bbbe680e1fee14677ba1a3c3540bf7b1cdb606e857233e0e"
+)
+
+func TestHeadersServiceVerifier_VerifyMerkleRoots(t *testing.T) {
+	t.Parallel()
+
+	tcs := []struct {
+		name          string
+		states        map[string]string
+		items         []*MerkleRootConfirmationRequestItem
+		expectedError error
+	}{
+		{
+			name:   "all roots confirmed",
+			states: map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED"},
+			items:  []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}, {MerkleRoot: testRoot2, BlockHeight: 2}},
+		},
+		{
+			name:          "invalid root",
+			states:        map[string]string{testRoot1: "CONFIRMED", testRoot2: "INVALID"},
+			items:         []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}, {MerkleRoot: testRoot2, BlockHeight: 2}},
+			expectedError: errors.ErrMerkleRootInvalid,
+		},
+		{
+			name:          "root unable to verify",
+			states:        map[string]string{testRoot1: "UNABLE_TO_VERIFY", testRoot2: "CONFIRMED"},
+			items:         []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}, {MerkleRoot: testRoot2, BlockHeight: 2}},
+			expectedError: errors.ErrMerkleRootUnableToVerify,
+		},
+		{
+			name:          "invalid root takes precedence over unable to verify",
+			states:        map[string]string{testRoot1: "UNABLE_TO_VERIFY", testRoot2: "INVALID"},
+			items:         []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}, {MerkleRoot: testRoot2, BlockHeight: 2}},
+			expectedError: errors.ErrMerkleRootInvalid,
+		},
+		{
+			name:          "root missing in the response",
+			states:        map[string]string{testRoot1: "CONFIRMED"},
+			items:         []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}, {MerkleRoot: testRoot2, BlockHeight: 2}},
+			expectedError: errors.ErrMerkleRootUnableToVerify,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			service := newMockHeadersService(t, tc.states)
+			verifier := NewHeadersServiceVerifier(service.URL+"/", "test-token")
+
+			// when
+			err := verifier.VerifyMerkleRoots(context.Background(), tc.items)
+
+			// then
+			require.Equal(t, tc.expectedError, err)
+			assert.Equal(t, []string{"Bearer test-token"}, service.authorizations())
+		})
+	}
+
+	t.Run("service failure", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		t.Cleanup(server.Close)
+
+		err := NewHeadersServiceVerifier(server.URL, "").VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}})
+		require.Equal(t, errors.ErrHeadersServiceFailed, err)
+	})
+
+	t.Run("service not reachable", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		server.Close()
+
+		err := NewHeadersServiceVerifier(server.URL, "").VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot1, BlockHeight: 1}})
+		require.Equal(t, errors.ErrHeadersServiceFailed, err)
+	})
+}
+
+func TestHeadersServiceVerifier_Batches(t *testing.T) {
+	t.Parallel()
+
+	// given
+	service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED", testRoot3: "CONFIRMED"})
+	verifier := NewHeadersServiceVerifier(service.URL, "", WithHeadersServiceBatchSize(2))
+
+	// when
+	err := verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{
+		{MerkleRoot: testRoot1, BlockHeight: 1},
+		{MerkleRoot: testRoot2, BlockHeight: 2},
+		{MerkleRoot: testRoot1, BlockHeight: 1},
+		{MerkleRoot: testRoot3, BlockHeight: 3},
+	})
+
+	// then
+	require.NoError(t, err)
+	assert.Equal(t, [][]uint64{{1, 2}, {3}}, service.requestedHeights())
+	assert.Equal(t, []string{"", ""}, service.authorizations())
+}
+
+func TestHeadersServiceVerifier_Cache(t *testing.T) {
+	t.Parallel()
+
+	items := func(roots ...string) []*MerkleRootConfirmationRequestItem {
+		result := make([]*MerkleRootConfirmationRequestItem, 0, len(roots))
+		for i, root := range roots {
+			result = append(result, &MerkleRootConfirmationRequestItem{MerkleRoot: root, BlockHeight: uint64(i + 1)})
+		}
+		return result
+	}
+
+	t.Run("confirmed roots are cached", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "UNABLE_TO_VERIFY"})
+		verifier := NewHeadersServiceVerifier(service.URL, "")
+
+		require.Equal(t, errors.ErrMerkleRootUnableToVerify, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2)))
+		require.Equal(t, errors.ErrMerkleRootUnableToVerify, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2)))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1)))
+		assert.Equal(t, [][]uint64{{1, 2}, {2}}, service.requestedHeights())
+	})
+
+	t.Run("another root confirmed at a cached height invalidates the higher roots", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED", testRoot3: "CONFIRMED"})
+		verifier := NewHeadersServiceVerifier(service.URL, "")
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+
+		// reorg at height 2
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{{MerkleRoot: testRoot3, BlockHeight: 2}}))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+		assert.Equal(t, [][]uint64{{1, 2, 3}, {2}, {2, 3}}, service.requestedHeights())
+	})
+
+	t.Run("invalid cached root invalidates the higher roots", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED", testRoot3: "CONFIRMED"})
+		verifier := NewHeadersServiceVerifier(service.URL, "", WithHeadersServiceCache(10, time.Millisecond))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+		time.Sleep(2 * time.Millisecond)
+
+		// reorg at height 2 (the expired roots are verified again)
+		service.setState(testRoot2, "INVALID")
+		require.Equal(t, errors.ErrMerkleRootInvalid, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2)))
+		assert.Equal(t, [][]uint64{{1, 2, 3}, {1, 2}}, service.requestedHeights())
+		assert.Len(t, verifier.cache, 1)
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED", testRoot3: "CONFIRMED"})
+		verifier := NewHeadersServiceVerifier(service.URL, "")
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+
+		verifier.Invalidate(3)
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+		assert.Equal(t, [][]uint64{{1, 2, 3}, {3}}, service.requestedHeights())
+	})
+
+	t.Run("cache disabled", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED"})
+		verifier := NewHeadersServiceVerifier(service.URL, "", WithHeadersServiceCache(0, 0))
+
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1)))
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1)))
+		assert.Equal(t, [][]uint64{{1}, {1}}, service.requestedHeights())
+	})
+
+	t.Run("full cache", func(t *testing.T) {
+		service := newMockHeadersService(t, map[string]string{testRoot1: "CONFIRMED", testRoot2: "CONFIRMED", testRoot3: "CONFIRMED"})
+		verifier := NewHeadersServiceVerifier(service.URL, "", WithHeadersServiceCache(2, time.Hour))
+
+		require.NoError(t, verifier.VerifyMerkleRoots(context.Background(), items(testRoot1, testRoot2, testRoot3)))
+		assert.Len(t, verifier.cache, 1)
+	})
+}
+
+// mockHeadersService is a Block Headers Service stand-in returning the configured state of each root
+type mockHeadersService struct {
+	*httptest.Server
+	auth    []string
+	heights [][]uint64
+	mu      sync.Mutex
+	states  map[string]string
+}
+
+// newMockHeadersService will start the headers service stand-in
+func newMockHeadersService(t *testing.T, states map[string]string) *mockHeadersService {
+	t.Helper()
+	m := &mockHeadersService{states: states}
+	m.Server = httptest.NewServer(http.HandlerFunc(m.verify))
+	t.Cleanup(m.Close)
+	return m
+}
+
+// verify will handle the merkle roots verification request
+func (m *mockHeadersService) verify(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost || req.URL.Path != "/api/v1/chain/merkleroot/verify" {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	var items []*MerkleRootConfirmationRequestItem
+	if err := json.NewDecoder(req.Body).Decode(&items); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.auth = append(m.auth, req.Header.Get("Authorization"))
+
+	heights := make([]uint64, 0, len(items))
+	response := merkleRootsConfirmations{ConfirmationState: "CONFIRMED"}
+	for _, item := range items {
+		heights = append(heights, item.BlockHeight)
+		if state, ok := m.states[item.MerkleRoot]; ok {
+			response.Confirmations = append(response.Confirmations, merkleRootConfirmation{
+				BlockHeight:  item.BlockHeight,
+				Confirmation: state,
+				MerkleRoot:   item.MerkleRoot,
+			})
+		}
+	}
+	m.heights = append(m.heights, heights)
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// setState will change the state of the root
+func (m *mockHeadersService) setState(root, state string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.states[root] = state
+}
+
+// authorizations will return the Authorization header of each request
+func (m *mockHeadersService) authorizations() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.auth
+}
+
+// requestedHeights will return the heights of the roots of each request
+func (m *mockHeadersService) requestedHeights() [][]uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.heights
+}
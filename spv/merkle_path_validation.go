@@ -1,28 +1,9 @@
 package spv
 
 import (
-	"context"
-
 	"github.com/bitcoin-sv/go-paymail/beef"
 )
 
-func verifyMerkleRoots(ctx context.Context, dBeef *beef.DecodedBEEF, provider MerkleRootVerifier) error {
-	if err := ensureAncestorsArePresentInBump(dBeef.GetLatestTx(), dBeef); err != nil {
-		return err
-	}
-
-	verifyReq, err := getMerkleRootsVerificationRequests(dBeef.BUMPs)
-	if err != nil {
-		return err
-	}
-
-	if err = provider.VerifyMerkleRoots(ctx, verifyReq); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func getMerkleRootsVerificationRequests(bumps beef.BUMPs) ([]*MerkleRootConfirmationRequestItem, error) {
 	var reqItems []*MerkleRootConfirmationRequestItem
 
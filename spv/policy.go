@@ -0,0 +1,50 @@
+package spv
+
+import (
+	"math/bits"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// Policy is the receiver policy for the transactions which are not mined yet (the zero Policy accepts everything)
+type Policy struct {
+	AllowZeroValueOpReturn bool           `json:"allowZeroValueOpReturn"` // Zero value data outputs (OP_RETURN, OP_FALSE OP_RETURN) are not dust
+	DustLimit              uint64         `json:"dustLimit"`              // Outputs with fewer satoshis are dust (e.g. 1 rejects the zero value outputs)
+	MaxTxSize              uint64         `json:"maxTxSize"`              // Maximum size of a transaction in bytes (0 is unlimited)
+	MinFeeRateSatPerKB     uint64         `json:"minFeeRateSatPerKb"`     // Minimum fee rate in satoshis per 1000 bytes
+	NonFinal               NonFinalPolicy `json:"nonFinal"`               // What to do with the transactions which are not final (see WithChainTip)
+}
+
+// WithPolicy will set the policy evaluated for the transactions which are not mined
+func WithPolicy(policy Policy) VerifyOps {
+	return func(o *verifyOptions) {
+		o.policy = policy
+	}
+}
+
+// validatePolicy will check the size, the outputs and the fee of the transaction
+func (p Policy) validatePolicy(tx *sdk.Transaction, txReport *TransactionReport) error {
+	if p.MaxTxSize > 0 && uint64(txReport.Size) > p.MaxTxSize {
+		return errors.ErrTxTooLarge
+	}
+
+	for _, output := range tx.Outputs {
+		if output.Satoshis >= p.DustLimit {
+			continue
+		}
+		if output.Satoshis == 0 && p.AllowZeroValueOpReturn && output.LockingScript != nil && output.LockingScript.IsData() {
+			continue
+		}
+		return errors.ErrDustOutput
+	}
+
+	// fee * 1000 < rate * size (without overflows)
+	feeHi, feeLo := bits.Mul64(txReport.Fee, 1000)
+	minHi, minLo := bits.Mul64(p.MinFeeRateSatPerKB, uint64(txReport.Size))
+	if feeHi < minHi || (feeHi == minHi && feeLo < minLo) {
+		return errors.ErrFeeTooLow
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package spv
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+func TestVerify_Policy(t *testing.T) {
+	t.Parallel()
+
+	decoded, err := beef.DecodeBEEF(validNotMinedBeef)
+	require.NoError(t, err)
+
+	// the limits of the transactions which are not mined
+	report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)))
+	require.NoError(t, err)
+	minFeeRate, minOutput, maxSize := uint64(math.MaxUint64), uint64(math.MaxUint64), uint64(0)
+	for i, txReport := range report.Transactions {
+		if txReport.BumpIndex != nil {
+			continue
+		}
+		minFeeRate = min(minFeeRate, txReport.Fee*1000/uint64(txReport.Size))
+		maxSize = max(maxSize, uint64(txReport.Size))
+		for _, output := range decoded.Transactions[i].Transaction.Outputs {
+			minOutput = min(minOutput, output.Satoshis)
+		}
+	}
+	require.Positive(t, minFeeRate)
+
+	tcs := []struct {
+		name          string
+		policy        Policy
+		expectedError error
+	}{
+		{
+			name: "zero policy",
+		},
+		{
+			name:   "within the policy",
+			policy: Policy{DustLimit: minOutput, MaxTxSize: maxSize, MinFeeRateSatPerKB: minFeeRate},
+		},
+		{
+			name:          "fee rate too low",
+			policy:        Policy{MinFeeRateSatPerKB: minFeeRate + 1},
+			expectedError: errors.ErrFeeTooLow,
+		},
+		{
+			name:          "fee rate overflow",
+			policy:        Policy{MinFeeRateSatPerKB: math.MaxUint64},
+			expectedError: errors.ErrFeeTooLow,
+		},
+		{
+			name:          "dust output",
+			policy:        Policy{DustLimit: minOutput + 1},
+			expectedError: errors.ErrDustOutput,
+		},
+		{
+			name:          "transaction too large",
+			policy:        Policy{MaxTxSize: maxSize - 1},
+			expectedError: errors.ErrTxTooLarge,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)), WithPolicy(tc.policy))
+
+			// then
+			if tc.expectedError == nil {
+				require.NoError(t, err)
+				return
+			}
+			require.Equal(t, tc.expectedError, err)
+			assert.False(t, report.MerkleRootsConfirmed)
+		})
+	}
+
+	t.Run("mined transactions are not evaluated", func(t *testing.T) {
+		decoded, err := beef.DecodeBEEF(validFullMinedBeef)
+		require.NoError(t, err)
+		subject := decoded.GetLatestTx()
+
+		policy := Policy{MaxTxSize: uint64(subject.Size())}
+		for _, output := range subject.Outputs {
+			policy.DustLimit = max(policy.DustLimit, output.Satoshis)
+		}
+		_, err = Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)), WithPolicy(policy))
+		require.NoError(t, err)
+	})
+}
+
+func TestPolicy_ZeroValueOutputs(t *testing.T) {
+	t.Parallel()
+
+	opReturn, err := script.NewFromASM("OP_FALSE OP_RETURN 68656c6c6f")
+	require.NoError(t, err)
+	p2pkh, err := script.NewFromHex("76a914000000000000000000000000000000000000000088ac")
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		lockingScript *script.Script
+		policy        Policy
+		expectedError error
+	}{
+		{
+			name:          "zero value data output is allowed",
+			lockingScript: opReturn,
+			policy:        Policy{AllowZeroValueOpReturn: true, DustLimit: 1},
+		},
+		{
+			name:          "zero value data output is dust",
+			lockingScript: opReturn,
+			policy:        Policy{DustLimit: 1},
+			expectedError: errors.ErrDustOutput,
+		},
+		{
+			name:          "zero value output which is not data is dust",
+			lockingScript: p2pkh,
+			policy:        Policy{AllowZeroValueOpReturn: true, DustLimit: 1},
+			expectedError: errors.ErrDustOutput,
+		},
+		{
+			name:          "zero value output without dust limit",
+			lockingScript: p2pkh,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// given
+			tx := sdk.NewTransaction()
+			tx.AddOutput(&sdk.TransactionOutput{LockingScript: tc.lockingScript})
+
+			// when
+			err := tc.policy.validatePolicy(tx, &TransactionReport{Size: tx.Size()})
+
+			// then
+			require.Equal(t, tc.expectedError, err)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package spv
+
+// Report is the detailed result of the SPV of a BEEF (see Verify)
+//
+// The errors are reported as messages, the first failure is the error returned by Verify
+type Report struct {
+	Ancestors            []*AncestorReport                    `json:"ancestors"`            // Mined ancestors of the verified transaction and the BUMPs proving them
+	Error                string                               `json:"error,omitempty"`      // The first failure
+	MerkleRoots          []*MerkleRootConfirmationRequestItem `json:"merkleRoots"`          // Merkle roots of the BUMPs (in the BUMPs order)
+	MerkleRootsConfirmed bool                                 `json:"merkleRootsConfirmed"` // True if the merkle roots were confirmed by the verifier
+	Queue                bool                                 `json:"queue,omitempty"`      // True if the non final transactions must be queued until they are final (NonFinalQueue)
+	Transactions         []*TransactionReport                 `json:"transactions"`         // Results of the transactions (in the BEEF order)
+	Valid                bool                                 `json:"valid"`
+}
+
+// TransactionReport is the result of a single transaction of the BEEF
+//
+// The inputs are only verified for the transactions which are not mined (no BUMP)
+type TransactionReport struct {
+	BumpIndex *uint64        `json:"bumpIndex,omitempty"` // BUMP of the mined transaction
+	Error     string         `json:"error,omitempty"`
+	Fee       uint64         `json:"fee"` // Input sum minus output sum (not mined transactions)
+	InputSum  uint64         `json:"inputSum"`
+	Inputs    []*InputReport `json:"inputs,omitempty"`
+	NonFinal  bool           `json:"nonFinal,omitempty"` // The lock time has not passed at the chain tip
+	OutputSum uint64         `json:"outputSum"`
+	Size      int            `json:"size"`
+	TxID      string         `json:"txId"`
+	TxIDOnly  bool           `json:"txIdOnly,omitempty"` // Transaction provided as a txid only (BEEF V2), not verified
+}
+
+// InputReport is the result of a single input of a transaction which is not mined
+type InputReport struct {
+	Error             string `json:"error,omitempty"` // The parent transaction of the input is missing or invalid
+	Index             int    `json:"index"`
+	Satoshis          uint64 `json:"satoshis"`
+	ScriptError       string `json:"scriptError,omitempty"`
+	ScriptValid       bool   `json:"scriptValid"`
+	SourceOutputIndex uint32 `json:"sourceOutputIndex"`
+	SourceTxID        string `json:"sourceTxId"`
+}
+
+// AncestorReport is a mined ancestor of the verified transaction and the BUMP proving it
+type AncestorReport struct {
+	BlockHeight uint64 `json:"blockHeight"`
+	BumpIndex   uint64 `json:"bumpIndex"`
+	MerkleRoot  string `json:"merkleRoot,omitempty"`
+	Proven      bool   `json:"proven"` // True if the BUMP contains the ancestor
+	TxID        string `json:"txId"`
+}
+
+// NonFinalTxIDs will return the transactions which are not final (in the BEEF order)
+func (r *Report) NonFinalTxIDs() []string {
+	var txIDs []string
+	for _, txReport := range r.Transactions {
+		if txReport.NonFinal {
+			txIDs = append(txIDs, txReport.TxID)
+		}
+	}
+	return txIDs
+}
@@ -1,27 +1,96 @@
 package spv
 
 import (
-	"github.com/bitcoin-sv/go-paymail/beef"
-	"github.com/bitcoin-sv/go-paymail/errors"
+	"runtime"
+	"sync"
 
 	interpreter "github.com/bitcoin-sv/go-sdk/script/interpreter"
 	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
 )
 
-func validateScripts(tx *sdk.Transaction, inputTxs []*beef.TxData) error {
-	for i, input := range tx.Inputs {
-		inputParentTx := findParentForInput(input, inputTxs)
-		if inputParentTx == nil {
-			return errors.ErrNoMatchingTransactionsForInput
+// WithScriptWorkers will set the number of goroutines verifying the scripts (default is GOMAXPROCS, 1 is sequential)
+func WithScriptWorkers(workers int) VerifyOps {
+	return func(o *verifyOptions) {
+		if workers > 0 {
+			o.scriptWorkers = workers
+		}
+	}
+}
+
+// scriptJob is the verification of the scripts of a single input
+type scriptJob struct {
+	err      *error
+	inputIdx int
+	prevTx   *sdk.Transaction
+	tx       *sdk.Transaction
+}
+
+// verifyAllScripts will verify the scripts of the inputs of the transactions which are not mined
+// with a bounded pool of workers, the results are indexed as the transactions and their inputs
+//
+// The inputs spending a transaction missing in the BEEF (or txid only) are not verified (nil result)
+func verifyAllScripts(transactions []*beef.TxData, index txIndex, workers int) [][]error {
+	results := make([][]error, len(transactions))
+	jobs := make([]scriptJob, 0)
+	for i, txDt := range transactions {
+		if txDt.IsTxIDOnly() || !txDt.Unmined() {
+			continue
 		}
 
-		err := verifyScripts(tx, inputParentTx.Transaction, i)
-		if err != nil {
-			return errors.ErrInvalidScript
+		tx := txDt.Transaction
+		results[i] = make([]error, len(tx.Inputs))
+		for inputIdx, input := range tx.Inputs {
+			parent := index.findParentForInput(input)
+			if parent == nil || parent.IsTxIDOnly() || int(input.SourceTxOutIndex) >= len(parent.Transaction.Outputs) {
+				continue
+			}
+			jobs = append(jobs, scriptJob{err: &results[i][inputIdx], inputIdx: inputIdx, prevTx: parent.Transaction, tx: tx})
 		}
 	}
 
-	return nil
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	workers = min(workers, len(jobs))
+	if workers <= 1 {
+		for _, job := range jobs {
+			*job.err = verifyScripts(job.tx, job.prevTx, job.inputIdx)
+		}
+		return results
+	}
+
+	queue := make(chan scriptJob)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for job := range queue {
+				// the engine sets the source output of the verified input, each job gets its own input
+				*job.err = verifyScripts(withOwnInput(job.tx, job.inputIdx), job.prevTx, job.inputIdx)
+			}
+		}()
+	}
+	for _, job := range jobs {
+		queue <- job
+	}
+	close(queue)
+	wg.Wait()
+
+	return results
+}
+
+// withOwnInput will return a shallow copy of the transaction with a copy of the input, so the
+// inputs of the same transaction can be verified concurrently
+func withOwnInput(tx *sdk.Transaction, inputIdx int) *sdk.Transaction {
+	input := *tx.Inputs[inputIdx]
+	clone := *tx
+	clone.Inputs = make([]*sdk.TransactionInput, len(tx.Inputs))
+	copy(clone.Inputs, tx.Inputs)
+	clone.Inputs[inputIdx] = &input
+	return &clone
 }
 
 // Verify locking and unlocking scripts pair
@@ -0,0 +1,162 @@
+package spv
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+func TestVerifyAllScripts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("parallel and sequential results", func(t *testing.T) {
+		decoded := largeBEEF(t, 16, 3)
+		index := newTxIndex(decoded.Transactions)
+
+		for _, workers := range []int{1, 4, 0} {
+			results := verifyAllScripts(decoded.Transactions, index, workers)
+			require.Len(t, results, len(decoded.Transactions))
+			assert.Nil(t, results[0], "the mined parent is not verified")
+			for _, txResults := range results[1:] {
+				require.Len(t, txResults, 16)
+				for _, err := range txResults {
+					assert.NoError(t, err)
+				}
+			}
+		}
+	})
+
+	t.Run("invalid scripts are reported for their inputs", func(t *testing.T) {
+		decoded := largeBEEF(t, 16, 2)
+		subject := decoded.GetLatestTx()
+		subject.Inputs[5].UnlockingScript, subject.Inputs[6].UnlockingScript = subject.Inputs[6].UnlockingScript, subject.Inputs[5].UnlockingScript
+
+		results := verifyAllScripts(decoded.Transactions, newTxIndex(decoded.Transactions), 4)
+		for i, err := range results[len(results)-1] {
+			if i == 5 || i == 6 {
+				assert.Error(t, err, "input %d", i)
+			} else {
+				assert.NoError(t, err, "input %d", i)
+			}
+		}
+
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)), WithScriptWorkers(4))
+		require.Equal(t, errors.ErrInvalidScript, err)
+		assert.NotEmpty(t, report.Transactions[len(report.Transactions)-1].Inputs[5].ScriptError)
+	})
+
+	t.Run("inputs without a parent are not verified", func(t *testing.T) {
+		decoded := largeBEEF(t, 4, 1)
+		decoded.Transactions = decoded.Transactions[1:]
+
+		results := verifyAllScripts(decoded.Transactions, newTxIndex(decoded.Transactions), 4)
+		assert.Equal(t, []error{nil, nil, nil, nil}, results[0])
+	})
+}
+
+func TestVerify_LargeBEEF(t *testing.T) {
+	t.Parallel()
+
+	decoded := largeBEEF(t, 32, 4)
+
+	report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)), WithScriptWorkers(8))
+	require.NoError(t, err)
+	assert.True(t, report.Valid)
+	require.Len(t, report.Transactions, 5)
+	for _, input := range report.Transactions[4].Inputs {
+		assert.True(t, input.ScriptValid)
+	}
+	require.Len(t, report.Ancestors, 1)
+	assert.True(t, report.Ancestors[0].Proven)
+}
+
+// BenchmarkVerify benchmarks the method Verify() for large BEEF payloads
+func BenchmarkVerify(b *testing.B) {
+	shapes := []struct {
+		depth int
+		width int
+	}{
+		{depth: 1, width: 500}, // consolidation of many UTXOs
+		{depth: 50, width: 10}, // long ancestry
+	}
+
+	for _, shape := range shapes {
+		decoded := largeBEEF(b, shape.width, shape.depth)
+		for _, workers := range []int{1, 8} {
+			b.Run(fmt.Sprintf("width=%d/depth=%d/workers=%d", shape.width, shape.depth, workers), func(b *testing.B) {
+				for i := 0; i < b.N; i++ {
+					_, _ = Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)), WithScriptWorkers(workers))
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkNewTxIndex benchmarks the method newTxIndex()
+func BenchmarkNewTxIndex(b *testing.B) {
+	decoded := largeBEEF(b, 1, 1000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_ = newTxIndex(decoded.Transactions)
+	}
+}
+
+// largeBEEF will return a BEEF with a mined parent of width outputs and depth unmined
+// transactions, each of them spending all the width outputs of the previous one
+func largeBEEF(tb testing.TB, width, depth int) *beef.DecodedBEEF {
+	tb.Helper()
+
+	key, err := ec.NewPrivateKey()
+	require.NoError(tb, err)
+	address, err := script.NewAddressFromPublicKey(key.PubKey(), true)
+	require.NoError(tb, err)
+	lockingScript, err := p2pkh.Lock(address)
+	require.NoError(tb, err)
+	unlocker, err := p2pkh.Unlock(key, nil)
+	require.NoError(tb, err)
+
+	parent := sdk.NewTransaction()
+	parent.AddInput(&sdk.TransactionInput{SourceTXID: &chainhash.Hash{1}, SequenceNumber: finalSequence})
+	for i := 0; i < width; i++ {
+		parent.AddOutput(&sdk.TransactionOutput{Satoshis: 100000, LockingScript: lockingScript})
+	}
+	bump := &beef.BUMP{BlockHeight: 800000, Path: [][]beef.BUMPLeaf{{
+		{Hash: parent.TxID().String(), TxId: true},
+		{Hash: chainhash.Hash{2}.String(), Offset: 1},
+	}}}
+
+	var unmined []*sdk.Transaction
+	tx := parent
+	for level := 1; level <= depth; level++ {
+		child := sdk.NewTransaction()
+		for i := 0; i < width; i++ {
+			child.AddInputFromTx(tx, uint32(i), unlocker)
+			child.AddOutput(&sdk.TransactionOutput{Satoshis: 100000 - uint64(level)*100, LockingScript: lockingScript})
+		}
+		require.NoError(tb, child.Sign())
+
+		if level < depth {
+			unmined = append(unmined, child)
+		}
+		tx = child
+	}
+
+	builder := beef.NewBuilder(tx).AddAncestor(parent, bump)
+	for _, ancestor := range unmined {
+		builder.AddAncestor(ancestor, nil)
+	}
+	decoded, err := builder.Build()
+	require.NoError(tb, err)
+	return decoded
+}
@@ -0,0 +1,53 @@
+package spv
+
+import (
+	"context"
+	"math"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/chaintracker"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// ExecuteSimplifiedPaymentVerificationSDK executes the SPV for the go-sdk Beef (see beef.FromSDK)
+func ExecuteSimplifiedPaymentVerificationSDK(ctx context.Context, b *sdk.Beef, provider MerkleRootVerifier) error {
+	dBeef, err := beef.FromSDK(b)
+	if err != nil {
+		return err
+	}
+	return ExecuteSimplifiedPaymentVerification(ctx, dBeef, provider)
+}
+
+// chainTrackerVerifier verifies the merkle roots with a go-sdk ChainTracker
+type chainTrackerVerifier struct {
+	tracker chaintracker.ChainTracker
+}
+
+// NewChainTrackerVerifier will return a MerkleRootVerifier using the go-sdk ChainTracker
+func NewChainTrackerVerifier(tracker chaintracker.ChainTracker) MerkleRootVerifier {
+	return &chainTrackerVerifier{tracker: tracker}
+}
+
+// VerifyMerkleRoots will check each merkle root with the ChainTracker
+func (v *chainTrackerVerifier) VerifyMerkleRoots(_ context.Context, merkleRoots []*MerkleRootConfirmationRequestItem) error {
+	for _, item := range merkleRoots {
+		if item.BlockHeight > math.MaxUint32 {
+			return errors.ErrMerkleRootNotConfirmed
+		}
+		root, err := chainhash.NewHashFromHex(item.MerkleRoot)
+		if err != nil {
+			return err
+		}
+
+		valid, err := v.tracker.IsValidRootForHeight(root, uint32(item.BlockHeight))
+		if err != nil {
+			return err
+		} else if !valid {
+			return errors.ErrMerkleRootNotConfirmed
+		}
+	}
+	return nil
+}
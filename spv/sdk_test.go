@@ -0,0 +1,99 @@
+package spv
+
+import (
+	"context"
+	stderrors "errors"
+	"testing"
+
+	chainhash "github.com/bitcoin-sv/go-sdk/chainhash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+func TestExecuteSimplifiedPaymentVerificationSDK(t *testing.T) {
+	t.Parallel()
+
+	for _, beefHex := range []string{validFullMinedBeef, validNotMinedBeef} {
+		// given
+		decoded, err := beef.DecodeBEEF(beefHex)
+		require.NoError(t, err)
+		b, err := decoded.ToSDK()
+		require.NoError(t, err)
+
+		// when
+		err = ExecuteSimplifiedPaymentVerificationSDK(context.Background(), b, new(mockServiceProvider))
+
+		// then
+		require.NoError(t, err)
+	}
+
+	t.Run("missing Beef", func(t *testing.T) {
+		err := ExecuteSimplifiedPaymentVerificationSDK(context.Background(), nil, new(mockServiceProvider))
+		require.Error(t, err)
+	})
+}
+
+func TestNewChainTrackerVerifier(t *testing.T) {
+	t.Parallel()
+
+	decoded, err := beef.DecodeBEEF(validFullMinedBeef)
+	require.NoError(t, err)
+
+	tcs := []struct {
+		name          string
+		tracker       *mockChainTracker
+		expectedError error
+	}{
+		{
+			name:    "all merkle roots are valid",
+			tracker: &mockChainTracker{valid: true},
+		},
+		{
+			name:          "merkle root is not valid",
+			tracker:       &mockChainTracker{valid: false},
+			expectedError: errors.ErrMerkleRootNotConfirmed,
+		},
+		{
+			name:          "chain tracker failure",
+			tracker:       &mockChainTracker{err: stderrors.New("chain tracker is down")},
+			expectedError: stderrors.New("chain tracker is down"),
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			// when
+			err := ExecuteSimplifiedPaymentVerification(context.Background(), decoded, NewChainTrackerVerifier(tc.tracker))
+
+			// then
+			if tc.expectedError == nil {
+				require.NoError(t, err)
+				require.Len(t, tc.tracker.heights, len(decoded.BUMPs))
+			} else {
+				require.Equal(t, tc.expectedError, err)
+			}
+		})
+	}
+
+	t.Run("block height out of range", func(t *testing.T) {
+		err := NewChainTrackerVerifier(&mockChainTracker{valid: true}).VerifyMerkleRoots(context.Background(), []*MerkleRootConfirmationRequestItem{
+			{BlockHeight: 1 << 32, MerkleRoot: decoded.Transactions[0].GetTxID()},
+		})
+		require.Equal(t, errors.ErrMerkleRootNotConfirmed, err)
+	})
+}
+
+// mockChainTracker is a go-sdk ChainTracker returning the same result for every merkle root
+type mockChainTracker struct {
+	err     error
+	heights []uint32
+	valid   bool
+}
+
+// IsValidRootForHeight will record the height and return the configured result
+func (m *mockChainTracker) IsValidRootForHeight(_ *chainhash.Hash, height uint32) (bool, error) {
+	m.heights = append(m.heights, height)
+	return m.valid, m.err
+}
@@ -25,84 +25,40 @@ type MerkleRootConfirmationRequestItem struct {
 }
 
 // ExecuteSimplifiedPaymentVerification executes the SPV for decoded BEEF tx
+//
+// The subject of an Atomic BEEF must be the verified (last) transaction, the
+// txid only transactions (BEEF V2) cannot be spent by the verified transactions.
+// Use Verify for the detailed report
 func ExecuteSimplifiedPaymentVerification(ctx context.Context, dBeef *beef.DecodedBEEF, provider MerkleRootVerifier) error {
-
-	for _, txDt := range dBeef.Transactions {
-		tx := txDt.Transaction
-
-		if len(tx.Outputs) == 0 {
-			return errors.ErrNoOutputs
-		}
-
-		if len(tx.Inputs) == 0 {
-			return errors.ErrNoInputs
-		}
-
-		if err := validateLockTime(tx); err != nil {
-			return err
-		}
-
-		if txDt.Unmined() {
-			if err := validateSatoshisSum(tx, dBeef.Transactions); err != nil {
-				return err
-			}
-
-			if err := validateScripts(tx, dBeef.Transactions); err != nil {
-				return err
-			}
-		}
-	}
-
-	if err := verifyMerkleRoots(ctx, dBeef, provider); err != nil {
-		return err
-	}
-
-	return nil
+	_, err := Verify(ctx, dBeef, WithMerkleRootVerifier(provider))
+	return err
 }
 
-func validateLockTime(tx *sdk.Transaction) error {
-	if tx.LockTime == 0 {
+func validateAtomicSubject(dBeef *beef.DecodedBEEF) error {
+	if !dBeef.IsAtomic() {
 		return nil
 	}
-	for _, input := range tx.Inputs {
-		if input.SequenceNumber != 0xffffffff {
-			return errors.ErrLockTimeAndSequence
-		}
+	if dBeef.AtomicTxID != dBeef.Transactions[len(dBeef.Transactions)-1].GetTxID() {
+		return errors.ErrAtomicSubjectMismatch
 	}
 	return nil
 }
 
-func validateSatoshisSum(tx *sdk.Transaction, inputTxs []*beef.TxData) error {
-	inputSum, outputSum := uint64(0), uint64(0)
+// txIndex is the index of the BEEF transactions by txid, built once per verification
+type txIndex map[string]*beef.TxData
 
-	for _, input := range tx.Inputs {
-		inputParentTx := findParentForInput(input, inputTxs)
-
-		if inputParentTx == nil {
-			return errors.ErrInvalidParentTransactions
+// newTxIndex will index the transactions by txid (the first transaction wins for a duplicated txid)
+func newTxIndex(transactions []*beef.TxData) txIndex {
+	index := make(txIndex, len(transactions))
+	for _, txDt := range transactions {
+		if _, ok := index[txDt.GetTxID()]; !ok {
+			index[txDt.GetTxID()] = txDt
 		}
-
-		inputSum += inputParentTx.Transaction.Outputs[input.SourceTxOutIndex].Satoshis
-	}
-	for _, output := range tx.Outputs {
-		outputSum += output.Satoshis
-	}
-
-	if inputSum <= outputSum {
-		return errors.ErrOutputValueTooHigh
 	}
-
-	return nil
+	return index
 }
 
-func findParentForInput(input *sdk.TransactionInput, parentTxs []*beef.TxData) *beef.TxData {
-	parentID := input.SourceTXID.String()
-
-	for _, ptx := range parentTxs {
-		if ptx.GetTxID() == parentID {
-			return ptx
-		}
-	}
-
-	return nil
+// findParentForInput will return the transaction spent by the input, nil if it is not in the BEEF
+func (i txIndex) findParentForInput(input *sdk.TransactionInput) *beef.TxData {
+	return i[input.SourceTXID.String()]
 }
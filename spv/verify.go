@@ -0,0 +1,196 @@
+package spv
+
+import (
+	"context"
+
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+// VerifyOps allow functional options to be supplied to Verify
+type VerifyOps func(o *verifyOptions)
+
+// verifyOptions are the options of Verify
+type verifyOptions struct {
+	chainTip           ChainTip
+	merkleRootVerifier MerkleRootVerifier
+	policy             Policy
+	scriptWorkers      int
+}
+
+// WithMerkleRootVerifier will set the verifier of the BUMP merkle roots (required)
+func WithMerkleRootVerifier(verifier MerkleRootVerifier) VerifyOps {
+	return func(o *verifyOptions) {
+		o.merkleRootVerifier = verifier
+	}
+}
+
+// Verify executes the SPV for decoded BEEF tx and reports the result of each transaction, input and BUMP
+//
+// Every transaction is checked (the report is complete), the merkle roots are verified
+// only if all the other checks passed. The returned error is the first failure
+func Verify(ctx context.Context, dBeef *beef.DecodedBEEF, opts ...VerifyOps) (*Report, error) {
+	options := &verifyOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	report := &Report{}
+	var failure error
+	fail := func(err error) {
+		if failure == nil && err != nil {
+			failure = err
+		}
+	}
+
+	fail(validateAtomicSubject(dBeef))
+
+	index := newTxIndex(dBeef.Transactions)
+	scriptErrs := verifyAllScripts(dBeef.Transactions, index, options.scriptWorkers)
+	finality := &finalityChecker{chainTip: options.chainTip, ctx: ctx}
+	for i, txDt := range dBeef.Transactions {
+		txReport, err := verifyTransaction(txDt, index, scriptErrs[i], options.policy, finality)
+		report.Transactions = append(report.Transactions, txReport)
+		report.Queue = report.Queue || (txReport.NonFinal && options.policy.NonFinal == NonFinalQueue)
+		fail(err)
+	}
+
+	var err error
+	report.Ancestors, err = findMinedAncestors(dBeef.GetLatestTx(), index, dBeef.BUMPs)
+	fail(err)
+
+	report.MerkleRoots, err = getMerkleRootsVerificationRequests(dBeef.BUMPs)
+	fail(err)
+	setAncestorsMerkleRoots(report)
+
+	if failure == nil {
+		if options.merkleRootVerifier == nil {
+			fail(errors.ErrMerkleRootVerifierMissing)
+		} else if err = options.merkleRootVerifier.VerifyMerkleRoots(ctx, report.MerkleRoots); err != nil {
+			fail(err)
+		} else {
+			report.MerkleRootsConfirmed = true
+		}
+	}
+
+	if failure != nil {
+		report.Error = failure.Error()
+	}
+	report.Valid = failure == nil
+	return report, failure
+}
+
+// verifyTransaction will check the transaction, the inputs of a transaction which is not mined
+// are verified with the parents (and the results of their scripts) and the transaction
+// is checked against the policy (including the finality)
+func verifyTransaction(txDt *beef.TxData, index txIndex, scriptErrs []error, policy Policy, finality *finalityChecker) (*TransactionReport, error) {
+	txReport := &TransactionReport{TxID: txDt.GetTxID(), TxIDOnly: txDt.IsTxIDOnly()}
+	if txDt.IsTxIDOnly() {
+		return txReport, nil
+	}
+
+	tx := txDt.Transaction
+	txReport.Size = tx.Size()
+	for _, output := range tx.Outputs {
+		txReport.OutputSum += output.Satoshis
+	}
+
+	// The failures are returned in this order
+	var structureErr, parentErr, scriptErr error
+	if len(tx.Outputs) == 0 {
+		structureErr = errors.ErrNoOutputs
+	} else if len(tx.Inputs) == 0 {
+		structureErr = errors.ErrNoInputs
+	}
+
+	if !txDt.Unmined() {
+		bumpIndex := uint64(*txDt.BumpIndex)
+		txReport.BumpIndex = &bumpIndex
+		return txReport, reportError(txReport, structureErr)
+	}
+
+	if structureErr == nil {
+		final, err := finality.isFinal(tx)
+		if err != nil {
+			structureErr = err
+		} else if !final {
+			txReport.NonFinal = true
+			if policy.NonFinal == NonFinalReject {
+				structureErr = errors.ErrLockTimeAndSequence
+			}
+		}
+	}
+
+	for i, input := range tx.Inputs {
+		inputReport, err := verifyInput(i, input, index, scriptErrs[i])
+		txReport.Inputs = append(txReport.Inputs, inputReport)
+		txReport.InputSum += inputReport.Satoshis
+
+		if parentErr == nil && err != nil {
+			parentErr = err
+		}
+		if scriptErr == nil && inputReport.ScriptError != "" {
+			scriptErr = errors.ErrInvalidScript
+		}
+	}
+
+	var sumErr, policyErr error
+	if txReport.InputSum <= txReport.OutputSum {
+		sumErr = errors.ErrOutputValueTooHigh
+	} else {
+		txReport.Fee = txReport.InputSum - txReport.OutputSum
+		policyErr = policy.validatePolicy(tx, txReport)
+	}
+
+	for _, err := range []error{structureErr, parentErr, sumErr, scriptErr, policyErr} {
+		if err != nil {
+			return txReport, reportError(txReport, err)
+		}
+	}
+	return txReport, nil
+}
+
+// verifyInput will find the parent output of the input and report the result of its scripts
+func verifyInput(inputIdx int, input *sdk.TransactionInput, index txIndex, scriptErr error) (*InputReport, error) {
+	inputReport := &InputReport{
+		Index:             inputIdx,
+		SourceOutputIndex: input.SourceTxOutIndex,
+		SourceTxID:        input.SourceTXID.String(),
+	}
+
+	parent := index.findParentForInput(input)
+	if parent == nil || (!parent.IsTxIDOnly() && int(input.SourceTxOutIndex) >= len(parent.Transaction.Outputs)) {
+		inputReport.Error = errors.ErrInvalidParentTransactions.Error()
+		return inputReport, errors.ErrInvalidParentTransactions
+	} else if parent.IsTxIDOnly() {
+		inputReport.Error = errors.ErrTxIDOnlyParent.Error()
+		return inputReport, errors.ErrTxIDOnlyParent
+	}
+
+	inputReport.Satoshis = parent.Transaction.Outputs[input.SourceTxOutIndex].Satoshis
+	if scriptErr != nil {
+		inputReport.ScriptError = scriptErr.Error()
+	} else {
+		inputReport.ScriptValid = true
+	}
+	return inputReport, nil
+}
+
+// setAncestorsMerkleRoots will set the merkle root of the BUMP proving each ancestor
+func setAncestorsMerkleRoots(report *Report) {
+	for _, ancestor := range report.Ancestors {
+		if ancestor.BumpIndex < uint64(len(report.MerkleRoots)) {
+			ancestor.MerkleRoot = report.MerkleRoots[ancestor.BumpIndex].MerkleRoot
+		}
+	}
+}
+
+// reportError will set the error of the transaction report
+func reportError(txReport *TransactionReport, err error) error {
+	if err != nil {
+		txReport.Error = err.Error()
+	}
+	return err
+}
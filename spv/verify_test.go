@@ -0,0 +1,170 @@
+package spv
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail/beef"
+	"github.com/bitcoin-sv/go-paymail/errors"
+)
+
+func TestVerify_ValidBEEF(t *testing.T) {
+	t.Parallel()
+
+	t.Run("full mined", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validFullMinedBeef)
+		require.NoError(t, err)
+
+		// when
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		assert.Empty(t, report.Error)
+		assert.True(t, report.MerkleRootsConfirmed)
+		require.Len(t, report.MerkleRoots, len(decoded.BUMPs))
+		require.Len(t, report.Transactions, len(decoded.Transactions))
+
+		subject := report.Transactions[len(report.Transactions)-1]
+		assert.Equal(t, decoded.GetLatestTx().TxID().String(), subject.TxID)
+		assert.Nil(t, subject.BumpIndex)
+		require.Len(t, subject.Inputs, len(decoded.GetLatestTx().Inputs))
+		for i, input := range subject.Inputs {
+			assert.Equal(t, i, input.Index)
+			assert.True(t, input.ScriptValid)
+			assert.Empty(t, input.ScriptError)
+			assert.Equal(t, decoded.GetLatestTx().Inputs[i].SourceTXID.String(), input.SourceTxID)
+		}
+		assert.Greater(t, subject.InputSum, subject.OutputSum)
+
+		require.NotEmpty(t, report.Ancestors)
+		for _, ancestor := range report.Ancestors {
+			assert.True(t, ancestor.Proven)
+			assert.Equal(t, report.MerkleRoots[ancestor.BumpIndex].MerkleRoot, ancestor.MerkleRoot)
+			assert.Equal(t, decoded.BUMPs[ancestor.BumpIndex].BlockHeight, ancestor.BlockHeight)
+		}
+		for _, txReport := range report.Transactions[:len(report.Transactions)-1] {
+			require.NotNil(t, txReport.BumpIndex, "parents of the full mined beef are mined")
+			assert.Empty(t, txReport.Inputs)
+		}
+	})
+
+	t.Run("not mined", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+
+		// when
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)))
+
+		// then
+		require.NoError(t, err)
+		assert.True(t, report.Valid)
+		for _, txReport := range report.Transactions {
+			if txReport.BumpIndex == nil {
+				require.NotEmpty(t, txReport.Inputs)
+				assert.Greater(t, txReport.InputSum, txReport.OutputSum)
+			}
+		}
+
+		// the report can be logged
+		_, err = json.Marshal(report)
+		require.NoError(t, err)
+	})
+}
+
+func TestVerify_Failures(t *testing.T) {
+	t.Parallel()
+
+	t.Run("output value too high and invalid scripts", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		decoded.GetLatestTx().Outputs[0].Satoshis += 1e15
+
+		// when
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)))
+
+		// then
+		require.Equal(t, errors.ErrOutputValueTooHigh, err)
+		assert.False(t, report.Valid)
+		assert.Equal(t, errors.ErrOutputValueTooHigh.Error(), report.Error)
+		assert.False(t, report.MerkleRootsConfirmed, "merkle roots are not verified after a failure")
+		assert.NotEmpty(t, report.MerkleRoots)
+
+		subject := report.Transactions[len(report.Transactions)-1]
+		assert.Equal(t, errors.ErrOutputValueTooHigh.Error(), subject.Error)
+		for _, input := range subject.Inputs {
+			assert.False(t, input.ScriptValid, "the signatures do not match the changed output")
+			assert.NotEmpty(t, input.ScriptError)
+		}
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validNotMinedBeef)
+		require.NoError(t, err)
+		parentTxID := decoded.GetLatestTx().Inputs[0].SourceTXID.String()
+		for i, td := range decoded.Transactions {
+			if td.GetTxID() == parentTxID {
+				decoded.Transactions = append(decoded.Transactions[:i], decoded.Transactions[i+1:]...)
+				break
+			}
+		}
+
+		// when
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(new(mockServiceProvider)))
+
+		// then
+		require.Equal(t, errors.ErrInvalidParentTransactions, err)
+		subject := report.Transactions[len(report.Transactions)-1]
+		assert.Equal(t, errors.ErrInvalidParentTransactions.Error(), subject.Inputs[0].Error)
+		assert.False(t, subject.Inputs[0].ScriptValid)
+	})
+
+	t.Run("merkle roots not confirmed", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validFullMinedBeef)
+		require.NoError(t, err)
+		verifierErr := stderrors.New("not confirmed")
+
+		// when
+		report, err := Verify(context.Background(), decoded, WithMerkleRootVerifier(&failingMerkleRootVerifier{err: verifierErr}))
+
+		// then
+		require.Equal(t, verifierErr, err)
+		assert.False(t, report.Valid)
+		assert.False(t, report.MerkleRootsConfirmed)
+		assert.Equal(t, "not confirmed", report.Error)
+	})
+
+	t.Run("missing merkle root verifier", func(t *testing.T) {
+		// given
+		decoded, err := beef.DecodeBEEF(validFullMinedBeef)
+		require.NoError(t, err)
+
+		// when
+		report, err := Verify(context.Background(), decoded)
+
+		// then
+		require.Equal(t, errors.ErrMerkleRootVerifierMissing, err)
+		assert.False(t, report.Valid)
+	})
+}
+
+// failingMerkleRootVerifier is a MerkleRootVerifier returning the error
+type failingMerkleRootVerifier struct {
+	err error
+}
+
+// VerifyMerkleRoots will return the error
+func (f *failingMerkleRootVerifier) VerifyMerkleRoots(_ context.Context, _ []*MerkleRootConfirmationRequestItem) error {
+	return f.err
+}
@@ -3,8 +3,14 @@ package paymail
 import (
 	"context"
 	"fmt"
+	"math/rand/v2"
 	"net"
+	"sort"
 	"strings"
+	"time"
+
+	"github.com/bitcoin-sv/go-paymail/interfaces"
+	"github.com/miekg/dns"
 )
 
 // defaultResolver will return a custom dns resolver
@@ -29,6 +35,11 @@ func (c *Client) defaultResolver() net.Resolver {
 //
 // Specs: http://bsvalias.org/02-01-host-discovery.html
 func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SRV, err error) {
+	return c.GetSRVRecordCtx(context.Background(), service, protocol, domainName)
+}
+
+// GetSRVRecordCtx is the context-aware version of GetSRVRecord()
+func (c *Client) GetSRVRecordCtx(ctx context.Context, service, protocol, domainName string) (srv *net.SRV, err error) {
 	// Invalid parameters?
 	if len(service) == 0 { // Use the default from paymail specs
 		service = DefaultServiceName
@@ -44,18 +55,63 @@ func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SR
 	// Force the case
 	protocol = strings.TrimSpace(strings.ToLower(protocol))
 
+	// No cache, always lookup the record
+	if c.options.discoveryCache == nil {
+		srv, _, err = c.lookupSRVRecord(ctx, service, protocol, domainName, false)
+		return
+	}
+
+	// Use the discovery cache (honouring the TTL of the record)
+	var entry *DiscoveryCacheEntry
+	if entry, _, err = c.discover(
+		ctx, discoveryKeySRV+service+"."+protocol+"."+domainName,
+		func(ctx context.Context) (*DiscoveryCacheEntry, error) {
+			record, ttl, lookupErr := c.lookupSRVRecord(ctx, service, protocol, domainName, true)
+			if lookupErr != nil {
+				return nil, lookupErr
+			}
+			fresh := newDiscoveryEntry(ttl, c.options.discoveryStaleTTL)
+			fresh.SRV = record
+			return fresh, nil
+		},
+	); err != nil {
+		return
+	}
+
+	// Return a copy (the cached record is shared)
+	record := *entry.SRV
+	return &record, nil
+}
+
+// lookupSRVRecord will lookup the SRV record for a given domain name
+//
+// The returned TTL is the TTL of the record (if withTTL and the resolver supports it), the default TTL,
+// or the negative TTL when no record was found
+func (c *Client) lookupSRVRecord(ctx context.Context, service, protocol, domainName string,
+	withTTL bool) (srv *net.SRV, ttl time.Duration, err error) {
+
 	// The computed cname to check against
 	cnameCheck := fmt.Sprintf("_%s._%s.%s.", service, protocol, domainName)
 
 	// Lookup the SRV record
 	var cname string
 	var records []*net.SRV
-	if cname, records, err = c.resolver.LookupSRV(
-		context.Background(), service, protocol, domainName,
-	); err != nil || len(records) == 0 {
+	ttl = c.options.discoveryTTL
+	if ttlResolver, ok := c.resolver.(interfaces.SRVTTLResolver); ok && withTTL {
+		var recordTTL time.Duration
+		if cname, records, recordTTL, err = ttlResolver.LookupSRVWithTTL(
+			ctx, service, protocol, domainName,
+		); err == nil && recordTTL > 0 {
+			ttl = recordTTL
+		}
+	} else {
+		cname, records, err = c.resolver.LookupSRV(ctx, service, protocol, domainName)
+	}
+	if err != nil || len(records) == 0 {
 		// @rohenaz: Paymail spec says if SRV record doesn't exist, assume it is <domain>.<tld> and port of 443
 		err = nil          // Hack
 		cname = cnameCheck // Hack
+		ttl = c.options.discoveryNegativeTTL
 		records = append(records, &net.SRV{
 			Port:     DefaultPort,
 			Priority: DefaultPriority,
@@ -82,6 +138,111 @@ func (c *Client) GetSRVRecord(service, protocol, domainName string) (srv *net.SR
 	return
 }
 
+// dnsResolver is the default resolver, it also returns the TTL of SRV records (see interfaces.SRVTTLResolver)
+type dnsResolver struct {
+	net.Resolver
+	options *ClientOptions
+}
+
+// LookupSRVWithTTL will look up the SRV records and return the lowest TTL of the records
+//
+// The records are sorted by priority and randomized by weight within a priority (like net.LookupSRV)
+func (r *dnsResolver) LookupSRVWithTTL(ctx context.Context, service, proto,
+	name string) (string, []*net.SRV, time.Duration, error) {
+
+	cname := fmt.Sprintf("_%s._%s.%s", service, proto, dns.Fqdn(name))
+	msg, err := r.exchange(ctx, cname, dns.TypeSRV)
+	if err != nil {
+		return "", nil, 0, err
+	} else if msg.Rcode != dns.RcodeSuccess {
+		return "", nil, 0, &net.DNSError{
+			Err: dns.RcodeToString[msg.Rcode], Name: cname, IsNotFound: msg.Rcode == dns.RcodeNameError,
+		}
+	}
+
+	var records []*net.SRV
+	var ttl uint32
+	for _, answer := range msg.Answer {
+		switch record := answer.(type) {
+		case *dns.CNAME:
+			cname = record.Target
+		case *dns.SRV:
+			records = append(records, &net.SRV{
+				Port: record.Port, Priority: record.Priority, Target: record.Target, Weight: record.Weight,
+			})
+			if ttl == 0 || record.Hdr.Ttl < ttl {
+				ttl = record.Hdr.Ttl
+			}
+		}
+	}
+	sortSRVRecords(records)
+
+	return cname, records, time.Duration(ttl) * time.Second, nil
+}
+
+// exchange will send the query to the name server (using the network and timeout of the options)
+//
+// Truncated UDP answers are queried again over TCP
+func (r *dnsResolver) exchange(ctx context.Context, name string, dnsType uint16) (*dns.Msg, error) {
+	m := new(dns.Msg)
+	m.MsgHdr.RecursionDesired = true
+	m.SetQuestion(dns.Fqdn(name), dnsType)
+	m.SetEdns0(4096, false)
+
+	address := net.JoinHostPort(r.options.nameServer, r.options.dnsPort)
+	client := &dns.Client{Net: r.options.nameServerNetwork, Timeout: r.options.dnsTimeout}
+	msg, _, err := client.ExchangeContext(ctx, m, address)
+	if err == nil && msg.Truncated && !strings.HasPrefix(client.Net, "tcp") {
+		client.Net = "tcp"
+		msg, _, err = client.ExchangeContext(ctx, m, address)
+	}
+	return msg, err
+}
+
+// sortSRVRecords will sort the records by priority and shuffle them by weight within a priority
+//
+// Same ordering as net.LookupSRV (RFC 2782)
+func sortSRVRecords(records []*net.SRV) {
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Priority == records[j].Priority {
+			return records[i].Weight < records[j].Weight
+		}
+		return records[i].Priority < records[j].Priority
+	})
+
+	for start := 0; start < len(records); {
+		end := start + 1
+		for end < len(records) && records[end].Priority == records[start].Priority {
+			end++
+		}
+		shuffleSRVByWeight(records[start:end])
+		start = end
+	}
+}
+
+// shuffleSRVByWeight will order the records (of the same priority) randomly, proportionally to their weight
+func shuffleSRVByWeight(records []*net.SRV) {
+	sum := 0
+	for _, record := range records {
+		sum += int(record.Weight)
+	}
+	for sum > 0 && len(records) > 1 {
+		s := 0
+		n := rand.IntN(sum)
+		for i := range records {
+			s += int(records[i].Weight)
+			if s > n {
+				if i > 0 {
+					records[0], records[i] = records[i], records[0]
+				}
+				break
+			}
+		}
+		sum -= int(records[0].Weight)
+		records = records[1:]
+	}
+}
+
 // ValidateSRVRecord will check for a valid SRV record for paymail following specifications
 //
 // Specs: http://bsvalias.org/02-01-host-discovery.html
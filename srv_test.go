@@ -5,7 +5,9 @@ import (
 	"fmt"
 	"net"
 	"testing"
+	"time"
 
+	"github.com/miekg/dns"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -112,6 +114,19 @@ func BenchmarkClient_GetSRVRecord(b *testing.B) {
 	}
 }
 
+// TestClient_GetSRVRecordCtx will test the method GetSRVRecordCtx()
+func TestClient_GetSRVRecordCtx(t *testing.T) {
+	// t.Parallel() (turned off - race condition)
+
+	client := newTestClient(t)
+
+	srv, err := client.GetSRVRecordCtx(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+	require.NoError(t, err)
+	require.NotNil(t, srv)
+	assert.Equal(t, "www."+testDomain, srv.Target)
+	assert.Equal(t, uint16(DefaultPort), srv.Port)
+}
+
 // TestClient_ValidateSRVRecord will test the method ValidateSRVRecord()
 func TestClient_ValidateSRVRecord(t *testing.T) {
 	// t.Parallel() (turned off - race condition)
@@ -309,3 +324,105 @@ func BenchmarkClient_ValidateSRVRecord(b *testing.B) {
 		)
 	}
 }
+
+// startTestNameServer will start a name server (udp and tcp) on a local port and return the port
+func startTestNameServer(t *testing.T, udp, tcp dns.HandlerFunc) string {
+	packetConn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	port := fmt.Sprint(packetConn.LocalAddr().(*net.UDPAddr).Port)
+	listener, err := net.Listen("tcp", "127.0.0.1:"+port)
+	require.NoError(t, err)
+
+	servers := []*dns.Server{
+		{PacketConn: packetConn, Handler: udp},
+		{Listener: listener, Handler: tcp},
+	}
+	for _, server := range servers {
+		started := make(chan struct{})
+		server.NotifyStartedFunc = func() { close(started) }
+		go func() { _ = server.ActivateAndServe() }()
+		<-started
+		t.Cleanup(func() { _ = server.Shutdown() })
+	}
+	return port
+}
+
+// testSRVAnswer will answer the query with the SRV records
+func testSRVAnswer(records ...*net.SRV) dns.HandlerFunc {
+	return func(w dns.ResponseWriter, req *dns.Msg) {
+		msg := new(dns.Msg)
+		msg.SetReply(req)
+		for _, record := range records {
+			msg.Answer = append(msg.Answer, &dns.SRV{
+				Hdr:  dns.RR_Header{Name: req.Question[0].Name, Rrtype: dns.TypeSRV, Class: dns.ClassINET, Ttl: 60},
+				Port: record.Port, Priority: record.Priority, Target: record.Target, Weight: record.Weight,
+			})
+		}
+		_ = w.WriteMsg(msg)
+	}
+}
+
+// TestDNSResolver_LookupSRVWithTTL will test the method LookupSRVWithTTL() of the default resolver
+func TestDNSResolver_LookupSRVWithTTL(t *testing.T) {
+	t.Parallel()
+
+	resolver := func(t *testing.T, port string, opts ...ClientOps) *dnsResolver {
+		client, err := NewClient(append([]ClientOps{WithNameServer("127.0.0.1"), WithDNSPort(port)}, opts...)...)
+		require.NoError(t, err)
+		return client.GetResolver().(*dnsResolver)
+	}
+	noAnswer := func(dns.ResponseWriter, *dns.Msg) {}
+	record := &net.SRV{Target: "www." + testDomain + ".", Port: 443, Priority: 10, Weight: 10}
+
+	t.Run("truncated udp answer is queried over tcp", func(t *testing.T) {
+		truncated := func(w dns.ResponseWriter, req *dns.Msg) {
+			msg := new(dns.Msg)
+			msg.SetReply(req)
+			msg.Truncated = true
+			_ = w.WriteMsg(msg)
+		}
+		port := startTestNameServer(t, truncated, testSRVAnswer(record))
+
+		_, records, ttl, err := resolver(t, port).LookupSRVWithTTL(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, record, records[0])
+		assert.Equal(t, 60*time.Second, ttl)
+	})
+
+	t.Run("name server network is used", func(t *testing.T) {
+		port := startTestNameServer(t, noAnswer, testSRVAnswer(record))
+
+		_, records, _, err := resolver(t, port, WithNameServerNetwork("tcp")).LookupSRVWithTTL(
+			context.Background(), DefaultServiceName, DefaultProtocol, testDomain,
+		)
+		require.NoError(t, err)
+		assert.Len(t, records, 1)
+	})
+
+	t.Run("dns timeout is used", func(t *testing.T) {
+		port := startTestNameServer(t, noAnswer, noAnswer)
+
+		started := time.Now()
+		_, _, _, err := resolver(t, port, WithDNSTimeout(100*time.Millisecond)).LookupSRVWithTTL(
+			context.Background(), DefaultServiceName, DefaultProtocol, testDomain,
+		)
+		require.Error(t, err)
+		assert.Less(t, time.Since(started), 2*time.Second)
+	})
+
+	t.Run("records are sorted by priority and weight", func(t *testing.T) {
+		port := startTestNameServer(t, testSRVAnswer(
+			&net.SRV{Target: "c.", Priority: 20, Weight: 10},
+			&net.SRV{Target: "zero.", Priority: 10, Weight: 0},
+			&net.SRV{Target: "a.", Priority: 10, Weight: 10},
+		), noAnswer)
+
+		_, records, _, err := resolver(t, port).LookupSRVWithTTL(context.Background(), DefaultServiceName, DefaultProtocol, testDomain)
+		require.NoError(t, err)
+		require.Len(t, records, 3)
+		assert.Equal(t, "a.", records[0].Target, "the zero weight record is last within its priority")
+		assert.Equal(t, "zero.", records[1].Target)
+		assert.Equal(t, "c.", records[2].Target)
+	})
+}
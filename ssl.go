@@ -12,10 +12,15 @@ import (
 //
 // All paymail requests should be via HTTPS and have a valid certificate
 func (c *Client) CheckSSL(host string) (valid bool, err error) {
+	return c.CheckSSLCtx(context.Background(), host)
+}
+
+// CheckSSLCtx is the context-aware version of CheckSSL()
+func (c *Client) CheckSSLCtx(ctx context.Context, host string) (valid bool, err error) {
 
 	// Lookup the host
 	var ips []net.IPAddr
-	if ips, err = c.resolver.LookupIPAddr(context.Background(), host); err != nil {
+	if ips, err = c.resolver.LookupIPAddr(ctx, host); err != nil {
 		return
 	}
 
@@ -24,19 +29,21 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 		for _, ip := range ips {
 
 			// Set the dialer
-			dialer := net.Dialer{
-				Timeout:  c.options.sslTimeout,
-				Deadline: time.Now().Add(c.options.sslDeadline),
+			dialer := &tls.Dialer{
+				NetDialer: &net.Dialer{
+					Timeout:  c.options.sslTimeout,
+					Deadline: time.Now().Add(c.options.sslDeadline),
+				},
+				Config: &tls.Config{
+					ServerName: host,
+				},
 			}
 
 			// Set the connection
-			connection, dialErr := tls.DialWithDialer(
-				&dialer,
+			conn, dialErr := dialer.DialContext(
+				ctx,
 				DefaultProtocol,
 				fmt.Sprintf("[%s]:%d", ip.String(), DefaultPort),
-				&tls.Config{
-					ServerName: host,
-				},
 			)
 			if dialErr != nil {
 				// catch missing ipv6 connectivity
@@ -54,9 +61,16 @@ func (c *Client) CheckSSL(host string) (valid bool, err error) {
 						}
 					}
 				*/
+
+				// A canceled or expired context is not an invalid certificate
+				if err = ctx.Err(); err != nil {
+					return
+				}
 				continue
 			}
 
+			connection := conn.(*tls.Conn)
+
 			// remember the checked certs based on their Signature
 			checkedCerts := make(map[string]struct{})
 
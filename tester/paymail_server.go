@@ -0,0 +1,388 @@
+package tester
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	ec "github.com/bitcoin-sv/go-sdk/primitives/ec"
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/bitcoin-sv/go-sdk/transaction/template/p2pkh"
+	"github.com/go-resty/resty/v2"
+	"github.com/rs/zerolog"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/errors"
+	"github.com/bitcoin-sv/go-paymail/server"
+	"github.com/bitcoin-sv/go-paymail/spv"
+)
+
+// DefaultPaymailServerDomain is the paymail domain of the PaymailServer (reserved TLD, never resolved)
+const DefaultPaymailServerDomain = "paymail.test"
+
+// PaymailServer is an in-process paymail server (httptest TLS server) for end-to-end tests
+//
+// The Client resolves the paymail domain to the server and trusts its certificate,
+// received transactions and contact requests are recorded for assertions
+type PaymailServer struct {
+	Client paymail.ClientInterface // Client resolving the domain to the server
+	Config *server.Configuration   // Configuration of the server
+	Domain string                  // Paymail domain of the aliases
+	Server *httptest.Server        // Underlying TLS test server
+
+	aliases         map[string]*Alias
+	contactStatuses map[string]paymail.PikeContactStatus
+	contacts        []*ContactRequest
+	transactions    []*ReceivedTransaction
+	sync.Mutex
+}
+
+// Alias is a fake paymail registered on the PaymailServer (with a generated key)
+type Alias struct {
+	Alias         string         // Alias (handle) of the paymail
+	Domain        string         // Domain of the paymail
+	LockingScript string         // Hex encoded P2PKH locking script of the key (used for all outputs)
+	Name          string         // Name of the user (public profile)
+	Paymail       string         // Full paymail address (alias@domain)
+	PrivateKey    *ec.PrivateKey // Generated private key
+	PubKey        string         // Hex encoded (compressed) public key
+}
+
+// ReceivedTransaction is a transaction received by the PaymailServer
+type ReceivedTransaction struct {
+	Alias       string                  // Alias of the receiver
+	Domain      string                  // Domain of the receiver
+	Metadata    *server.RequestMetadata // Metadata of the request
+	Transaction *paymail.P2PTransaction // Transaction as received (the hex is set for BEEF as well)
+	TxID        string                  // The txid of the transaction
+}
+
+// ContactRequest is a PIKE contact request received by the PaymailServer
+type ContactRequest struct {
+	Paymail string                             // Paymail receiving the request
+	Request *paymail.PikeContactRequestPayload // Requester (full name and paymail)
+}
+
+// PaymailServerOps allow functional options to be supplied to NewPaymailServer
+type PaymailServerOps func(o *paymailServerOptions)
+
+// paymailServerOptions holds the options of the PaymailServer
+type paymailServerOptions struct {
+	aliases       []string
+	configOptions []server.ConfigOps
+	domain        string
+}
+
+// WithAlias will register a fake alias (with a generated key)
+func WithAlias(alias string) PaymailServerOps {
+	return func(o *paymailServerOptions) {
+		o.aliases = append(o.aliases, alias)
+	}
+}
+
+// WithDomain will set the paymail domain (default: DefaultPaymailServerDomain)
+func WithDomain(domain string) PaymailServerOps {
+	return func(o *paymailServerOptions) {
+		if len(domain) > 0 {
+			o.domain = domain
+		}
+	}
+}
+
+// WithConfigOptions will add options to the server configuration (e.g. server.WithBeefCapabilities())
+func WithConfigOptions(opts ...server.ConfigOps) PaymailServerOps {
+	return func(o *paymailServerOptions) {
+		o.configOptions = append(o.configOptions, opts...)
+	}
+}
+
+// NewPaymailServer will start a paymail server for the test (closed on cleanup)
+//
+// P2P, PIKE contact and PIKE payment capabilities are enabled and the issued references are
+// verified (server.WithReferenceStore). BEEF requires valid merkle proofs and unlocking
+// scripts, it can be enabled using WithConfigOptions(server.WithBeefCapabilities()).
+func NewPaymailServer(t testing.TB, opts ...PaymailServerOps) *PaymailServer {
+	t.Helper()
+
+	options := &paymailServerOptions{domain: DefaultPaymailServerDomain}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	s := &PaymailServer{
+		Domain:          options.domain,
+		Server:          httptest.NewUnstartedServer(nil),
+		aliases:         make(map[string]*Alias),
+		contactStatuses: make(map[string]paymail.PikeContactStatus),
+	}
+	host, port, err := net.SplitHostPort(s.Server.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("failed to parse the server address: %s", err)
+	}
+
+	// Create the client (resolving the domain to the server)
+	if s.Client, err = paymail.NewClient(); err != nil {
+		t.Fatalf("failed to create the paymail client: %s", err)
+	}
+	portNumber, _ := strconv.Atoi(port)
+	s.Client.WithCustomResolver(NewCustomResolver(
+		s.Client.GetResolver(),
+		map[string][]string{s.Domain: {host}},
+		map[string][]*net.SRV{
+			paymail.DefaultServiceName + paymail.DefaultProtocol + s.Domain: {{
+				Target: host, Port: uint16(portNumber), Priority: paymail.DefaultPriority, Weight: paymail.DefaultWeight,
+			}},
+		},
+		nil,
+	))
+
+	// Create the configuration
+	sl := &server.PaymailServiceLocator{}
+	provider := &paymailServerProvider{server: s}
+	sl.RegisterPaymailService(provider)
+	sl.RegisterPikeContactService(provider)
+	sl.RegisterPikePaymentService(provider)
+
+	logger := zerolog.Nop()
+	if s.Config, err = server.NewConfig(sl, append([]server.ConfigOps{
+		server.WithDomain(s.Domain),
+		server.WithDomain(host),
+		server.WithLogger(&logger),
+		server.WithP2PCapabilities(),
+		server.WithPaymailClient(s.Client),
+		server.WithPikeContactCapabilities(),
+		server.WithPikePaymentCapabilities(),
+		server.WithReferenceStore(server.NewMemoryReferenceStore(), 0),
+	}, options.configOptions...)...); err != nil {
+		t.Fatalf("failed to create the server configuration: %s", err)
+	}
+
+	// Start the server (and trust its certificate)
+	s.Server.Config.Handler = s.Config.Handler()
+	s.Server.StartTLS()
+	t.Cleanup(s.Server.Close)
+	s.Client.WithCustomHTTPClient(resty.NewWithClient(s.Server.Client()))
+
+	for _, alias := range options.aliases {
+		s.AddAlias(t, alias)
+	}
+	return s
+}
+
+// AddAlias will register a fake alias (with a generated key)
+func (s *PaymailServer) AddAlias(t testing.TB, alias string) *Alias {
+	t.Helper()
+
+	privateKey, err := ec.NewPrivateKey()
+	if err != nil {
+		t.Fatalf("failed to generate a private key: %s", err)
+	}
+	address, err := script.NewAddressFromPublicKey(privateKey.PubKey(), true)
+	if err != nil {
+		t.Fatalf("failed to create the address: %s", err)
+	}
+	lockingScript, err := p2pkh.Lock(address)
+	if err != nil {
+		t.Fatalf("failed to create the locking script: %s", err)
+	}
+
+	a := &Alias{
+		Alias:         alias,
+		Domain:        s.Domain,
+		LockingScript: lockingScript.String(),
+		Name:          alias,
+		Paymail:       alias + "@" + s.Domain,
+		PrivateKey:    privateKey,
+		PubKey:        hex.EncodeToString(privateKey.PubKey().Compressed()),
+	}
+
+	s.Lock()
+	defer s.Unlock()
+	s.aliases[alias] = a
+	return a
+}
+
+// Alias will return the registered alias (nil if not found)
+func (s *PaymailServer) Alias(alias string) *Alias {
+	s.Lock()
+	defer s.Unlock()
+	return s.aliases[alias]
+}
+
+// Transactions will return the received transactions
+func (s *PaymailServer) Transactions() []*ReceivedTransaction {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*ReceivedTransaction(nil), s.transactions...)
+}
+
+// ContactRequests will return the received PIKE contact requests
+func (s *PaymailServer) ContactRequests() []*ContactRequest {
+	s.Lock()
+	defer s.Unlock()
+	return append([]*ContactRequest(nil), s.contacts...)
+}
+
+// ContactStatus will return the status of the contact at the paymail (empty if there is no contact)
+//
+// The received contact requests are pending, the answers received for the requests sent
+// by the paymail are accepted or rejected
+func (s *PaymailServer) ContactStatus(paymailAddress, contactPaymail string) paymail.PikeContactStatus {
+	s.Lock()
+	defer s.Unlock()
+	return s.contactStatuses[contactKey(paymailAddress, contactPaymail)]
+}
+
+// setContactStatus will set the status of the contact at the paymail
+func (s *PaymailServer) setContactStatus(paymailAddress, contactPaymail string, status paymail.PikeContactStatus) {
+	s.Lock()
+	defer s.Unlock()
+	s.contactStatuses[contactKey(paymailAddress, contactPaymail)] = status
+}
+
+// contactKey is the key of the contact of the paymail
+func contactKey(paymailAddress, contactPaymail string) string {
+	return paymailAddress + " " + contactPaymail
+}
+
+// paymailServerProvider is the service provider of the PaymailServer
+type paymailServerProvider struct {
+	server *PaymailServer
+}
+
+// alias will return the registered alias (or errors.ErrCouldNotFindPaymail)
+func (p *paymailServerProvider) alias(alias, domain string) (*Alias, error) {
+	if a := p.server.Alias(alias); a != nil && a.Domain == domain {
+		return a, nil
+	}
+	return nil, errors.ErrCouldNotFindPaymail
+}
+
+// GetPaymailByAlias will return the registered alias
+func (p *paymailServerProvider) GetPaymailByAlias(_ context.Context, alias, domain string,
+	_ *server.RequestMetadata) (*paymail.AddressInformation, error) {
+	a := p.server.Alias(alias)
+	if a == nil || a.Domain != domain {
+		return nil, nil // the server responds with errors.ErrCouldNotFindPaymail
+	}
+	return &paymail.AddressInformation{
+		Alias:  a.Alias,
+		Domain: a.Domain,
+		ID:     a.Paymail,
+		Name:   a.Name,
+		PubKey: a.PubKey,
+	}, nil
+}
+
+// CreateAddressResolutionResponse will return the locking script of the alias
+func (p *paymailServerProvider) CreateAddressResolutionResponse(_ context.Context, alias, domain string,
+	_ bool, _ *server.RequestMetadata) (*paymail.ResolutionPayload, error) {
+	a, err := p.alias(alias, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &paymail.ResolutionPayload{Output: a.LockingScript}, nil
+}
+
+// CreateP2PDestinationResponse will return a single output (locking script of the alias) and a new reference
+func (p *paymailServerProvider) CreateP2PDestinationResponse(_ context.Context, alias, domain string,
+	satoshis uint64, _ *server.RequestMetadata) (*paymail.PaymentDestinationPayload, error) {
+	a, err := p.alias(alias, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &paymail.PaymentDestinationPayload{
+		Outputs:   []*paymail.PaymentOutput{{Satoshis: satoshis, Script: a.LockingScript}},
+		Reference: newReference(),
+	}, nil
+}
+
+// RecordTransaction will record the received transaction
+func (p *paymailServerProvider) RecordTransaction(_ context.Context, p2pTx *paymail.P2PTransaction,
+	md *server.RequestMetadata) (*paymail.P2PTransactionPayload, error) {
+	tx, err := sdk.NewTransactionFromHex(p2pTx.Hex)
+	if err != nil {
+		return nil, errors.ErrProcessingHex
+	}
+
+	received := &ReceivedTransaction{
+		Alias:       md.Alias,
+		Domain:      md.Domain,
+		Metadata:    md,
+		Transaction: p2pTx,
+		TxID:        tx.TxID().String(),
+	}
+
+	p.server.Lock()
+	defer p.server.Unlock()
+	p.server.transactions = append(p.server.transactions, received)
+	return &paymail.P2PTransactionPayload{Note: p2pTx.MetaData.Note, TxID: received.TxID}, nil
+}
+
+// VerifyMerkleRoots will accept all merkle roots
+func (p *paymailServerProvider) VerifyMerkleRoots(_ context.Context, _ []*spv.MerkleRootConfirmationRequestItem) error {
+	return nil
+}
+
+// AddContact will record the contact request
+func (p *paymailServerProvider) AddContact(_ context.Context, receiverPaymail string,
+	contact *paymail.PikeContactRequestPayload) error {
+	p.server.Lock()
+	defer p.server.Unlock()
+	p.server.contacts = append(p.server.contacts, &ContactRequest{Paymail: receiverPaymail, Request: contact})
+	p.server.contactStatuses[contactKey(receiverPaymail, contact.Paymail)] = paymail.PikeContactStatusPending
+	return nil
+}
+
+// AcceptContact will record the contact as accepted
+func (p *paymailServerProvider) AcceptContact(_ context.Context, paymailAddress string,
+	contact *paymail.PikeContactResponsePayload) error {
+	p.server.setContactStatus(paymailAddress, contact.Paymail, paymail.PikeContactStatusAccepted)
+	return nil
+}
+
+// RejectContact will record the contact as rejected
+func (p *paymailServerProvider) RejectContact(_ context.Context, paymailAddress string,
+	contact *paymail.PikeContactResponsePayload) error {
+	p.server.setContactStatus(paymailAddress, contact.Paymail, paymail.PikeContactStatusRejected)
+	return nil
+}
+
+// GetContactStatus will return the recorded status of the contact
+func (p *paymailServerProvider) GetContactStatus(_ context.Context, paymailAddress string,
+	contact *paymail.PikeContactResponsePayload) (*paymail.PikeContactStatusPayload, error) {
+	status := p.server.ContactStatus(paymailAddress, contact.Paymail)
+	if status == "" {
+		return nil, errors.ErrContactNotFound
+	}
+	return &paymail.PikeContactStatusPayload{Paymail: contact.Paymail, Status: status}, nil
+}
+
+// CreatePikeOutputResponse will return a single output template derived from the key of the alias
+// and the PKI key of the sender (BRC-42, see paymail.PikeOutputsDeriver)
+func (p *paymailServerProvider) CreatePikeOutputResponse(_ context.Context, alias, domain, senderPubKey string,
+	satoshis uint64, _ *server.RequestMetadata) (*paymail.PikePaymentOutputsResponse, error) {
+	a, err := p.alias(alias, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	deriver, err := paymail.NewPikeOutputsDeriver(a.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	return deriver.CreateOutputs(senderPubKey, newReference(), satoshis)
+}
+
+// newReference will return a random payment reference
+func newReference() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
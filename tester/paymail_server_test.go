@@ -0,0 +1,189 @@
+package tester
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/bitcoin-sv/go-sdk/script"
+	sdk "github.com/bitcoin-sv/go-sdk/transaction"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitcoin-sv/go-paymail"
+	"github.com/bitcoin-sv/go-paymail/server"
+)
+
+// TestNewPaymailServer will test the in-process paymail server (end-to-end, using the pre-wired client)
+func TestNewPaymailServer(t *testing.T) {
+	t.Parallel()
+
+	t.Run("pay an alias", func(t *testing.T) {
+		s := NewPaymailServer(t, WithAlias("alice"), WithAlias("bob"))
+		alice, bob := s.Alias("alice"), s.Alias("bob")
+
+		buildTx := func(_ context.Context, outputs []*paymail.PaymentOutput) (*sdk.Transaction, error) {
+			sourceTx := sdk.NewTransaction()
+			require.NoError(t, sourceTx.AddOpReturnOutput([]byte("source")))
+
+			tx := sdk.NewTransaction()
+			tx.AddInputFromTx(sourceTx, 0, nil)
+			for _, output := range outputs {
+				lockingScript, err := script.NewFromHex(output.Script)
+				require.NoError(t, err)
+				tx.AddOutput(&sdk.TransactionOutput{LockingScript: lockingScript, Satoshis: output.Satoshis})
+			}
+			return tx, nil
+		}
+
+		result, err := s.Client.Pay(context.Background(), alice.PrivateKey, bob.Paymail, 1000, buildTx,
+			paymail.WithPaymentSender(alice.Paymail, alice.Name), paymail.WithPaymentNote("test payment"),
+		)
+		require.NoError(t, err)
+		assert.True(t, result.Sent)
+		assert.Equal(t, paymail.BRFCP2PTransactions, result.Capability)
+
+		transactions := s.Transactions()
+		require.Len(t, transactions, 1)
+		assert.Equal(t, "bob", transactions[0].Alias)
+		assert.Equal(t, result.TxID, transactions[0].TxID)
+		assert.Equal(t, result.Reference, transactions[0].Transaction.Reference)
+		assert.Equal(t, alice.Paymail, transactions[0].Transaction.MetaData.Sender)
+	})
+
+	t.Run("pki and contact requests", func(t *testing.T) {
+		s := NewPaymailServer(t, WithDomain("example.test"), WithAlias("alice"), WithAlias("bob"))
+		alice, bob := s.Alias("alice"), s.Alias("bob")
+		assert.Equal(t, "alice@example.test", alice.Paymail)
+
+		srv, err := s.Client.GetSRVRecord(paymail.DefaultServiceName, paymail.DefaultProtocol, s.Domain)
+		require.NoError(t, err)
+		capabilities, err := s.Client.GetCapabilities(srv.Target, int(srv.Port))
+		require.NoError(t, err)
+
+		pki, err := s.Client.GetPKI(capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate), alice.Alias, s.Domain)
+		require.NoError(t, err)
+		assert.Equal(t, alice.PubKey, pki.PubKey)
+
+		_, err = s.Client.AddInviteRequest(capabilities.ExtractPikeInviteURL(), alice.Alias, s.Domain,
+			&paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: bob.Paymail},
+		)
+		require.NoError(t, err)
+
+		contacts := s.ContactRequests()
+		require.Len(t, contacts, 1)
+		assert.Equal(t, alice.Paymail, contacts[0].Paymail)
+		assert.Equal(t, bob.Paymail, contacts[0].Request.Paymail)
+
+		// The answers of bob are signed by the PKI key of bob
+		statusRequest := &paymail.PikeContactResponsePayload{Paymail: bob.Paymail}
+		_, err = s.Client.GetContactStatus(capabilities.ExtractPikeStatusURL(), alice.Alias, s.Domain, statusRequest)
+		require.Error(t, err, "not signed")
+
+		require.NoError(t, statusRequest.Sign(bob.PrivateKey, paymail.PikeContactActionStatus, alice.Paymail))
+		status, err := s.Client.GetContactStatus(capabilities.ExtractPikeStatusURL(), alice.Alias, s.Domain, statusRequest)
+		require.NoError(t, err)
+		assert.Equal(t, paymail.PikeContactStatusPending, status.Status)
+
+		accept := &paymail.PikeContactResponsePayload{Paymail: bob.Paymail}
+		require.NoError(t, accept.Sign(alice.PrivateKey, paymail.PikeContactActionAccept, alice.Paymail))
+		_, err = s.Client.AcceptContactRequest(capabilities.ExtractPikeAcceptURL(), alice.Alias, s.Domain, accept)
+		require.Error(t, err, "not signed by the PKI key of bob")
+
+		accept = &paymail.PikeContactResponsePayload{Paymail: bob.Paymail}
+		require.NoError(t, accept.Sign(bob.PrivateKey, paymail.PikeContactActionAccept, alice.Paymail))
+		_, err = s.Client.AcceptContactRequest(capabilities.ExtractPikeAcceptURL(), alice.Alias, s.Domain, accept)
+		require.NoError(t, err)
+		assert.Equal(t, paymail.PikeContactStatusAccepted, s.ContactStatus(alice.Paymail, bob.Paymail))
+
+		_, err = s.Client.GetPKI(capabilities.GetString(paymail.BRFCPki, paymail.BRFCPkiAlternate), "unknown", s.Domain)
+		require.Error(t, err)
+	})
+
+	t.Run("signed contact requests", func(t *testing.T) {
+		s := NewPaymailServer(t, WithAlias("alice"), WithAlias("bob"),
+			WithConfigOptions(server.WithPikeContactSignatureRequired()),
+		)
+		alice, bob := s.Alias("alice"), s.Alias("bob")
+
+		srv, err := s.Client.GetSRVRecord(paymail.DefaultServiceName, paymail.DefaultProtocol, s.Domain)
+		require.NoError(t, err)
+		capabilities, err := s.Client.GetCapabilities(srv.Target, int(srv.Port))
+		require.NoError(t, err)
+		inviteURL := capabilities.ExtractPikeInviteURL()
+		request := &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: bob.Paymail}
+
+		_, err = s.Client.AddContactRequest(inviteURL, alice.Alias, s.Domain, request)
+		require.Error(t, err, "not signed")
+
+		_, err = s.Client.AddSignedContactRequest(inviteURL, alice.Alias, s.Domain, request, alice.PrivateKey)
+		require.Error(t, err, "not signed by the PKI key of bob")
+		assert.Empty(t, s.ContactRequests())
+
+		_, err = s.Client.AddSignedContactRequest(inviteURL, alice.Alias, s.Domain, request, bob.PrivateKey)
+		require.NoError(t, err)
+
+		contacts := s.ContactRequests()
+		require.Len(t, contacts, 1)
+		assert.Equal(t, alice.Paymail, contacts[0].Paymail)
+		assert.Equal(t, bob.Paymail, contacts[0].Request.Paymail)
+		assert.NotEmpty(t, contacts[0].Request.Signature)
+	})
+	t.Run("derived PIKE outputs", func(t *testing.T) {
+		s := NewPaymailServer(t, WithAlias("alice"), WithAlias("bob"))
+		alice, bob := s.Alias("alice"), s.Alias("bob")
+
+		srv, err := s.Client.GetSRVRecord(paymail.DefaultServiceName, paymail.DefaultProtocol, s.Domain)
+		require.NoError(t, err)
+		capabilities, err := s.Client.GetCapabilities(srv.Target, int(srv.Port))
+		require.NoError(t, err)
+		payload := &paymail.PikePaymentOutputsPayload{SenderPaymail: bob.Paymail, Amount: 1000}
+
+		outputs, err := s.Client.GetVerifiedOutputsTemplate(capabilities.ExtractPikeOutputsURL(), alice.Alias, s.Domain,
+			payload, bob.PrivateKey, alice.PubKey,
+		)
+		require.NoError(t, err)
+		require.Len(t, outputs.Outputs, 1)
+		assert.NotEqual(t, alice.LockingScript, outputs.Outputs[0].Script)
+
+		_, err = s.Client.GetVerifiedOutputsTemplate(capabilities.ExtractPikeOutputsURL(), alice.Alias, s.Domain,
+			payload, alice.PrivateKey, alice.PubKey,
+		)
+		require.ErrorIs(t, err, paymail.ErrPikeOutputNotDerived)
+	})
+	t.Run("replayed signed requests", func(t *testing.T) {
+		s := NewPaymailServer(t, WithAlias("alice"), WithAlias("bob"),
+			WithConfigOptions(server.WithSenderValidation(), server.WithReplayGuard(server.NewMemoryReplayStore(), 0)),
+		)
+		alice, bob := s.Alias("alice"), s.Alias("bob")
+
+		srv, err := s.Client.GetSRVRecord(paymail.DefaultServiceName, paymail.DefaultProtocol, s.Domain)
+		require.NoError(t, err)
+		capabilities, err := s.Client.GetCapabilities(srv.Target, int(srv.Port))
+		require.NoError(t, err)
+
+		senderRequest := &paymail.SenderRequest{
+			Dt:           time.Now().UTC().Format(time.RFC3339),
+			SenderHandle: bob.Paymail,
+		}
+		signature, err := senderRequest.Sign(hex.EncodeToString(bob.PrivateKey.Serialize()))
+		require.NoError(t, err)
+		senderRequest.Signature = base64.StdEncoding.EncodeToString(signature)
+
+		resolutionURL := capabilities.GetString(paymail.BRFCPaymentDestination, paymail.BRFCBasicAddressResolution)
+		_, err = s.Client.ResolveAddress(resolutionURL, alice.Alias, s.Domain, senderRequest)
+		require.NoError(t, err)
+		_, err = s.Client.ResolveAddress(resolutionURL, alice.Alias, s.Domain, senderRequest)
+		require.Error(t, err, "replayed address resolution")
+
+		request := &paymail.PikeContactRequestPayload{FullName: "Bob", Paymail: bob.Paymail}
+		require.NoError(t, request.Sign(bob.PrivateKey, alice.Paymail))
+		_, err = s.Client.AddContactRequest(capabilities.ExtractPikeInviteURL(), alice.Alias, s.Domain, request)
+		require.NoError(t, err)
+		_, err = s.Client.AddContactRequest(capabilities.ExtractPikeInviteURL(), alice.Alias, s.Domain, request)
+		require.Error(t, err, "replayed contact request")
+		assert.Len(t, s.ContactRequests(), 1)
+	})
+}
@@ -1,6 +1,7 @@
 package paymail
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -35,10 +36,15 @@ type VerificationPayload struct {
 //
 // Specs: https://bsvalias.org/05-verify-public-key-owner.html
 func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error) {
+	return c.VerifyPubKeyCtx(context.Background(), verifyURL, alias, domain, pubKey)
+}
+
+// VerifyPubKeyCtx is the context-aware version of VerifyPubKey()
+func (c *Client) VerifyPubKeyCtx(ctx context.Context, verifyURL, alias, domain, pubKey string) (response *VerificationResponse, err error) {
 
 	// Require a valid url
 	if len(verifyURL) == 0 || !strings.Contains(verifyURL, "https://") {
-		err = fmt.Errorf("invalid url: %s", verifyURL)
+		err = invalidURLError(verifyURL)
 		return
 	}
 
@@ -60,7 +66,7 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Fire the GET request
 	var resp StandardResponse
-	if resp, err = c.getRequest(reqURL); err != nil {
+	if resp, err = c.getRequest(ctx, reqURL); err != nil {
 		return
 	}
 
@@ -69,11 +75,7 @@ func (c *Client) VerifyPubKey(verifyURL, alias, domain, pubKey string) (response
 
 	// Test the status code (200 or 304 is valid)
 	if response.StatusCode != http.StatusOK && response.StatusCode != http.StatusNotModified {
-		serverError := &ServerError{}
-		if err = json.Unmarshal(resp.Body, serverError); err != nil {
-			return
-		}
-		err = fmt.Errorf("bad response from paymail provider: code %d, message: %s", response.StatusCode, serverError.Message)
+		err = newPaymailProviderError(reqURL, alias, domain, &resp)
 		return
 	}
 